@@ -0,0 +1,169 @@
+package v1beta1
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"syscall"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func init() {
+	NewSysRegistry[CEOSNode] = func() System { return new(CEOS) }
+}
+
+const (
+	CEOSNode             NodeType = "ceos"
+	DefaultCEOSMem       string   = "2Gi"
+	DefaultCEOSFlashSize string   = "1Gi"
+	CEOSFlashDir         string   = "/mnt/flash"
+	CEOSStartupCfgKey    string   = "startup-config"
+)
+
+var ceosPortRegex = regexp.MustCompile(`^eth[1-9][0-9]*$`)
+
+// CEOS specifies an Arista cEOS container router
+type CEOS struct {
+	//cEOS container image
+	Image *string `json:"image,omitempty"`
+	//a k8s configmap in the lab namespace with key "startup-config",
+	//it is copied into /mnt/flash/startup-config on first boot
+	// +optional
+	// +nullable
+	StartupConfig *string `json:"startupConfig,omitempty"`
+	//size of the pvc mounted on /mnt/flash
+	// +optional
+	// +nullable
+	FlashSize *resource.Quantity `json:"flashSize,omitempty"`
+	//additional environment variables of the cEOS container
+	// +optional
+	Env map[string]string `json:"env,omitempty"`
+	//requested memory in k8s resource unit
+	// +optional
+	// +nullable
+	ReqMemory *resource.Quantity `json:"memory,omitempty"`
+	//requested cpu in k8s resource unit
+	// +optional
+	// +nullable
+	ReqCPU *resource.Quantity `json:"cpu,omitempty"`
+}
+
+func (ceos *CEOS) SetToAppDefVal() {
+	ceos.FlashSize = ReturnPointerVal(resource.MustParse(DefaultCEOSFlashSize))
+	ceos.ReqMemory = ReturnPointerVal(resource.MustParse(DefaultCEOSMem))
+}
+
+func (ceos *CEOS) FillDefaultVal(nodeName string) {
+
+}
+
+func (ceos *CEOS) Validate(lab *LabSpec, nodeName string) error {
+	if ceos.Image == nil {
+		return fmt.Errorf("image not specified")
+	}
+	if ceos.FlashSize == nil {
+		return fmt.Errorf("flashSize not specified")
+	}
+	return validatePodPortIds(lab, nodeName, func(portId string) error {
+		if !ceosPortRegex.MatchString(portId) {
+			return fmt.Errorf("expect ethN, which is EthernetN in cEOS")
+		}
+		return nil
+	})
+}
+
+// getEnv returns environment variables required by cEOS, overridden by Env
+func (ceos *CEOS) getEnv() map[string]string {
+	r := map[string]string{
+		"CEOS":                                "1",
+		"EOS_PLATFORM":                        "ceoslab",
+		"container":                           "docker",
+		"ETBA":                                "1",
+		"SKIP_ZEROTOUCH_BARRIER_IN_SYSDBINIT": "1",
+		"INTFTYPE":                            "eth",
+		"MAPETH0":                             "1",
+		"MGMT_INTF":                           "eth0",
+	}
+	for k, v := range ceos.Env {
+		r[k] = v
+	}
+	return r
+}
+
+func (ceos *CEOS) Ensure(ctx context.Context, nodeName string, clnt client.Client, forceRemoval bool) error {
+	lab, err := getParsedLab(ctx)
+	if err != nil {
+		return err
+	}
+	spec := &cfgPodSpec{
+		NodeType:  CEOSNode,
+		Image:     *ceos.Image,
+		PVCSuffix: "flash",
+		PVCSize:   *ceos.FlashSize,
+		MountPath: CEOSFlashDir,
+		//interfaces are eth1..N in sorted link order, ethN maps to EthernetN in cEOS
+		AutoIntfName: func(i int) string { return fmt.Sprintf("eth%d", i) },
+		ReqCPU:       ceos.ReqCPU,
+		ReqMemory:    ceos.ReqMemory,
+	}
+	//copy startup config on first boot, existing startup-config in flash is kept
+	if ceos.StartupConfig != nil {
+		spec.StartupConfig = ceos.StartupConfig
+		spec.InitCmds = []string{copyStartupCfgCmd(CEOSStartupCfgKey)}
+	}
+	pod, _, err := lab.newCfgPod(ctx, clnt, nodeName, spec)
+	if err != nil {
+		return err
+	}
+	env := ceos.getEnv()
+	pod.Spec.Containers[0].Command = []string{"/sbin/init"}
+	for _, k := range GetSortedKeySlice(env) {
+		pod.Spec.Containers[0].Env = append(pod.Spec.Containers[0].Env, corev1.EnvVar{Name: k, Value: env[k]})
+		pod.Spec.Containers[0].Args = append(pod.Spec.Containers[0].Args, fmt.Sprintf("systemd.setenv=%v=%v", k, env[k]))
+	}
+	err = createIfNotExistsOrRemove(ctx, clnt, lab, pod, true, false)
+	if err != nil {
+		return fmt.Errorf("failed to create cEOS pod %v in lab %v, %w", nodeName, lab.Lab.Name, err)
+	}
+	return nil
+}
+
+// GetCfgSnapshotSource implements CfgSnapshotSystem interface, default is startup-config in flash,
+// paths are relative to /mnt/flash
+func (ceos *CEOS) GetCfgSnapshotSource(labName, nodeName string, paths []string) (*CfgSnapshotSource, error) {
+	plist, err := getCfgSnapshotPaths(CEOSFlashDir, CEOSFlashDir, paths, []string{CEOSStartupCfgKey})
+	if err != nil {
+		return nil, err
+	}
+	return &CfgSnapshotSource{
+		PodName:   GetPodName(labName, nodeName),
+		Container: "main",
+		RootDir:   CEOSFlashDir,
+		Paths:     plist,
+	}, nil
+}
+
+// Shell runs Cli in the pod
+func (ceos *CEOS) Shell(ctx context.Context, clnt client.Client, ns, lab, chassis, username string) {
+	envList := []string{fmt.Sprintf("HOME=%v", os.Getenv("HOME"))}
+	fmt.Printf("connecting to %v\n", GetPodName(lab, chassis))
+	syscall.Exec("/bin/sh",
+		[]string{"sh", "-c",
+			fmt.Sprintf("kubectl -n %v exec -it %v -- Cli",
+				ns, GetPodName(lab, chassis))},
+		envList)
+}
+
+func (ceos *CEOS) Console(ctx context.Context, clnt client.Client, ns, lab, chassis string) {
+	envList := []string{fmt.Sprintf("HOME=%v", os.Getenv("HOME"))}
+	fmt.Printf("connecting to %v\n", GetPodName(lab, chassis))
+	syscall.Exec("/bin/sh",
+		[]string{"sh", "-c",
+			fmt.Sprintf("kubectl -n %v exec -it %v -- bash",
+				ns, GetPodName(lab, chassis))},
+		envList)
+}
@@ -3,49 +3,102 @@ package v1beta1
 import (
 	"fmt"
 	"os"
+	"sort"
 	"strings"
 	"sync"
 )
 
+// DefKNLConfigName is name of the default KNLConfig, used by labs that don't select a config
+const DefKNLConfigName = "knlcfg"
+
 // +kubebuilder:object:generate=false
 // +kubebuilder:object:root=false
 // +hidefromdoc
-type Config struct {
+type namedConfig struct {
 	config     *KNLConfigSpec
-	lock       *sync.RWMutex
 	generation int64
 }
 
+// Config holds all KNLConfigs in MYNAMESPACE, key is the KNLConfig name
+// +kubebuilder:object:generate=false
+// +kubebuilder:object:root=false
+// +hidefromdoc
+type Config struct {
+	configs map[string]*namedConfig
+	lock    *sync.RWMutex
+}
+
+// Get returns the default config
 func (cfg *Config) Get() KNLConfigSpec {
+	r, _ := cfg.Lookup(DefKNLConfigName)
+	return r
+}
+
+// Lookup returns the config of name, the default config and false if not found
+func (cfg *Config) Lookup(name string) (KNLConfigSpec, bool) {
 	cfg.lock.RLock()
 	defer cfg.lock.RUnlock()
-	return *cfg.config
+	if c, ok := cfg.configs[name]; ok {
+		return *c.config, true
+	}
+	return *cfg.configs[DefKNLConfigName].config, false
 }
 
 // return true if changed
-func (cfg *Config) Set(new *KNLConfigSpec, gen int64) bool {
+func (cfg *Config) Set(name string, new *KNLConfigSpec, gen int64) bool {
 	cfg.lock.Lock()
 	defer cfg.lock.Unlock()
-	if cfg.generation != gen {
-		cfg.config = new
-		cfg.generation = gen
-		return true
+	if c, ok := cfg.configs[name]; ok && c.generation == gen {
+		return false
+	}
+	cfg.configs[name] = &namedConfig{
+		config:     new,
+		generation: gen,
+	}
+	return true
+}
+
+// Remove removes config of name, the default config is kept
+func (cfg *Config) Remove(name string) {
+	cfg.lock.Lock()
+	defer cfg.lock.Unlock()
+	if name != DefKNLConfigName {
+		delete(cfg.configs, name)
 	}
-	return false
 }
 
-func (cfg *Config) GetGen() int64 {
+// GetGen returns generation of config name, -1 if not found
+func (cfg *Config) GetGen(name string) int64 {
 	cfg.lock.RLock()
 	defer cfg.lock.RUnlock()
-	return cfg.generation
+	if c, ok := cfg.configs[name]; ok {
+		return c.generation
+	}
+	return -1
+}
+
+// Names returns sorted names of all configs
+func (cfg *Config) Names() []string {
+	cfg.lock.RLock()
+	defer cfg.lock.RUnlock()
+	r := make([]string, 0, len(cfg.configs))
+	for name := range cfg.configs {
+		r = append(r, name)
+	}
+	sort.Strings(r)
+	return r
 }
 
 func newConfig() *Config {
 	defCfg := DefKNLConfig()
 	return &Config{
-		config:     &defCfg,
-		lock:       new(sync.RWMutex),
-		generation: -1,
+		configs: map[string]*namedConfig{
+			DefKNLConfigName: {
+				config:     &defCfg,
+				generation: -1,
+			},
+		},
+		lock: new(sync.RWMutex),
 	}
 }
 
@@ -0,0 +1,177 @@
+package v1beta1
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	cfgPodVolName          = "persis-cfg"
+	cfgPodInitMountPath    = "/persis-cfg"
+	cfgPodStartupVolName   = "startup-cfg"
+	cfgPodStartupMountPath = "/knl-startup-cfg"
+)
+
+// getParsedLab returns the ParsedLab stored in ctx
+func getParsedLab(ctx context.Context) (*ParsedLab, error) {
+	val := ctx.Value(ParsedLabKey)
+	if val == nil {
+		return nil, MakeErr(fmt.Errorf("failed to get parsed lab obj from context"))
+	}
+	lab, ok := val.(*ParsedLab)
+	if !ok {
+		return nil, MakeErr(fmt.Errorf("context stored value is not a ParsedLabSpec"))
+	}
+	return lab, nil
+}
+
+// cfgPodSpec specifies a container based node whose configuration is persisted in a PVC
+type cfgPodSpec struct {
+	NodeType NodeType
+	Image    string
+	//the PVC is named <lab>-<node>-<PVCSuffix>
+	PVCSuffix string
+	PVCSize   resource.Quantity
+	//mount path of the PVC in the main container
+	MountPath string
+	//configmap of the startup config, mounted on cfgPodStartupMountPath of the init container
+	StartupConfig *string
+	//shell commands of the init container, it runs with the PVC mounted on cfgPodInitMountPath, after config is restored;
+	//the init container is not added if empty
+	InitCmds []string
+	//additional volume mounts of the init container, caller adds the volumes
+	InitMounts     []corev1.VolumeMount
+	InitPrivileged bool
+	//interface naming of setPodNetworks
+	AutoIntfName func(int) string
+	PortIntfName func(string) string
+	ReqCPU       *resource.Quantity
+	ReqMemory    *resource.Quantity
+}
+
+// copyStartupCfgCmd returns a shell command copying file of the startup config into the PVC without overwriting existing file,
+// file could be a wildcard; it does nothing if there is no startup config
+func copyStartupCfgCmd(file string) string {
+	return fmt.Sprintf("if [ -d %v ]; then cp -n %v/%v %v/; fi", cfgPodStartupMountPath, cfgPodStartupMountPath, file, cfgPodInitMountPath)
+}
+
+// addCfgMapVolume adds configmap cmName as volume volName of pod, and mounts it on mountPath of container, subPath could be empty
+func addCfgMapVolume(pod *corev1.Pod, container *corev1.Container, volName, cmName, mountPath, subPath string) {
+	container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+		Name:      volName,
+		MountPath: mountPath,
+		SubPath:   subPath,
+	})
+	pod.Spec.Volumes = append(pod.Spec.Volumes, corev1.Volume{
+		Name: volName,
+		VolumeSource: corev1.VolumeSource{
+			ConfigMap: &corev1.ConfigMapVolumeSource{
+				LocalObjectReference: corev1.LocalObjectReference{
+					Name: cmName,
+				},
+			},
+		},
+	})
+}
+
+func (spec *cfgPodSpec) getPVC(ns, nodeName, labName string, storageClass *string) *corev1.PersistentVolumeClaim {
+	name := fmt.Sprintf("%v-%v-%v", labName, nodeName, spec.PVCSuffix)
+	return &corev1.PersistentVolumeClaim{
+		ObjectMeta: GetObjMeta(name, labName, ns, nodeName, spec.NodeType),
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOncePod},
+			StorageClassName: GetPointerVal(*storageClass),
+			Resources: corev1.VolumeResourceRequirements{
+				Requests: map[corev1.ResourceName]resource.Quantity{
+					corev1.ResourceStorage: spec.PVCSize,
+				},
+			},
+		},
+	}
+}
+
+// newCfgPod creates the PVC of nodeName, restored from the snapshot if the lab restores from one,
+// and returns its privileged pod with the PVC mounted, config restore and init containers, networks and resource requests;
+// return interface names of setPodNetworks
+func (lab *ParsedLab) newCfgPod(ctx context.Context, clnt client.Client, nodeName string, spec *cfgPodSpec) (*corev1.Pod, []string, error) {
+	pvc := spec.getPVC(lab.Lab.Namespace, nodeName, lab.Lab.Name, lab.Lab.GetConfig().PVCStorageClass)
+	err := lab.setRestoreVolSource(ctx, clnt, pvc)
+	if err != nil {
+		return nil, nil, err
+	}
+	err = createIfNotExistsOrRemove(ctx, clnt, lab, pvc, false, false)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create %v pvc for %v %v in lab %v, %w", spec.PVCSuffix, spec.NodeType, nodeName, lab.Lab.Name, err)
+	}
+	pod := NewBasePod(lab.Lab.Name, nodeName, lab.Lab.Namespace, spec.Image, spec.NodeType)
+	pod.Spec.Volumes = []corev1.Volume{
+		{
+			Name: cfgPodVolName,
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+					ClaimName: pvc.Name,
+					ReadOnly:  false,
+				},
+			},
+		},
+	}
+	//restore config from snapshot
+	restoreCM, snapshot, err := lab.ensureRestoreCfgMap(ctx, clnt, nodeName, spec.NodeType)
+	if err != nil {
+		return nil, nil, err
+	}
+	if restoreCM != nil {
+		restoreContainer, restoreVol := getRestoreInitContainer(spec.Image, restoreCM, snapshot, cfgPodVolName, spec.MountPath, spec.MountPath)
+		pod.Spec.InitContainers = append(pod.Spec.InitContainers, restoreContainer)
+		pod.Spec.Volumes = append(pod.Spec.Volumes, restoreVol)
+	}
+	if len(spec.InitCmds) > 0 {
+		initContainer := corev1.Container{
+			Name:    "init-cfg",
+			Image:   spec.Image,
+			Command: []string{"sh", "-c", strings.Join(spec.InitCmds, "; ")},
+			SecurityContext: &corev1.SecurityContext{
+				RunAsUser:  ReturnPointerVal(int64(0)),
+				RunAsGroup: ReturnPointerVal(int64(0)),
+			},
+			VolumeMounts: append([]corev1.VolumeMount{
+				{
+					Name:      cfgPodVolName,
+					MountPath: cfgPodInitMountPath,
+				},
+			}, spec.InitMounts...),
+		}
+		if spec.InitPrivileged {
+			initContainer.SecurityContext.Privileged = ReturnPointerVal(true)
+		}
+		if spec.StartupConfig != nil {
+			addCfgMapVolume(pod, &initContainer, cfgPodStartupVolName, *spec.StartupConfig, cfgPodStartupMountPath, "")
+		}
+		pod.Spec.InitContainers = append(pod.Spec.InitContainers, initContainer)
+	}
+	pod.Spec.Containers[0].SecurityContext = &corev1.SecurityContext{
+		Privileged: ReturnPointerVal(true),
+	}
+	pod.Spec.Containers[0].VolumeMounts = []corev1.VolumeMount{
+		{
+			Name:      cfgPodVolName,
+			MountPath: spec.MountPath,
+		},
+	}
+	//refer to NADs
+	intfList := setPodNetworks(lab, nodeName, pod, spec.AutoIntfName, spec.PortIntfName)
+	//add resource request
+	pod.Spec.Containers[0].Resources.Requests = make(corev1.ResourceList)
+	if spec.ReqCPU != nil {
+		pod.Spec.Containers[0].Resources.Requests[corev1.ResourceCPU] = *spec.ReqCPU
+	}
+	if spec.ReqMemory != nil {
+		pod.Spec.Containers[0].Resources.Requests[corev1.ResourceMemory] = *spec.ReqMemory
+	}
+	return pod, intfList, nil
+}
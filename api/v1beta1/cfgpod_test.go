@@ -0,0 +1,255 @@
+package v1beta1
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// newTestParsedLab returns parsed lab with spokes populated, which is normally done by EnsureLinks
+func newTestParsedLab(lab *Lab, sch *runtime.Scheme) *ParsedLab {
+	plab := ParseLab(lab, sch)
+	plab.SpokeMap = map[string]map[string][]string{}
+	plab.SpokeConnectorMap = map[string]*Connector{}
+	plab.SpokeLinkMap = map[string]string{}
+	for i, linkName := range GetSortedKeySlice(lab.Spec.LinkList) {
+		for j, c := range lab.Spec.LinkList[linkName].Connectors {
+			spokeName := getSpokeName(int32(i), j)
+			if plab.SpokeMap[*c.NodeName] == nil {
+				plab.SpokeMap[*c.NodeName] = map[string][]string{}
+			}
+			plab.SpokeMap[*c.NodeName][linkName] = append(plab.SpokeMap[*c.NodeName][linkName], spokeName)
+			plab.SpokeConnectorMap[spokeName] = &c
+			plab.SpokeLinkMap[spokeName] = linkName
+		}
+	}
+	return plab
+}
+
+// ensureTestNode runs Ensure of nodeName with a fake client, return the pod and the client
+func ensureTestNode(t *testing.T, lab *Lab, nodeName string) (*corev1.Pod, client.Client) {
+	sch := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(sch); err != nil {
+		t.Fatal(err)
+	}
+	if err := AddToScheme(sch); err != nil {
+		t.Fatal(err)
+	}
+	clnt := fake.NewClientBuilder().WithScheme(sch).Build()
+	plab := newTestParsedLab(lab, sch)
+	ctx := context.WithValue(context.Background(), ParsedLabKey, plab)
+	sys, _ := lab.Spec.NodeList[nodeName].GetSystem()
+	if err := sys.Validate(&lab.Spec, nodeName); err != nil {
+		t.Fatal(err)
+	}
+	if err := sys.Ensure(ctx, nodeName, clnt, false); err != nil {
+		t.Fatal(err)
+	}
+	pod := new(corev1.Pod)
+	if err := clnt.Get(ctx, types.NamespacedName{Namespace: lab.Namespace, Name: GetPodName(lab.Name, nodeName)}, pod); err != nil {
+		t.Fatal(err)
+	}
+	return pod, clnt
+}
+
+func newTestCfgPodLab(nodes map[string]*OneOfSystem, ports ...string) *Lab {
+	lab := &Lab{
+		ObjectMeta: metav1.ObjectMeta{Name: "lab1", Namespace: "default"},
+		Spec: LabSpec{
+			NodeList:        nodes,
+			LinkList:        map[string]*Link{},
+			PVCStorageClass: ReturnPointerVal("local"),
+		},
+	}
+	for i, port := range ports {
+		c := Connector{NodeName: ReturnPointerVal("r1")}
+		if port != "" {
+			c.PortId = ReturnPointerVal(port)
+		}
+		lab.Spec.LinkList["link"+string(rune('1'+i))] = &Link{Connectors: []Connector{c, {NodeName: ReturnPointerVal("peer")}}}
+	}
+	return lab
+}
+
+func checkCfgPod(t *testing.T, pod *corev1.Pod, clnt client.Client, pvcName, mountPath string) {
+	pvc := new(corev1.PersistentVolumeClaim)
+	if err := clnt.Get(context.Background(), types.NamespacedName{Namespace: pod.Namespace, Name: pvcName}, pvc); err != nil {
+		t.Fatal(err)
+	}
+	if pvc.Labels[ChassisNameAnnotation] != "r1" || *pvc.Spec.StorageClassName != "local" {
+		t.Fatalf("unexpected pvc labels %v", pvc.Labels)
+	}
+	main := pod.Spec.Containers[0]
+	if main.VolumeMounts[0].Name != cfgPodVolName || main.VolumeMounts[0].MountPath != mountPath || !*main.SecurityContext.Privileged {
+		t.Fatalf("unexpected main container %+v", main)
+	}
+	if pod.Spec.Volumes[0].PersistentVolumeClaim == nil || pod.Spec.Volumes[0].PersistentVolumeClaim.ClaimName != pvcName {
+		t.Fatalf("unexpected volumes %+v", pod.Spec.Volumes)
+	}
+	if len(pod.OwnerReferences) != 1 || pod.OwnerReferences[0].Kind != "Lab" {
+		t.Fatalf("pod is not owned by the lab, %+v", pod.OwnerReferences)
+	}
+}
+
+func TestCRPDEnsure(t *testing.T) {
+	crpd := &CRPD{}
+	crpd.SetToAppDefVal()
+	crpd.Image = ReturnPointerVal("crpd:24.2")
+	crpd.StartupConfig = ReturnPointerVal("r1-cfg")
+	crpd.License = ReturnPointerVal("crpd-lic")
+	crpd.ReqCPU = ReturnPointerVal(resource.MustParse("2"))
+	lab := newTestCfgPodLab(map[string]*OneOfSystem{"r1": {CRPD: crpd}, "peer": {CRPD: &CRPD{}}}, "")
+	pod, clnt := ensureTestNode(t, lab, "r1")
+	checkCfgPod(t, pod, clnt, "lab1-r1-config", CRPDConfigDir)
+	if len(pod.Spec.InitContainers) != 1 || !strings.Contains(pod.Spec.InitContainers[0].Command[2], JunosStartCfgKey) {
+		t.Fatalf("unexpected init containers %+v", pod.Spec.InitContainers)
+	}
+	mounts := map[string]corev1.VolumeMount{}
+	for _, m := range pod.Spec.Containers[0].VolumeMounts {
+		mounts[m.Name] = m
+	}
+	if mounts["varlog"].MountPath != "/var/log" || mounts["lic"].SubPath != "license" {
+		t.Fatalf("unexpected volume mounts %+v", mounts)
+	}
+	vols := map[string]corev1.Volume{}
+	for _, v := range pod.Spec.Volumes {
+		vols[v.Name] = v
+	}
+	if vols["lic"].Secret == nil || vols["lic"].Secret.SecretName != "crpd-lic" || vols["varlog"].EmptyDir == nil {
+		t.Fatalf("unexpected volumes %+v", vols)
+	}
+	req := pod.Spec.Containers[0].Resources.Requests
+	if req.Cpu().String() != "2" || req.Memory().String() != DefaultCRPDMem {
+		t.Fatalf("unexpected resource requests %v", req)
+	}
+	if pod.Annotations[MultusAnnoKey] == "" || !strings.HasSuffix(pod.Annotations[MultusAnnoKey], "@eth1") {
+		t.Fatalf("unexpected multus annotation %v", pod.Annotations[MultusAnnoKey])
+	}
+
+	//committed config is collected, not the startup config
+	src, err := crpd.GetCfgSnapshotSource("lab1", "r1", nil)
+	if err != nil || src.RootDir != CRPDConfigDir || strings.Join(src.Paths, ",") != JunosCommittedCfgKey {
+		t.Fatalf("unexpected snapshot source %+v, %v", src, err)
+	}
+	src, err = crpd.GetCfgSnapshotSource("lab1", "r1", []string{"/config/juniper.conf.gz", "/config/rollback"})
+	if err != nil || strings.Join(src.Paths, ",") != "juniper.conf.gz,rollback" {
+		t.Fatalf("unexpected snapshot source %+v, %v", src, err)
+	}
+	if _, err = crpd.GetCfgSnapshotSource("lab1", "r1", []string{"/var/log/messages"}); err == nil {
+		t.Fatalf("expect error for path outside of /config")
+	}
+}
+
+func TestFRREnsure(t *testing.T) {
+	frr := &FRR{Image: ReturnPointerVal(DefaultFRRImage), Config: ReturnPointerVal("r1-cfg")}
+	lab := newTestCfgPodLab(map[string]*OneOfSystem{"r1": {FRR: frr}, "peer": {FRR: &FRR{}}}, "")
+	if err := frr.Validate(&lab.Spec, "r1"); err != nil {
+		t.Fatal(err)
+	}
+	//addresses and routes are configured in frr.conf, not via connectors
+	lab.Spec.LinkList["link1"].Connectors[0].Addrs = []string{"192.168.1.1/24"}
+	if err := frr.Validate(&lab.Spec, "r1"); err == nil {
+		t.Fatalf("expect error for connector address")
+	}
+	lab.Spec.LinkList["link1"].Connectors[0].Addrs = nil
+	lab.Spec.LinkList["link1"].Connectors[0].Routes = []string{"10.0.0.0/8 via 192.168.1.2"}
+	if err := frr.Validate(&lab.Spec, "r1"); err == nil {
+		t.Fatalf("expect error for connector route")
+	}
+	lab.Spec.LinkList["link1"].Connectors[0].Routes = nil
+	pod, clnt := ensureTestNode(t, lab, "r1")
+	checkCfgPod(t, pod, clnt, "lab1-r1-etc", FRREtcDir)
+	init := pod.Spec.InitContainers[0]
+	//config from configmap takes precedence over image defaults
+	cmd := init.Command[2]
+	if !*init.SecurityContext.Privileged || strings.Index(cmd, cfgPodStartupMountPath) > strings.Index(cmd, FRREtcDir) ||
+		!strings.Contains(cmd, "chown -R frr:frr "+cfgPodInitMountPath) {
+		t.Fatalf("unexpected init container %+v", init)
+	}
+}
+
+func TestCEOSEnsure(t *testing.T) {
+	ceos := &CEOS{}
+	ceos.SetToAppDefVal()
+	ceos.Image = ReturnPointerVal("ceos:4.33")
+	ceos.Env = map[string]string{"INTFTYPE": "et"}
+	lab := newTestCfgPodLab(map[string]*OneOfSystem{"r1": {CEOS: ceos}, "peer": {CEOS: &CEOS{}}}, "", "eth1")
+	if err := ceos.Validate(&lab.Spec, "r1"); err != nil {
+		t.Fatal(err)
+	}
+	lab.Spec.LinkList["link1"].Connectors[0].PortId = ReturnPointerVal("Ethernet1")
+	if err := ceos.Validate(&lab.Spec, "r1"); err == nil {
+		t.Fatalf("expect error for invalid port id")
+	}
+	lab.Spec.LinkList["link1"].Connectors[0].PortId = nil
+
+	//no startup config, there is no init container
+	pod, clnt := ensureTestNode(t, lab, "r1")
+	checkCfgPod(t, pod, clnt, "lab1-r1-flash", CEOSFlashDir)
+	if len(pod.Spec.InitContainers) != 0 {
+		t.Fatalf("unexpected init containers %+v", pod.Spec.InitContainers)
+	}
+	main := pod.Spec.Containers[0]
+	if main.Command[0] != "/sbin/init" || main.Resources.Requests.Memory().String() != DefaultCEOSMem {
+		t.Fatalf("unexpected main container %+v", main)
+	}
+	env := map[string]string{}
+	for _, e := range main.Env {
+		env[e.Name] = e.Value
+	}
+	if env["INTFTYPE"] != "et" || env["CEOS"] != "1" || len(main.Args) != len(main.Env) {
+		t.Fatalf("unexpected env %v, args %v", env, main.Args)
+	}
+	//link2 uses eth1, link1 is the next
+	nets := strings.Split(pod.Annotations[MultusAnnoKey], ",")
+	if len(nets) != 2 || !strings.HasSuffix(nets[0], "-link1-klan0-0@eth2") || !strings.HasSuffix(nets[1], "-link2-klan1-0@eth1") {
+		t.Fatalf("unexpected multus annotation %v", pod.Annotations[MultusAnnoKey])
+	}
+
+	//startup config is copied into flash on first boot
+	ceos.StartupConfig = ReturnPointerVal("r1-cfg")
+	pod, _ = ensureTestNode(t, lab, "r1")
+	if len(pod.Spec.InitContainers) != 1 {
+		t.Fatalf("unexpected init containers %+v", pod.Spec.InitContainers)
+	}
+	init := pod.Spec.InitContainers[0]
+	if !strings.Contains(init.Command[2], "cp -n "+cfgPodStartupMountPath+"/"+CEOSStartupCfgKey+" "+cfgPodInitMountPath+"/") {
+		t.Fatalf("unexpected init command %v", init.Command)
+	}
+	if len(init.VolumeMounts) != 2 || init.VolumeMounts[1].Name != cfgPodStartupVolName || pod.Spec.Volumes[1].ConfigMap.Name != "r1-cfg" {
+		t.Fatalf("startup config is not mounted, %+v, %+v", init.VolumeMounts, pod.Spec.Volumes)
+	}
+
+	src, err := ceos.GetCfgSnapshotSource("lab1", "r1", []string{"/mnt/flash/startup-config", "/mnt/flash/if-wait.sh"})
+	if err != nil || src.RootDir != CEOSFlashDir || strings.Join(src.Paths, ",") != "startup-config,if-wait.sh" {
+		t.Fatalf("unexpected snapshot source %+v", src)
+	}
+}
+
+func TestSONiCEnsure(t *testing.T) {
+	sonic := &SONiC{Image: ReturnPointerVal("sonic-vs:latest")}
+	lab := newTestCfgPodLab(map[string]*OneOfSystem{"r1": {SONiC: sonic}, "peer": {SONiC: &SONiC{}}}, "Ethernet8", "")
+	pod, clnt := ensureTestNode(t, lab, "r1")
+	checkCfgPod(t, pod, clnt, "lab1-r1-etc", SONiCEtcDir)
+	init := pod.Spec.InitContainers[0]
+	if len(init.VolumeMounts) != 2 || init.VolumeMounts[1].Name != "hwsku" || init.SecurityContext.Privileged != nil {
+		t.Fatalf("unexpected init container %+v", init)
+	}
+	hwsku := new(corev1.ConfigMap)
+	if err := clnt.Get(context.Background(), types.NamespacedName{Namespace: "default", Name: "lab1-r1-hwsku"}, hwsku); err != nil {
+		t.Fatal(err)
+	}
+	//Ethernet8 is eth3, so there are 3 ports
+	if strings.Count(hwsku.Data[sonicLaneMapKey], "\n") != 3 {
+		t.Fatalf("unexpected lanemap %v", hwsku.Data[sonicLaneMapKey])
+	}
+}
@@ -25,6 +25,7 @@ type CloudInitNetworkConfigBody struct {
 type NicConfig struct {
 	Dhcp4     bool
 	Match     NicMatcher     `yaml:",omitempty"`
+	MTU       int32          `yaml:"mtu,omitempty"`
 	Addresses []netip.Prefix `yaml:",omitempty"`
 	Routes    []k8slan.Route `yaml:",omitempty"`
 }
@@ -43,7 +44,9 @@ func (netcfg *CloudInitNetworkConfig) Marshal() []byte {
 	}
 	return buf
 }
-func (netcfg *CloudInitNetworkConfig) AddConnector(nicName string, c *Connector) {
+
+// AddConnector adds nic config of connector c, mtu is not set if it is nil
+func (netcfg *CloudInitNetworkConfig) AddConnector(nicName string, c *Connector, mtu *int32) {
 	netcfg.Network.Ethernets[nicName] = &NicConfig{
 		Dhcp4: false,
 		Match: NicMatcher{
@@ -52,6 +55,9 @@ func (netcfg *CloudInitNetworkConfig) AddConnector(nicName string, c *Connector)
 		Addresses: []netip.Prefix{},
 		Routes:    []k8slan.Route{},
 	}
+	if mtu != nil {
+		netcfg.Network.Ethernets[nicName].MTU = *mtu
+	}
 	for _, addStr := range c.Addrs {
 		netcfg.Network.Ethernets[nicName].Addresses = append(netcfg.Network.Ethernets[nicName].Addresses, netip.MustParsePrefix(addStr))
 	}
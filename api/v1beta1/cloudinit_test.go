@@ -28,7 +28,7 @@ func TestCloiudinit(t *testing.T) {
 		Addrs:  []string{"192.168.100.99/24", "2001:beef::1/64"},
 	}
 	cfg := getDefCloudinitNetworkCfg()
-	cfg.AddConnector("nic1", &c)
+	cfg.AddConnector("nic1", &c, ReturnPointerVal(int32(1400)))
 	fmt.Println(string(cfg.Marshal()))
 
 }
@@ -347,6 +347,16 @@ func ReCreateSymLink(labName, chassisName, newtarget string) error {
 		filepath.Join("/"+KNLROOTName, GetFTPSROSImgPath(labName, chassisName)))
 }
 
+// GetConsoleLogFolder return the folder of console log files for a given lab, with knlroot prefix
+func GetConsoleLogFolder(ns, labName string) string {
+	return filepath.Join("/"+KNLROOTName, ConsoleLogSubFolder, ns, labName)
+}
+
+// GetSnapshotFolder return the folder of snapshot archives for a given lab, with knlroot prefix
+func GetSnapshotFolder(ns, labName string) string {
+	return filepath.Join("/"+KNLROOTName, SnapshotSubFolder, ns, labName)
+}
+
 func WaitForObjGone(ctx context.Context, clnt client.Client, ns string, obj client.Object) {
 	wg := new(wait.Group)
 	wctx, cancelf := context.WithDeadline(ctx, time.Now().Add(60*time.Second))
@@ -419,18 +429,11 @@ func GetSRConfigFTPSubFolder(labname string, chassisName string) string {
 	return filepath.Join("/"+KNLROOTName, CfgSubFolder, labname, chassisName)
 
 }
-func NewDV(namespace, labName, dvName, nodeImg string, stroageclass *string, disksize *resource.Quantity) *cdiv1.DataVolume {
-	// func newDV(lab *ParsedLabSpec, nodeName, nodeImg string) *cdiv1.DataVolume {
-	r := new(cdiv1.DataVolume)
 
-	r.ObjectMeta = metav1.ObjectMeta{
-		Name:      dvName,
-		Namespace: namespace,
-		Labels: map[string]string{
-			K8SLABELAPPKey:   K8SLABELAPPVAL,
-			K8SLABELSETUPKEY: labName,
-		},
-	}
+// NewDV returns a DataVolume of node nodeName, nodeName is empty if the DV doesn't belong to a node
+func NewDV(namespace, labName, nodeName string, nodeType NodeType, dvName, nodeImg string, stroageclass *string, disksize *resource.Quantity) *cdiv1.DataVolume {
+	r := new(cdiv1.DataVolume)
+	r.ObjectMeta = GetObjMeta(dvName, labName, namespace, nodeName, nodeType)
 	r.Spec.PVC = &corev1.PersistentVolumeClaimSpec{
 		StorageClassName: stroageclass,
 		AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOncePod},
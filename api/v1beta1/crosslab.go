@@ -0,0 +1,259 @@
+package v1beta1
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"slices"
+	"strings"
+
+	k8slan "github.com/hujun-open/k8slan/api/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// CrossLabLANLabelKey is set to "true" on shared LAN of lab-to-lab links
+	CrossLabLANLabelKey = "crosslab.kubenetlab.net/lan"
+	// CrossLabEndpointsAnnotation lists endpoints of shared LAN in format namespace/lab/node, in order of spoke index
+	CrossLabEndpointsAnnotation = "crosslab.kubenetlab.net/endpoints"
+)
+
+// PeerLab references the lab of the peer node of a lab-to-lab link
+type PeerLab struct {
+	//namespace of the peer lab, default is the namespace of this lab
+	// +optional
+	// +nullable
+	Namespace *string `json:"namespace,omitempty"`
+	//+required
+	//name of the peer lab
+	Name string `json:"name"`
+}
+
+// CrossLabLink is a lab-to-lab link between a node of this lab and a node of another lab
+type CrossLabLink struct {
+	//link name in this lab, empty if the link is only declared by the peer lab
+	// +optional
+	Link string `json:"link,omitempty"`
+	//node of this lab
+	Node string `json:"node"`
+	//peer node in format namespace/lab/node
+	Peer string `json:"peer"`
+	//name of the shared k8slan LAN in the operator namespace
+	LAN string `json:"lan"`
+	//true if both labs joined the shared LAN
+	Connected bool `json:"connected"`
+}
+
+// IsCrossLab returns true if the connector is a node of another lab
+func (c *Connector) IsCrossLab() bool {
+	return c.Kind != nil && *c.Kind == ConnectorKindLab
+}
+
+// IsNode returns true if the connector is the node nodeName of this lab
+func (c *Connector) IsNode(nodeName string) bool {
+	return c.NodeName != nil && *c.NodeName == nodeName && !c.IsCrossLab() && !c.IsExternal()
+}
+
+// IsCrossLab returns true if the link is a lab-to-lab link, it has a node connector and a peer lab connector
+func (link *Link) IsCrossLab() bool {
+	return slices.ContainsFunc(link.Connectors, func(c Connector) bool { return c.IsCrossLab() })
+}
+
+// hasNonNodeConnector returns true if any connector of the link is not a node of the lab
+func (link *Link) hasNonNodeConnector() bool {
+	return slices.ContainsFunc(link.Connectors, func(c Connector) bool { return c.IsCrossLab() || c.IsExternal() })
+}
+
+// getCrossLabConnectors returns the node connector and the peer lab connector of a lab-to-lab link
+func (link *Link) getCrossLabConnectors() (*Connector, *Connector) {
+	var local, peer *Connector
+	for i := range link.Connectors {
+		if link.Connectors[i].IsCrossLab() {
+			peer = &link.Connectors[i]
+		} else {
+			local = &link.Connectors[i]
+		}
+	}
+	return local, peer
+}
+
+func (link *Link) validateCrossLab() error {
+	if len(link.Connectors) != 2 {
+		return fmt.Errorf("lab-to-lab link must have exactly 2 nodes")
+	}
+	local, peer := link.getCrossLabConnectors()
+	if local == nil || local.IsExternal() {
+		return fmt.Errorf("lab-to-lab link must have a node of the lab")
+	}
+	if peer.PeerLab == nil || peer.PeerLab.Name == "" {
+		return fmt.Errorf("peer lab of %v not specified", *peer.NodeName)
+	}
+	if link.IsLocal() {
+		return fmt.Errorf("lab-to-lab link can't be local")
+	}
+	return nil
+}
+
+func getCrossLabEndpoint(ns, lab, node string) string {
+	return ns + "/" + lab + "/" + node
+}
+
+// getCrossLabEndpoints returns endpoints of the lab-to-lab link in order of spoke index,
+// and spoke index of the node of this lab
+func (lab *Lab) getCrossLabEndpoints(link *Link) ([]string, int) {
+	local, peer := link.getCrossLabConnectors()
+	peerNS := lab.Namespace
+	if peer.PeerLab.Namespace != nil {
+		peerNS = *peer.PeerLab.Namespace
+	}
+	localEP := getCrossLabEndpoint(lab.Namespace, lab.Name, *local.NodeName)
+	eps := []string{localEP, getCrossLabEndpoint(peerNS, peer.PeerLab.Name, *peer.NodeName)}
+	slices.Sort(eps)
+	return eps, slices.Index(eps, localEP)
+}
+
+// GetCrossLabLANName returns name of the shared LAN of a lab-to-lab link, both labs get the same name
+func GetCrossLabLANName(endpoints []string) string {
+	h := fnv.New64a()
+	h.Write([]byte(strings.Join(endpoints, ",")))
+	return fmt.Sprintf("xlab%016x", h.Sum64())
+}
+
+// GetLANKey returns the namespaced name of the LAN of a link, LAN of lab-to-lab link is in the operator namespace
+func (lab *Lab) GetLANKey(linkName string) types.NamespacedName {
+	link := lab.Spec.LinkList[linkName]
+	if link != nil && link.IsCrossLab() {
+		eps, _ := lab.getCrossLabEndpoints(link)
+		return types.NamespacedName{Namespace: MYNAMESPACE, Name: GetCrossLabLANName(eps)}
+	}
+	return types.NamespacedName{Namespace: lab.Namespace, Name: Getk8lanName(lab.Name, linkName)}
+}
+
+// GetCrossLabSpoke returns the spoke of this lab in the shared LAN of the lab-to-lab link
+func (lab *Lab) GetCrossLabSpoke(linkName string, lan *k8slan.LAN) string {
+	_, index := lab.getCrossLabEndpoints(lab.Spec.LinkList[linkName])
+	return getSpokeName(*lan.Spec.VNI, index)
+}
+
+// ensureCrossLabLink creates the shared LAN of a lab-to-lab link if it doesn't exist, and joins it;
+// the LAN is owned by neither lab, it is removed when both labs left, vni is used if the LAN is created
+func (plab *ParsedLab) ensureCrossLabLink(ctx context.Context, clnt client.Client, linkName string, link *Link, vni int32) error {
+	gconf := plab.Lab.GetConfig()
+	key := plab.Lab.GetLANKey(linkName)
+	eps, index := plab.Lab.getCrossLabEndpoints(link)
+	lan := new(k8slan.LAN)
+	err := clnt.Get(ctx, key, lan)
+	create := false
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return fmt.Errorf("unexpected error getting existing LAN %v, %w", key, err)
+		}
+		create = true
+		lan = &k8slan.LAN{
+			Spec: k8slan.LANSpec{
+				NS:           GetPointerVal(key.Name),
+				BridgeName:   GetPointerVal(Getk8lanBRName(plab.Lab.Name, linkName)),
+				VxLANName:    GetPointerVal(Getk8lanVxName(plab.Lab.Name, linkName, vni)),
+				VNI:          GetPointerVal(vni),
+				DefaultVxDev: *gconf.VXLANDefaultDev,
+				VxDevMap:     gconf.VxDevMap,
+				VxPort:       GetPointerVal(VxLANPort),
+				VxLANGrp:     gconf.VXLANGrpAddr,
+				SpokeList:    []string{},
+			},
+		}
+		lan.Name = key.Name
+		lan.Namespace = key.Namespace
+		lan.Labels = map[string]string{
+			K8SLABELAPPKey:      K8SLABELAPPVAL,
+			CrossLabLANLabelKey: "true",
+		}
+		lan.Annotations = map[string]string{
+			CrossLabEndpointsAnnotation: strings.Join(eps, ","),
+		}
+		lan.Finalizers = append(lan.Finalizers, FinalizerName)
+	}
+	spokeName := getSpokeName(*lan.Spec.VNI, index)
+	local, _ := link.getCrossLabConnectors()
+	plab.addSpoke(spokeName, linkName, local)
+	plab.CrossLabLinkMap[linkName] = key.Name
+	if slices.Contains(lan.Spec.SpokeList, spokeName) {
+		return nil
+	}
+	lan.Spec.SpokeList = append(lan.Spec.SpokeList, spokeName)
+	slices.Sort(lan.Spec.SpokeList)
+	if create {
+		return clnt.Create(ctx, lan)
+	}
+	return clnt.Update(ctx, lan)
+}
+
+// isCrossLabSpoke returns true if the spoke is in a shared LAN of lab-to-lab link
+func (plab *ParsedLab) isCrossLabSpoke(spokeName string) bool {
+	_, ok := plab.CrossLabLinkMap[plab.SpokeLinkMap[spokeName]]
+	return ok
+}
+
+// getLANName returns name of the LAN of a link
+func (plab *ParsedLab) getLANName(linkName string) string {
+	if lanName, ok := plab.CrossLabLinkMap[linkName]; ok {
+		return lanName
+	}
+	return Getk8lanName(plab.Lab.Name, linkName)
+}
+
+// getNADRef returns reference to NAD of the spoke in multus network selection,
+// NADs of shared LAN are in the operator namespace
+func (plab *ParsedLab) getNADRef(spokeName, nadName string) string {
+	if plab.isCrossLabSpoke(spokeName) {
+		return MYNAMESPACE + "/" + nadName
+	}
+	return nadName
+}
+
+// GetCrossLabLinks returns lab-to-lab links the lab is an endpoint of, from shared LANs in lans,
+// including ones only declared by the peer lab
+func (lab *Lab) GetCrossLabLinks(lans []k8slan.LAN) []CrossLabLink {
+	links := make(map[string]string) //key is LAN name, value is link name
+	for linkName, link := range lab.Spec.LinkList {
+		if link != nil && link.IsCrossLab() {
+			links[lab.GetLANKey(linkName).Name] = linkName
+		}
+	}
+	prefix := getCrossLabEndpoint(lab.Namespace, lab.Name, "")
+	var r []CrossLabLink
+	for _, lan := range lans {
+		eps := strings.Split(lan.Annotations[CrossLabEndpointsAnnotation], ",")
+		if len(eps) != 2 || lan.Spec.VNI == nil {
+			continue
+		}
+		for i, ep := range eps {
+			if !strings.HasPrefix(ep, prefix) {
+				continue
+			}
+			r = append(r, CrossLabLink{
+				Link:      links[lan.Name],
+				Node:      strings.TrimPrefix(ep, prefix),
+				Peer:      eps[1-i],
+				LAN:       lan.Name,
+				Connected: slices.Contains(lan.Spec.SpokeList, getSpokeName(*lan.Spec.VNI, 0)) && slices.Contains(lan.Spec.SpokeList, getSpokeName(*lan.Spec.VNI, 1)),
+			})
+			break
+		}
+	}
+	slices.SortFunc(r, func(a, b CrossLabLink) int { return strings.Compare(a.LAN, b.LAN) })
+	return r
+}
+
+// GetCrossLabLANLabs returns labs of both endpoints of a shared LAN
+func GetCrossLabLANLabs(lan *k8slan.LAN) []types.NamespacedName {
+	var r []types.NamespacedName
+	for _, ep := range strings.Split(lan.Annotations[CrossLabEndpointsAnnotation], ",") {
+		if fields := strings.Split(ep, "/"); len(fields) == 3 {
+			r = append(r, types.NamespacedName{Namespace: fields[0], Name: fields[1]})
+		}
+	}
+	return r
+}
@@ -0,0 +1,111 @@
+package v1beta1
+
+import (
+	"strings"
+	"testing"
+
+	k8slan "github.com/hujun-open/k8slan/api/v1beta1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func newTestCrossLabLink(node, peerLab, peerNode string) *Link {
+	link := newTestLink(false, node)
+	link.Connectors = append(link.Connectors, Connector{
+		NodeName: ReturnPointerVal(peerNode),
+		Kind:     ReturnPointerVal(ConnectorKindLab),
+		PeerLab:  &PeerLab{Namespace: ReturnPointerVal("ns1"), Name: peerLab},
+	})
+	return link
+}
+
+func TestCrossLabLink(t *testing.T) {
+	testList := []struct {
+		link  *Link
+		valid bool
+	}{
+		{newTestCrossLabLink("srl1", "lab2", "srl1"), true},
+		{newTestCrossLabLink("srl1", "", "srl1"), false},
+		{func() *Link {
+			link := newTestCrossLabLink("srl1", "lab2", "srl1")
+			link.Local = ReturnPointerVal(true)
+			return link
+		}(), false},
+		{func() *Link {
+			link := newTestCrossLabLink("srl1", "lab2", "srl1")
+			link.Connectors = append(link.Connectors, Connector{NodeName: ReturnPointerVal("srl2")})
+			return link
+		}(), false},
+		{func() *Link {
+			link := newTestLink(false, "srl1", "srl2")
+			link.Connectors[1].PeerLab = &PeerLab{Name: "lab2"}
+			return link
+		}(), false},
+	}
+	for i, c := range testList {
+		err := c.link.Validate()
+		if (err == nil) != c.valid {
+			t.Fatalf("case %d: expect valid %v, got error %v", i, c.valid, err)
+		}
+	}
+
+	//both labs derive the same LAN, with different spoke index
+	lab1 := &Lab{}
+	lab1.Name, lab1.Namespace = "lab1", "ns1"
+	lab1.Spec.LinkList = map[string]*Link{"x1": newTestCrossLabLink("srl1", "lab2", "srl1")}
+	lab2 := &Lab{}
+	lab2.Name, lab2.Namespace = "lab2", "ns1"
+	lab2.Spec.LinkList = map[string]*Link{"x2": newTestCrossLabLink("srl1", "lab1", "srl1")}
+	eps1, index1 := lab1.getCrossLabEndpoints(lab1.Spec.LinkList["x1"])
+	eps2, index2 := lab2.getCrossLabEndpoints(lab2.Spec.LinkList["x2"])
+	key1, key2 := lab1.GetLANKey("x1"), lab2.GetLANKey("x2")
+	if key1 != key2 || key1.Namespace != MYNAMESPACE || GetCrossLabLANName(eps1) != GetCrossLabLANName(eps2) {
+		t.Fatalf("labs get different shared LAN, %v and %v", key1, key2)
+	}
+	if index1 != 0 || index2 != 1 {
+		t.Fatalf("unexpected spoke index %d and %d", index1, index2)
+	}
+	if labs := GetCrossLabLANLabs(&k8slan.LAN{}); len(labs) != 0 {
+		t.Fatalf("unexpected labs %v", labs)
+	}
+
+	lan := k8slan.LAN{}
+	lan.Name = key1.Name
+	lan.Annotations = map[string]string{CrossLabEndpointsAnnotation: "ns1/lab1/srl1,ns1/lab2/srl1"}
+	lan.Spec.VNI = ReturnPointerVal(int32(100))
+	lan.Spec.SpokeList = []string{getSpokeName(100, 0)}
+	if labs := GetCrossLabLANLabs(&lan); len(labs) != 2 || labs[0].Name != "lab1" || labs[1].Name != "lab2" {
+		t.Fatalf("unexpected labs %v", labs)
+	}
+	if spoke := lab2.GetCrossLabSpoke("x2", &lan); spoke != getSpokeName(100, 1) {
+		t.Fatalf("unexpected spoke %v", spoke)
+	}
+	r := lab1.GetCrossLabLinks([]k8slan.LAN{lan})
+	if len(r) != 1 || r[0].Link != "x1" || r[0].Node != "srl1" || r[0].Peer != "ns1/lab2/srl1" || r[0].Connected {
+		t.Fatalf("unexpected lab-to-lab links %+v", r)
+	}
+	lan.Spec.SpokeList = append(lan.Spec.SpokeList, getSpokeName(100, 1))
+	//lab3 is not an endpoint, lab2 without its own declaration still sees the link
+	lab3 := &Lab{}
+	lab3.Name, lab3.Namespace = "lab3", "ns1"
+	if r := lab3.GetCrossLabLinks([]k8slan.LAN{lan}); len(r) != 0 {
+		t.Fatalf("unexpected lab-to-lab links %+v", r)
+	}
+	lab2.Spec.LinkList = nil
+	r = lab2.GetCrossLabLinks([]k8slan.LAN{lan})
+	if len(r) != 1 || r[0].Link != "" || r[0].Peer != "ns1/lab1/srl1" || !r[0].Connected {
+		t.Fatalf("unexpected lab-to-lab links %+v", r)
+	}
+
+	spec := &LabSpec{
+		NodeList: map[string]*OneOfSystem{"srl1": {Pod: &GeneralPod{Image: ReturnPointerVal("alpine"), PvcSize: ReturnPointerVal(resource.MustParse("1Gi"))}}},
+		LinkList: map[string]*Link{
+			"x1": newTestCrossLabLink("srl1", "lab2", "srl1"),
+			"x2": newTestCrossLabLink("srl1", "lab2", "srl1"),
+		},
+	}
+	if err := spec.Validate(); err == nil {
+		t.Fatalf("expect error for parallel lab-to-lab links")
+	} else if !strings.Contains(err.Error(), "connect the same nodes") {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
@@ -0,0 +1,155 @@
+package v1beta1
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"syscall"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func init() {
+	NewSysRegistry[CRPDNode] = func() System { return new(CRPD) }
+}
+
+const (
+	CRPDNode         NodeType = "crpd"
+	DefaultCRPDMem   string   = "1Gi"
+	CRPDConfigDir    string   = "/config"
+	JunosStartCfgKey string   = "juniper.conf"
+	//cRPD saves committed config as juniper.conf.gz
+	JunosCommittedCfgKey string = "juniper.conf.gz"
+)
+
+// CRPD specifies a Juniper cRPD container router
+type CRPD struct {
+	//cRPD container image
+	Image *string `json:"image,omitempty"`
+	//a k8s configmap in the lab namespace with key "juniper.conf",
+	//it is copied into /config/juniper.conf on first boot
+	// +optional
+	// +nullable
+	StartupConfig *string `json:"startupConfig,omitempty"`
+	//a k8s secret contains the license with "license" as the key, it is mounted as /config/license/safenet/junos_sfnt.lic
+	// +optional
+	// +nullable
+	License *string `json:"license,omitempty"`
+	//requested memory in k8s resource unit
+	// +optional
+	// +nullable
+	ReqMemory *resource.Quantity `json:"memory,omitempty"`
+	//requested cpu in k8s resource unit
+	// +optional
+	// +nullable
+	ReqCPU *resource.Quantity `json:"cpu,omitempty"`
+}
+
+func (crpd *CRPD) SetToAppDefVal() {
+	crpd.ReqMemory = ReturnPointerVal(resource.MustParse(DefaultCRPDMem))
+}
+
+func (crpd *CRPD) FillDefaultVal(nodeName string) {
+
+}
+
+func (crpd *CRPD) Validate(lab *LabSpec, nodeName string) error {
+	if crpd.Image == nil {
+		return fmt.Errorf("image not specified")
+	}
+	return validatePodPortIds(lab, nodeName, validateLinuxIntfName)
+}
+
+func (crpd *CRPD) Ensure(ctx context.Context, nodeName string, clnt client.Client, forceRemoval bool) error {
+	lab, err := getParsedLab(ctx)
+	if err != nil {
+		return err
+	}
+	spec := &cfgPodSpec{
+		NodeType:  CRPDNode,
+		Image:     *crpd.Image,
+		PVCSuffix: "config",
+		PVCSize:   resource.MustParse(EtcPVCSize),
+		MountPath: CRPDConfigDir,
+		//interface is named as port id, or ethN
+		AutoIntfName: func(i int) string { return fmt.Sprintf("eth%d", i) },
+		ReqCPU:       crpd.ReqCPU,
+		ReqMemory:    crpd.ReqMemory,
+	}
+	//copy startup config on first boot, existing juniper.conf is kept
+	if crpd.StartupConfig != nil {
+		spec.StartupConfig = crpd.StartupConfig
+		spec.InitCmds = []string{copyStartupCfgCmd(JunosStartCfgKey)}
+	}
+	pod, _, err := lab.newCfgPod(ctx, clnt, nodeName, spec)
+	if err != nil {
+		return err
+	}
+	pod.Spec.Volumes = append(pod.Spec.Volumes, corev1.Volume{
+		Name: "varlog",
+		VolumeSource: corev1.VolumeSource{
+			EmptyDir: &corev1.EmptyDirVolumeSource{},
+		},
+	})
+	pod.Spec.Containers[0].VolumeMounts = append(pod.Spec.Containers[0].VolumeMounts, corev1.VolumeMount{
+		Name:      "varlog",
+		MountPath: "/var/log",
+	})
+	if crpd.License != nil {
+		pod.Spec.Containers[0].VolumeMounts = append(pod.Spec.Containers[0].VolumeMounts, corev1.VolumeMount{
+			Name:      "lic",
+			MountPath: "/config/license/safenet/junos_sfnt.lic",
+			SubPath:   "license",
+		})
+		pod.Spec.Volumes = append(pod.Spec.Volumes, corev1.Volume{
+			Name: "lic",
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: *crpd.License,
+				},
+			},
+		})
+	}
+	err = createIfNotExistsOrRemove(ctx, clnt, lab, pod, true, false)
+	if err != nil {
+		return fmt.Errorf("failed to create cRPD pod %v in lab %v, %w", nodeName, lab.Lab.Name, err)
+	}
+	return nil
+}
+
+// GetCfgSnapshotSource implements CfgSnapshotSystem interface, the committed config /config/juniper.conf.gz is collected by default
+func (crpd *CRPD) GetCfgSnapshotSource(labName, nodeName string, paths []string) (*CfgSnapshotSource, error) {
+	plist, err := getCfgSnapshotPaths(CRPDConfigDir, CRPDConfigDir, paths, []string{JunosCommittedCfgKey})
+	if err != nil {
+		return nil, err
+	}
+	return &CfgSnapshotSource{
+		PodName:   GetPodName(labName, nodeName),
+		Container: "main",
+		RootDir:   CRPDConfigDir,
+		Paths:     plist,
+	}, nil
+}
+
+// Shell runs Junos cli in the pod
+func (crpd *CRPD) Shell(ctx context.Context, clnt client.Client, ns, lab, chassis, username string) {
+	envList := []string{fmt.Sprintf("HOME=%v", os.Getenv("HOME"))}
+	fmt.Printf("connecting to %v\n", GetPodName(lab, chassis))
+	syscall.Exec("/bin/sh",
+		[]string{"sh", "-c",
+			fmt.Sprintf("kubectl -n %v exec -it %v -- cli",
+				ns, GetPodName(lab, chassis))},
+		envList)
+}
+
+func (crpd *CRPD) Console(ctx context.Context, clnt client.Client, ns, lab, chassis string) {
+	envList := []string{fmt.Sprintf("HOME=%v", os.Getenv("HOME"))}
+	fmt.Printf("connecting to %v\n", GetPodName(lab, chassis))
+	syscall.Exec("/bin/sh",
+		[]string{"sh", "-c",
+			fmt.Sprintf("kubectl -n %v exec -it %v -- bash",
+				ns, GetPodName(lab, chassis))},
+		envList)
+}
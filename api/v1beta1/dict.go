@@ -9,17 +9,25 @@ const (
 	ChassisNameAnnotation    = "chassis.kubenetlab.net/name"
 	ChassisTypeAnnotation    = "chassis.kubenetlab.net/type"
 	FTPPathMapAnnotation     = "kubenetlab.net/ftppathmap" //json of map[string]string
+	ConsoleLogAnnotation     = "kubenetlab.net/consolelog" //"true" if the console is recorded
 	KvirtSideCarAnnontation  = "hooks.kubevirt.io/hookSidecars"
+	SMBIOSProductAnnotation  = "kubenetlab.net/smbiosproduct"      //SMBIOS system product set by the sidecar
+	TemplateGenAnnotation    = "kubenetlab.net/templategeneration" //generation of LabTemplate the lab is rendered from
 	K8SLABELAPPVAL           = `kubenetlab`
 	K8SLABELAPPKey           = `app.kubernetes.io/name`
 	K8SLABELSETUPKEY         = `lab.kubenetlab.net/name`
 	K8SLABELNodeKEY          = `node.kubenetlab.net/name`
 	BridgeIndexLabelKey      = "bridge.kubenetlab.net/index"
+	KNLConfigLabelKey        = "config.kubenetlab.net/name"     //namespace label selects KNLConfig of labs in the namespace
+	GoldenImageLabelKey      = "image.kubenetlab.net/golden"    //name of GoldenImage the DataVolume uses
+	LocalGroupLabelKey       = "locallink.kubenetlab.net/group" //co-location group of nodes connected by local links
 	KNLROOTName              = `knlroot`
 	VMDiskSubFolder          = `vmdisks`
 	IMGSubFolder             = `imgs`
 	LicSubFolder             = `lic`
 	CfgSubFolder             = `cfgs`
+	ConsoleLogSubFolder      = `consolelogs`
+	SnapshotSubFolder        = `snapshots`
 	KVirtPodPVCMountRoot     = `/var/run/kubevirt-private/vmi-disks/`
 	PVCName                  = `knl-pvc` //this must be inline with config/default/pvc
 	macVTAPResourceKey       = `k8s.v1.cni.cncf.io/resourceName`
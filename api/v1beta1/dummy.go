@@ -0,0 +1,178 @@
+package v1beta1
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"syscall"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func init() {
+	NewSysRegistry[DummyNode] = func() System { return new(Dummy) }
+}
+
+const (
+	DummyNode         NodeType = "dummy"
+	DefaultDummyImage string   = "alpine:3.20"
+	DefaultDummyMem   string   = "16Mi"
+)
+
+// podIdleCmds keeps a shell script running until the pod is terminated
+var podIdleCmds = []string{"trap 'exit 0' TERM INT", "while true; do sleep 3600 & wait $!; done"}
+
+// Dummy specifies a lightweight test node, it brings up every connector interface and applies connector's addrs and routes,
+// it could stand in for any node type to verify the wiring of a topology without licenses or real images
+type Dummy struct {
+	//container image, it must have a shell and the ip command
+	Image *string `json:"image,omitempty"`
+	//if true, interface of a connector is named after its PortId with "/" and ":" replaced by "-",
+	//and the original PortId is set as the interface alias; otherwise interfaces are named as eth1, eth2...
+	// +optional
+	// +nullable
+	ReplayPortId *bool `json:"replayPortId,omitempty"`
+	//requested memory in k8s resource unit
+	// +optional
+	// +nullable
+	ReqMemory *resource.Quantity `json:"memory,omitempty"`
+	//requested cpu in k8s resource unit
+	// +optional
+	// +nullable
+	ReqCPU *resource.Quantity `json:"cpu,omitempty"`
+}
+
+func (dummy *Dummy) SetToAppDefVal() {
+	dummy.Image = ReturnPointerVal(DefaultDummyImage)
+	dummy.ReplayPortId = ReturnPointerVal(false)
+	dummy.ReqMemory = ReturnPointerVal(resource.MustParse(DefaultDummyMem))
+}
+
+func (dummy *Dummy) FillDefaultVal(nodeName string) {
+
+}
+
+func (dummy *Dummy) replayPortId() bool {
+	return dummy.ReplayPortId != nil && *dummy.ReplayPortId
+}
+
+// dummyIntfName returns the linux interface name replaying portId
+func dummyIntfName(portId string) string {
+	return strings.NewReplacer("/", "-", ":", "-").Replace(portId)
+}
+
+func (dummy *Dummy) Validate(lab *LabSpec, nodeName string) error {
+	if dummy.Image == nil {
+		return fmt.Errorf("image not specified")
+	}
+	if !dummy.replayPortId() {
+		return nil
+	}
+	used := make(map[string]string)
+	return validatePodPortIds(lab, nodeName, func(portId string) error {
+		name := dummyIntfName(portId)
+		if err := validateLinuxIntfName(name); err != nil {
+			return err
+		}
+		if prev, ok := used[name]; ok {
+			return fmt.Errorf("it has same interface name %v as port %v", name, prev)
+		}
+		used[name] = portId
+		return nil
+	})
+}
+
+// genIntfCmds returns the ip commands that bring up all interfaces in intfList with link's MTU, connector's addrs and routes,
+// intfList is the return of setPodNetworks; PortId is set as interface alias if replayPortId is true
+func genIntfCmds(lab *ParsedLab, nodeName string, intfList []string, replayPortId bool) []string {
+	lines := []string{}
+	i := 0
+	for _, linkName := range GetSortedKeySlice(lab.SpokeMap[nodeName]) {
+		for _, spokeName := range lab.SpokeMap[nodeName][linkName] {
+			c := lab.SpokeConnectorMap[spokeName]
+			intf := intfList[i]
+			i++
+			if mtu := lab.Lab.Spec.LinkList[linkName].MTU; mtu != nil {
+				lines = append(lines, fmt.Sprintf("ip link set dev %v mtu %d", intf, *mtu))
+			}
+			lines = append(lines, fmt.Sprintf("ip link set dev %v up", intf))
+			if replayPortId && c.PortId != nil {
+				lines = append(lines, fmt.Sprintf("ip link set dev %v alias '%v'", intf, *c.PortId))
+			}
+			for _, addr := range c.Addrs {
+				lines = append(lines, fmt.Sprintf("ip addr add %v dev %v", addr, intf))
+			}
+			for _, routeStr := range c.Routes {
+				r, _ := parseRoute(routeStr)
+				family := "-4"
+				if r.To.Addr().Is6() {
+					family = "-6"
+				}
+				lines = append(lines, fmt.Sprintf("ip %v route add %v via %v dev %v", family, r.To, r.Via, intf))
+			}
+		}
+	}
+	return lines
+}
+
+// genDummyScript returns the shell script that configures interfaces and then waits until terminated
+func genDummyScript(lab *ParsedLab, nodeName string, intfList []string, replayPortId bool) string {
+	lines := genIntfCmds(lab, nodeName, intfList, replayPortId)
+	lines = append(lines, podIdleCmds...)
+	return strings.Join(lines, "\n")
+}
+
+func (dummy *Dummy) Ensure(ctx context.Context, nodeName string, clnt client.Client, forceRemoval bool) error {
+	val := ctx.Value(ParsedLabKey)
+	if val == nil {
+		return MakeErr(fmt.Errorf("failed to get parsed lab obj from context"))
+	}
+	var lab *ParsedLab
+	var ok bool
+	if lab, ok = val.(*ParsedLab); !ok {
+		return MakeErr(fmt.Errorf("context stored value is not a ParsedLabSpec"))
+	}
+	pod := NewBasePod(lab.Lab.Name, nodeName, lab.Lab.Namespace, *dummy.Image, DummyNode)
+	//refer to NADs, PortId is ignored unless ReplayPortId is true
+	portName := func(string) string { return "" }
+	if dummy.replayPortId() {
+		portName = dummyIntfName
+	}
+	intfList := setPodNetworks(lab, nodeName, pod, func(i int) string { return fmt.Sprintf("eth%d", i) }, portName)
+	pod.Spec.Containers[0].Command = []string{"sh", "-c", genDummyScript(lab, nodeName, intfList, dummy.replayPortId())}
+	pod.Spec.Containers[0].SecurityContext = &corev1.SecurityContext{
+		Capabilities: &corev1.Capabilities{
+			Add: []corev1.Capability{"NET_ADMIN"},
+		},
+	}
+	//add resource request
+	pod.Spec.Containers[0].Resources.Requests = make(corev1.ResourceList)
+	if dummy.ReqCPU != nil {
+		pod.Spec.Containers[0].Resources.Requests[corev1.ResourceCPU] = *dummy.ReqCPU
+	}
+	if dummy.ReqMemory != nil {
+		pod.Spec.Containers[0].Resources.Requests[corev1.ResourceMemory] = *dummy.ReqMemory
+	}
+	err := createIfNotExistsOrRemove(ctx, clnt, lab, pod, true, false)
+	if err != nil {
+		return fmt.Errorf("failed to create dummy pod %v in lab %v, %w", nodeName, lab.Lab.Name, err)
+	}
+	return nil
+}
+
+func (dummy *Dummy) Shell(ctx context.Context, clnt client.Client, ns, lab, chassis, username string) {
+	envList := []string{fmt.Sprintf("HOME=%v", os.Getenv("HOME"))}
+	fmt.Printf("connecting to %v\n", GetPodName(lab, chassis))
+	syscall.Exec("/bin/sh",
+		[]string{"sh", "-c",
+			fmt.Sprintf("kubectl -n %v exec -it %v -- sh",
+				ns, GetPodName(lab, chassis))},
+		envList)
+}
+
+func (dummy *Dummy) Console(ctx context.Context, clnt client.Client, ns, lab, chassis string) {
+	dummy.Shell(ctx, clnt, ns, lab, chassis, "")
+}
@@ -0,0 +1,214 @@
+package v1beta1
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+type ConnectorKind string
+
+const (
+	ConnectorKindNode     ConnectorKind = "node"
+	ConnectorKindExternal ConnectorKind = "external"
+	ConnectorKindLab      ConnectorKind = "lab"
+)
+
+type ExternalMode string
+
+const (
+	// ExternalModeMACVLAN attaches a passthru macvlan of the host interface
+	ExternalModeMACVLAN ExternalMode = "macvlan"
+	// ExternalModeVLAN attaches a VLAN sub-interface of the host interface
+	ExternalModeVLAN ExternalMode = "vlan"
+	// ExternalModeVeth creates a veth pair, the end named as the host interface stays in host namespace
+	ExternalModeVeth ExternalMode = "veth"
+)
+
+const (
+	// ExternalType is the chassis type label value of external connector pods
+	ExternalType NodeType = "external"
+	// externalPodIntf is the interface name of the LAN spoke in external connector pod
+	externalPodIntf = "lan0"
+	externalPodBR   = "br0"
+)
+
+// ExternalConnector specifies a host interface of a worker attached to the link
+type ExternalConnector struct {
+	//+required
+	//name of k8s worker the interface is on
+	Worker string `json:"worker"`
+	//+required
+	//name of the host interface, it must be listed in externalInterfaces of KNLConfig;
+	//in veth mode, it is the name of veth end created in host namespace
+	Interface string `json:"interface"`
+	//VLAN ID, traffic of the link is tagged with it on the host interface, requires vlan mode
+	// +optional
+	// +nullable
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=4094
+	VLAN *int32 `json:"vlan,omitempty"`
+	//how the interface is attached to the link, macvlan, vlan or veth;
+	//default is vlan if VLAN is specified, otherwise macvlan
+	// +optional
+	// +nullable
+	// +kubebuilder:validation:Enum=macvlan;vlan;veth
+	Mode *ExternalMode `json:"mode,omitempty"`
+}
+
+// IsExternal returns true if the connector attaches a host interface instead of a node
+func (c *Connector) IsExternal() bool {
+	return c.Kind != nil && *c.Kind == ConnectorKindExternal
+}
+
+func (ext *ExternalConnector) getMode() ExternalMode {
+	if ext.Mode != nil {
+		return *ext.Mode
+	}
+	if ext.VLAN != nil {
+		return ExternalModeVLAN
+	}
+	return ExternalModeMACVLAN
+}
+
+func (ext *ExternalConnector) validate() error {
+	if ext.Worker == "" {
+		return fmt.Errorf("worker not specified")
+	}
+	if ext.Interface == "" || len(ext.Interface) > 15 {
+		return fmt.Errorf("invalid interface name %v", ext.Interface)
+	}
+	switch ext.getMode() {
+	case ExternalModeVLAN:
+		if ext.VLAN == nil {
+			return fmt.Errorf("vlan mode requires VLAN ID")
+		}
+		if *ext.VLAN < 1 || *ext.VLAN > 4094 {
+			return fmt.Errorf("invalid VLAN ID %d", *ext.VLAN)
+		}
+	case ExternalModeMACVLAN, ExternalModeVeth:
+		if ext.VLAN != nil {
+			return fmt.Errorf("VLAN ID requires vlan mode")
+		}
+	default:
+		return fmt.Errorf("unsupported mode %v", ext.getMode())
+	}
+	return nil
+}
+
+// IsExternalIntfAllowed returns true if the host interface of the worker is in externalInterfaces
+func (spec KNLConfigSpec) IsExternalIntfAllowed(worker, intf string) bool {
+	return slices.Contains(spec.ExternalIntfs[worker], intf) || slices.Contains(spec.ExternalIntfs["*"], intf)
+}
+
+// ValidateExternals checks host interfaces of external connectors are allowed by conf,
+// workerExists returns true if the k8s worker exists
+func (spec *LabSpec) ValidateExternals(conf KNLConfigSpec, workerExists func(string) (bool, error)) error {
+	for _, linkName := range GetSortedKeySlice(spec.LinkList) {
+		for _, c := range spec.LinkList[linkName].Connectors {
+			if !c.IsExternal() {
+				continue
+			}
+			ok, err := workerExists(c.External.Worker)
+			if err != nil {
+				return fmt.Errorf("failed to check worker %v, %w", c.External.Worker, err)
+			}
+			if !ok {
+				return fmt.Errorf("worker %v of external connector %v in link %v doesn't exist", c.External.Worker, *c.NodeName, linkName)
+			}
+			if !conf.IsExternalIntfAllowed(c.External.Worker, c.External.Interface) {
+				return fmt.Errorf("interface %v of worker %v is not allowed by externalInterfaces of KNLConfig", c.External.Interface, c.External.Worker)
+			}
+		}
+	}
+	return nil
+}
+
+// getExternalHostIntfName returns name of the interface created in host namespace and then moved into the external connector pod,
+// it is unique per lab connector
+func getExternalHostIntfName(ns, lab, name string) string {
+	h := fnv.New32a()
+	h.Write([]byte(ns + "/" + lab + "/" + name))
+	return fmt.Sprintf("knlx%08x", h.Sum32())
+}
+
+// genExternalScript returns the shell script of external connector pod, it bridges the LAN spoke with the interface
+// created from the host interface; hostIntf is moved from host namespace into the pod, it is removed with the pod,
+// so is the veth end in host namespace; the script could be re-run after a container restart in the same pod
+func genExternalScript(ext *ExternalConnector, hostIntf string, mtu *int32) []string {
+	host := "nsenter -t 1 -n "
+	lines := []string{
+		"set -e",
+		//hostIntf is already in the pod if the container restarts
+		fmt.Sprintf("if ! ip link show %v >/dev/null 2>&1; then", hostIntf),
+		host + fmt.Sprintf("ip link del %v 2>/dev/null || true", hostIntf),
+	}
+	switch ext.getMode() {
+	case ExternalModeVLAN:
+		lines = append(lines, host+fmt.Sprintf("ip link add link %v name %v type vlan id %d", ext.Interface, hostIntf, *ext.VLAN))
+	case ExternalModeMACVLAN:
+		lines = append(lines, host+fmt.Sprintf("ip link add link %v name %v type macvlan mode passthru", ext.Interface, hostIntf))
+	case ExternalModeVeth:
+		lines = append(lines,
+			host+fmt.Sprintf("ip link add %v type veth peer name %v", ext.Interface, hostIntf),
+			host+fmt.Sprintf("ip link set dev %v up", ext.Interface),
+		)
+	}
+	//the pod shares host PID namespace, so $$ is the PID in host
+	lines = append(lines,
+		host+fmt.Sprintf("ip link set %v netns $$", hostIntf),
+		"fi",
+		fmt.Sprintf("ip link show %v >/dev/null 2>&1 || ip link add %v type bridge", externalPodBR, externalPodBR),
+		fmt.Sprintf("ip link set %v master %v", externalPodIntf, externalPodBR),
+		fmt.Sprintf("ip link set %v master %v", hostIntf, externalPodBR),
+	)
+	for _, intf := range []string{externalPodIntf, hostIntf, externalPodBR} {
+		if mtu != nil {
+			lines = append(lines, fmt.Sprintf("ip link set dev %v mtu %d || true", intf, *mtu))
+		}
+		lines = append(lines, fmt.Sprintf("ip link set dev %v up", intf))
+	}
+	lines = append(lines, "set +e")
+	return append(lines, podIdleCmds...)
+}
+
+// EnsureExternals creates a pod for each external connector on its worker,
+// the pod connects to the link's LAN as a spoke and bridges it with the host interface
+func (plab *ParsedLab) EnsureExternals(ctx context.Context, clnt client.Client) error {
+	gconf := plab.Lab.GetConfig()
+	for _, linkName := range GetSortedKeySlice(plab.Lab.Spec.LinkList) {
+		link := plab.Lab.Spec.LinkList[linkName]
+		for _, c := range link.Connectors {
+			if !c.IsExternal() {
+				continue
+			}
+			name := *c.NodeName
+			pod := NewBasePod(plab.Lab.Name, name, plab.Lab.Namespace, *gconf.VxLANAgentImage, ExternalType)
+			pod.Spec.NodeName = c.External.Worker
+			pod.Spec.HostPID = true
+			pod.Spec.Containers[0].SecurityContext = &corev1.SecurityContext{
+				Privileged: ReturnPointerVal(true),
+			}
+			pod.Spec.Containers[0].Command = []string{"sh", "-c",
+				strings.Join(genExternalScript(c.External, getExternalHostIntfName(plab.Lab.Namespace, plab.Lab.Name, name), link.MTU), "\n")}
+			pod.Spec.Containers[0].Resources.Limits = make(corev1.ResourceList)
+			netList := []string{}
+			for _, spokeName := range plab.SpokeMap[name][linkName] {
+				nadName, resKey := plab.getPodSpokeNAD(spokeName, false)
+				netList = append(netList, fmt.Sprintf("%v@%v", nadName, externalPodIntf))
+				pod.Spec.Containers[0].Resources.Limits[corev1.ResourceName(resKey)] = resource.MustParse("1")
+			}
+			pod.Annotations[MultusAnnoKey] = strings.Join(netList, ",")
+			if err := createIfNotExistsOrRemove(ctx, clnt, plab, pod, true, false); err != nil {
+				return fmt.Errorf("failed to create external connector pod %v in lab %v, %w", name, plab.Lab.Name, err)
+			}
+		}
+	}
+	return nil
+}
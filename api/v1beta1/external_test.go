@@ -0,0 +1,79 @@
+package v1beta1
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExternalConnector(t *testing.T) {
+	newExt := func(ext *ExternalConnector) Connector {
+		return Connector{
+			NodeName: ReturnPointerVal("tester"),
+			Kind:     ReturnPointerVal(ConnectorKindExternal),
+			External: ext,
+		}
+	}
+	testList := []struct {
+		c     Connector
+		local bool
+		valid bool
+	}{
+		{newExt(&ExternalConnector{Worker: "w1", Interface: "eth2"}), false, true},
+		{newExt(&ExternalConnector{Worker: "w1", Interface: "eth2", VLAN: ReturnPointerVal(int32(100))}), false, true},
+		{newExt(&ExternalConnector{Worker: "w1", Interface: "eth2", VLAN: ReturnPointerVal(int32(4095))}), false, false},
+		{newExt(&ExternalConnector{Worker: "w1", Interface: "eth2", VLAN: ReturnPointerVal(int32(100)),
+			Mode: ReturnPointerVal(ExternalModeVeth)}), false, false},
+		{newExt(&ExternalConnector{Worker: "w1", Interface: "eth2", Mode: ReturnPointerVal(ExternalModeVLAN)}), false, false},
+		{newExt(&ExternalConnector{Interface: "eth2"}), false, false},
+		{newExt(nil), false, false},
+		{newExt(&ExternalConnector{Worker: "w1", Interface: "eth2"}), true, false},
+		{Connector{NodeName: ReturnPointerVal("srl1"), External: &ExternalConnector{Worker: "w1", Interface: "eth2"}}, false, false},
+	}
+	for i, c := range testList {
+		link := newTestLink(c.local, "srl1")
+		link.Connectors = append(link.Connectors, c.c)
+		err := link.Validate()
+		if (err == nil) != c.valid {
+			t.Fatalf("case %d: expect valid %v, got error %v", i, c.valid, err)
+		}
+	}
+
+	spec := &LabSpec{
+		NodeList: map[string]*OneOfSystem{"srl1": {}},
+		LinkList: map[string]*Link{"l1": newTestLink(false, "srl1")},
+	}
+	spec.LinkList["l1"].Connectors = append(spec.LinkList["l1"].Connectors, newExt(&ExternalConnector{Worker: "w1", Interface: "eth2"}))
+	conf := KNLConfigSpec{ExternalIntfs: map[string][]string{"w1": {"eth3"}, "*": {"eth2"}}}
+	workerExists := func(w string) (bool, error) { return w == "w1", nil }
+	if err := spec.ValidateExternals(conf, workerExists); err != nil {
+		t.Fatal(err)
+	}
+	spec.LinkList["l1"].Connectors[1].External.Interface = "eth4"
+	if err := spec.ValidateExternals(conf, workerExists); err == nil {
+		t.Fatalf("expect error for interface not allowed")
+	}
+	spec.LinkList["l1"].Connectors[1].External = &ExternalConnector{Worker: "w2", Interface: "eth2"}
+	if err := spec.ValidateExternals(conf, workerExists); err == nil {
+		t.Fatalf("expect error for worker not exists")
+	}
+
+	hostIntf := getExternalHostIntfName("ns1", "lab1", "tester")
+	if len(hostIntf) > 15 || hostIntf == getExternalHostIntfName("ns1", "lab2", "tester") {
+		t.Fatalf("invalid host interface name %v", hostIntf)
+	}
+	script := strings.Join(genExternalScript(&ExternalConnector{Worker: "w1", Interface: "eth2", VLAN: ReturnPointerVal(int32(100))},
+		hostIntf, ReturnPointerVal(int32(1500))), "\n")
+	for _, s := range []string{
+		"if ! ip link show " + hostIntf + " >/dev/null 2>&1; then",
+		"nsenter -t 1 -n ip link add link eth2 name " + hostIntf + " type vlan id 100",
+		"ip link show br0 >/dev/null 2>&1 || ip link add br0 type bridge",
+		"nsenter -t 1 -n ip link set " + hostIntf + " netns $$",
+		"ip link set " + hostIntf + " master br0",
+		"ip link set lan0 master br0",
+		"ip link set dev br0 mtu 1500",
+	} {
+		if !strings.Contains(script, s) {
+			t.Fatalf("script doesn't contain %v:\n%v", s, script)
+		}
+	}
+}
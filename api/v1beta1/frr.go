@@ -0,0 +1,130 @@
+package v1beta1
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"syscall"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func init() {
+	NewSysRegistry[FRRNode] = func() System { return new(FRR) }
+}
+
+const (
+	FRRNode         NodeType = "frr"
+	DefaultFRRImage string   = "quay.io/frrouting/frr:10.2.1"
+	FRREtcDir       string   = "/etc/frr"
+)
+
+// FRR specifies a FRRouting container router
+type FRR struct {
+	//FRR container image
+	Image *string `json:"image,omitempty"`
+	//a k8s configmap in the lab namespace, its keys like "frr.conf", "daemons" and "vtysh.conf" are copied into /etc/frr on first boot;
+	//files not in the configmap use the defaults of the image
+	// +optional
+	// +nullable
+	Config *string `json:"config,omitempty"`
+	//requested memory in k8s resource unit
+	// +optional
+	// +nullable
+	ReqMemory *resource.Quantity `json:"memory,omitempty"`
+	//requested cpu in k8s resource unit
+	// +optional
+	// +nullable
+	ReqCPU *resource.Quantity `json:"cpu,omitempty"`
+}
+
+func (frr *FRR) SetToAppDefVal() {
+	frr.Image = ReturnPointerVal(DefaultFRRImage)
+}
+
+func (frr *FRR) FillDefaultVal(nodeName string) {
+
+}
+
+func (frr *FRR) Validate(lab *LabSpec, nodeName string) error {
+	if frr.Image == nil {
+		return fmt.Errorf("image not specified")
+	}
+	//interfaces are configured in frr.conf
+	if err := validateNoPodAddrs(lab, nodeName); err != nil {
+		return err
+	}
+	return validatePodPortIds(lab, nodeName, validateLinuxIntfName)
+}
+
+func (frr *FRR) Ensure(ctx context.Context, nodeName string, clnt client.Client, forceRemoval bool) error {
+	lab, err := getParsedLab(ctx)
+	if err != nil {
+		return err
+	}
+	//init-container copies config from configmap and the image defaults without overwriting existing files,
+	//so the saved config in pvc is kept across restarts; it also enables ip forwarding of the pod
+	pod, _, err := lab.newCfgPod(ctx, clnt, nodeName, &cfgPodSpec{
+		NodeType:      FRRNode,
+		Image:         *frr.Image,
+		PVCSuffix:     "etc",
+		PVCSize:       resource.MustParse(EtcPVCSize),
+		MountPath:     FRREtcDir,
+		StartupConfig: frr.Config,
+		InitCmds: []string{
+			copyStartupCfgCmd("*"),
+			fmt.Sprintf("cp -n %v/* %v/", FRREtcDir, cfgPodInitMountPath),
+			fmt.Sprintf("chown -R frr:frr %v", cfgPodInitMountPath),
+			"sysctl -w net.ipv4.ip_forward=1 net.ipv6.conf.all.forwarding=1",
+		},
+		InitPrivileged: true,
+		//interface is named as port id, or ethN
+		AutoIntfName: func(i int) string { return fmt.Sprintf("eth%d", i) },
+		ReqCPU:       frr.ReqCPU,
+		ReqMemory:    frr.ReqMemory,
+	})
+	if err != nil {
+		return err
+	}
+	err = createIfNotExistsOrRemove(ctx, clnt, lab, pod, true, false)
+	if err != nil {
+		return fmt.Errorf("failed to create FRR pod %v in lab %v, %w", nodeName, lab.Lab.Name, err)
+	}
+	return nil
+}
+
+// GetCfgSnapshotSource implements CfgSnapshotSystem interface, /etc/frr is collected
+func (frr *FRR) GetCfgSnapshotSource(labName, nodeName string, paths []string) (*CfgSnapshotSource, error) {
+	plist, err := getCfgSnapshotPaths(FRREtcDir, FRREtcDir, paths, []string{"."})
+	if err != nil {
+		return nil, err
+	}
+	return &CfgSnapshotSource{
+		PodName:   GetPodName(labName, nodeName),
+		Container: "main",
+		RootDir:   FRREtcDir,
+		Paths:     plist,
+	}, nil
+}
+
+// Shell runs vtysh in the pod
+func (frr *FRR) Shell(ctx context.Context, clnt client.Client, ns, lab, chassis, username string) {
+	envList := []string{fmt.Sprintf("HOME=%v", os.Getenv("HOME"))}
+	fmt.Printf("connecting to %v\n", GetPodName(lab, chassis))
+	syscall.Exec("/bin/sh",
+		[]string{"sh", "-c",
+			fmt.Sprintf("kubectl -n %v exec -it %v -- vtysh",
+				ns, GetPodName(lab, chassis))},
+		envList)
+}
+
+func (frr *FRR) Console(ctx context.Context, clnt client.Client, ns, lab, chassis string) {
+	envList := []string{fmt.Sprintf("HOME=%v", os.Getenv("HOME"))}
+	fmt.Printf("connecting to %v\n", GetPodName(lab, chassis))
+	syscall.Exec("/bin/sh",
+		[]string{"sh", "-c",
+			fmt.Sprintf("kubectl -n %v exec -it %v -- sh",
+				ns, GetPodName(lab, chassis))},
+		envList)
+}
@@ -81,8 +81,12 @@ func (gpod *GeneralPod) Ensure(ctx context.Context, nodeName string, clnt client
 		return MakeErr(fmt.Errorf("context stored value is not a ParsedLabSpec"))
 	}
 	//create PVC
-	rootPVC := gpod.getRootPVC(lab.Lab.Namespace, nodeName, lab.Lab.Name, *gpod.PvcSize)
-	err := createIfNotExistsOrRemove(ctx, clnt, lab, rootPVC, false, false)
+	rootPVC := gpod.getRootPVC(lab.Lab.Namespace, nodeName, lab.Lab.Name, *gpod.PvcSize, lab.Lab.GetConfig().PVCStorageClass)
+	err := lab.setRestoreVolSource(ctx, clnt, rootPVC)
+	if err != nil {
+		return err
+	}
+	err = createIfNotExistsOrRemove(ctx, clnt, lab, rootPVC, false, false)
 	if err != nil {
 		return fmt.Errorf("failed to create etc pvc for pod %v in lab %v, %w", nodeName, lab.Lab.Name, err)
 	}
@@ -111,12 +115,28 @@ func (gpod *GeneralPod) Ensure(ctx context.Context, nodeName string, clnt client
 			},
 		},
 	}
+	//restore files from snapshot, only files under /root are persisted
+	restoreCM, snapshot, err := lab.ensureRestoreCfgMap(ctx, clnt, nodeName, Pod)
+	if err != nil {
+		return err
+	}
+	if restoreCM != nil {
+		initContainer, restoreVol := getRestoreInitContainer(*gpod.Image, restoreCM, snapshot, "root", "/root", "/")
+		pod.Spec.InitContainers = append(pod.Spec.InitContainers, initContainer)
+		pod.Spec.Volumes = append(pod.Spec.Volumes, restoreVol)
+	}
 	//generate NAD if the connector address is specified
 	if linkSpokeMap, ok := lab.SpokeMap[nodeName]; ok {
 		for _, spokes := range linkSpokeMap {
 			for _, spokeName := range spokes {
 				if lab.SpokeConnectorMap[spokeName].Addrs != nil {
-					lanName := Getk8lanName(lab.Lab.Name, lab.SpokeLinkMap[spokeName])
+					if lab.isLocalSpoke(spokeName) {
+						if err = lab.ensureLocalSpokeAddrNAD(ctx, clnt, spokeName); err != nil {
+							return fmt.Errorf("failed to create general pod nad %v in lab %v, %w", nodeName, lab.Lab.Name, err)
+						}
+						continue
+					}
+					lanName := lab.getLANName(lab.SpokeLinkMap[spokeName])
 					prefixList := []netip.Prefix{}
 					for _, pstr := range lab.SpokeConnectorMap[spokeName].Addrs {
 						prefixList = append(prefixList, netip.MustParsePrefix(pstr))
@@ -145,20 +165,15 @@ func (gpod *GeneralPod) Ensure(ctx context.Context, nodeName string, clnt client
 		spokes := lab.SpokeMap[nodeName][linkName]
 		// for _, spokes := range lab.SpokeMap[nodeName] {
 		for _, spokeName := range spokes {
-			lanName := Getk8lanName(lab.Lab.Name, lab.SpokeLinkMap[spokeName])
-
-			nadName := k8slan.GetDefNADName(lanName, spokeName, true)
-			if lab.SpokeConnectorMap[spokeName].Addrs != nil {
-				nadName = k8slan.GetAddrNADName(lanName, spokeName)
-			}
+			nadName, resKey := lab.getPodSpokeNAD(spokeName, lab.SpokeConnectorMap[spokeName].Addrs != nil)
 			if lab.SpokeConnectorMap[spokeName].PortId == nil {
 				netStr += fmt.Sprintf("%v,", nadName)
 			} else {
 				netStr += fmt.Sprintf("%v@%v,", nadName, *lab.SpokeConnectorMap[spokeName].PortId)
 			}
-			resName := k8slan.GetDPResouceName(lanName, spokeName, true)
-			resKey := fmt.Sprintf("%v/%v", K8sLANResKeyPrefix, resName)
-			pod.Spec.Containers[0].Resources.Limits[corev1.ResourceName(resKey)] = resource.MustParse("1")
+			if resKey != "" {
+				pod.Spec.Containers[0].Resources.Limits[corev1.ResourceName(resKey)] = resource.MustParse("1")
+			}
 		}
 	}
 	if netStr != "" {
@@ -167,6 +182,7 @@ func (gpod *GeneralPod) Ensure(ctx context.Context, nodeName string, clnt client
 			MultusAnnoKey: netStr,
 		}
 	}
+	lab.setLocalAffinity(nodeName, &pod.ObjectMeta, &pod.Spec.Affinity)
 	//add resource request
 	pod.Spec.Containers[0].Resources.Requests = make(corev1.ResourceList)
 	if gpod.ReqCPU != nil {
@@ -183,14 +199,28 @@ func (gpod *GeneralPod) Ensure(ctx context.Context, nodeName string, clnt client
 
 }
 
-func (gpod *GeneralPod) getRootPVC(ns, nodeName, labName string, size resource.Quantity) *corev1.PersistentVolumeClaim {
-	gconf := GCONF.Get()
+// GetCfgSnapshotSource implements CfgSnapshotSystem interface, default path is /root
+func (gpod *GeneralPod) GetCfgSnapshotSource(labName, nodeName string, paths []string) (*CfgSnapshotSource, error) {
+	//only /root is persisted
+	plist, err := getCfgSnapshotPaths("/", "/root", paths, []string{"root"})
+	if err != nil {
+		return nil, err
+	}
+	return &CfgSnapshotSource{
+		PodName:   GetPodName(labName, nodeName),
+		Container: "main",
+		RootDir:   "/",
+		Paths:     plist,
+	}, nil
+}
+
+func (gpod *GeneralPod) getRootPVC(ns, nodeName, labName string, size resource.Quantity, storageClass *string) *corev1.PersistentVolumeClaim {
 	name := fmt.Sprintf("%v-%v-root", labName, nodeName)
 	return &corev1.PersistentVolumeClaim{
 		ObjectMeta: GetObjMeta(name, labName, ns, nodeName, Pod),
 		Spec: corev1.PersistentVolumeClaimSpec{
 			AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOncePod},
-			StorageClassName: GetPointerVal(*gconf.PVCStorageClass),
+			StorageClassName: GetPointerVal(*storageClass),
 			Resources: corev1.VolumeResourceRequirements{
 				Requests: map[corev1.ResourceName]resource.Quantity{
 					corev1.ResourceStorage: size,
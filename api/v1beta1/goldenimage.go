@@ -0,0 +1,112 @@
+package v1beta1
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	cdiv1 "kubevirt.io/containerized-data-importer-api/pkg/apis/core/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// GetGoldenImageName returns name of the GoldenImage for a CDI image URL
+func GetGoldenImageName(url string) string {
+	h := sha256.Sum256([]byte(url))
+	return "img-" + hex.EncodeToString(h[:8])
+}
+
+// GetGoldenPVCName returns name of the golden DataVolume/PVC of a GoldenImage, it is in MYNAMESPACE
+func GetGoldenPVCName(imgName string) string {
+	return "golden-" + imgName
+}
+
+// GetConfig returns KNLConfig of the golden image, with the storage class override
+func (img *GoldenImage) GetConfig() KNLConfigSpec {
+	r, _ := GCONF.Lookup(getConfigName(img.Spec.Config))
+	if !isStrNotSpecfied(img.Spec.StorageClass) {
+		r.PVCStorageClass = img.Spec.StorageClass
+	}
+	return r
+}
+
+// NewGoldenDV returns the DataVolume that imports the golden image
+func NewGoldenDV(img *GoldenImage) *cdiv1.DataVolume {
+	gconf := img.GetConfig()
+	r := NewDV(MYNAMESPACE, "", "", "", GetGoldenPVCName(img.Name), img.Spec.URL, gconf.PVCStorageClass, &img.Spec.Size)
+	delete(r.Labels, K8SLABELSETUPKEY)
+	//golden PVC is only read by clones of lab DVs, ReadWriteOncePod would block concurrent clones
+	r.Spec.PVC.AccessModes = []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce}
+	return r
+}
+
+// getDVImageURL returns the import URL of a DataVolume, return "" if it doesn't import from registry or HTTP
+func getDVImageURL(dv *cdiv1.DataVolume) string {
+	if dv.Spec.Source == nil {
+		return ""
+	}
+	if dv.Spec.Source.Registry != nil && dv.Spec.Source.Registry.URL != nil {
+		return *dv.Spec.Source.Registry.URL
+	}
+	if dv.Spec.Source.HTTP != nil {
+		return dv.Spec.Source.HTTP.URL
+	}
+	return ""
+}
+
+// setCachedImageSource changes source of dv to clone from the golden image if image cache is enabled,
+// the GoldenImage is created if it doesn't exist, dv imports directly until the golden image is ready
+func (lab *ParsedLab) setCachedImageSource(ctx context.Context, clnt client.Client, dv *cdiv1.DataVolume) error {
+	gconf := lab.Lab.GetConfig()
+	if !gconf.IsImageCacheEnabled() {
+		return nil
+	}
+	url := getDVImageURL(dv)
+	if url == "" || dv.Spec.PVC == nil {
+		return nil
+	}
+	size, ok := dv.Spec.PVC.Resources.Requests[corev1.ResourceStorage]
+	if !ok {
+		return nil
+	}
+	img := new(GoldenImage)
+	imgName := GetGoldenImageName(url)
+	dv.Labels[GoldenImageLabelKey] = imgName
+	err := clnt.Get(ctx, types.NamespacedName{Name: imgName}, img)
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return MakeErr(err)
+		}
+		img = &GoldenImage{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: imgName,
+				Labels: map[string]string{
+					K8SLABELAPPKey: K8SLABELAPPVAL,
+				},
+			},
+			Spec: GoldenImageSpec{
+				URL:          url,
+				Size:         size,
+				Config:       ReturnPointerVal(lab.Lab.ConfigName()),
+				StorageClass: gconf.PVCStorageClass,
+			},
+		}
+		if err = clnt.Create(ctx, img); err != nil && !apierrors.IsAlreadyExists(err) {
+			return MakeErr(err)
+		}
+		return nil
+	}
+	if img.Status.Phase != GoldenImagePhaseReady || img.Spec.Size.Cmp(size) > 0 {
+		return nil
+	}
+	dv.Spec.Source = &cdiv1.DataVolumeSource{
+		PVC: &cdiv1.DataVolumeSourcePVC{
+			Namespace: MYNAMESPACE,
+			Name:      GetGoldenPVCName(imgName),
+		},
+	}
+	return nil
+}
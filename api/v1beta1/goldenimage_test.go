@@ -0,0 +1,90 @@
+package v1beta1
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestGoldenImageCache(t *testing.T) {
+	conf := DefKNLConfig()
+	conf.ImageCache = &ImageCacheConfig{
+		Enabled: ReturnPointerVal(true),
+		GCAfter: &metav1.Duration{Duration: time.Hour},
+	}
+	conf.PVCStorageClass = ReturnPointerVal("fast")
+	GCONF.Set("cached", &conf, 1)
+	t.Cleanup(func() { GCONF.Remove("cached") })
+
+	sch := runtime.NewScheme()
+	if err := AddToScheme(sch); err != nil {
+		t.Fatal(err)
+	}
+	clnt := fake.NewClientBuilder().WithScheme(sch).WithStatusSubresource(&GoldenImage{}).Build()
+	ctx := context.Background()
+	url := "docker://registry.example.com/vm:1.0"
+	lab := &Lab{}
+	lab.Name = "lab1"
+	lab.Namespace = "ns1"
+	plab := ParseLab(lab, nil)
+
+	//image cache is disabled in the default config
+	dv := NewDV("ns1", "lab1", "vm1", VM, "lab1-vm1", url, nil, ReturnPointerVal(resource.MustParse("10Gi")))
+	if err := plab.setCachedImageSource(ctx, clnt, dv); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := dv.Labels[GoldenImageLabelKey]; ok || getDVImageURL(dv) != url {
+		t.Fatalf("dv is changed while image cache is disabled, %+v", dv)
+	}
+
+	//golden image is created with the lab's config and storage class, dv imports directly until it is ready
+	lab.Spec.Config = ReturnPointerVal("cached")
+	lab.Spec.PVCStorageClass = ReturnPointerVal("local")
+	if err := plab.setCachedImageSource(ctx, clnt, dv); err != nil {
+		t.Fatal(err)
+	}
+	imgName := GetGoldenImageName(url)
+	if dv.Labels[GoldenImageLabelKey] != imgName || getDVImageURL(dv) != url {
+		t.Fatalf("unexpected dv %+v", dv)
+	}
+	img := new(GoldenImage)
+	if err := clnt.Get(ctx, types.NamespacedName{Name: imgName}, img); err != nil {
+		t.Fatal(err)
+	}
+	if *img.Spec.Config != "cached" || *img.Spec.StorageClass != "local" || img.Spec.Size.Cmp(resource.MustParse("10Gi")) != 0 {
+		t.Fatalf("unexpected golden image %+v", img.Spec)
+	}
+	if img.GetConfig().GetImageCacheGCAfter() != time.Hour {
+		t.Fatalf("golden image doesn't use config of the lab")
+	}
+	if gdv := NewGoldenDV(img); gdv.Namespace != MYNAMESPACE || *gdv.Spec.PVC.StorageClassName != "local" ||
+		len(gdv.Spec.PVC.AccessModes) != 1 || gdv.Spec.PVC.AccessModes[0] != corev1.ReadWriteOnce {
+		t.Fatalf("unexpected golden dv %+v", gdv)
+	}
+
+	//golden image is ready, dv clones from it unless the disk is smaller than the golden PVC
+	img.Status.Phase = GoldenImagePhaseReady
+	if err := clnt.Status().Update(ctx, img); err != nil {
+		t.Fatal(err)
+	}
+	smallDV := NewDV("ns1", "lab1", "vm2", VM, "lab1-vm2", url, nil, ReturnPointerVal(resource.MustParse("5Gi")))
+	if err := plab.setCachedImageSource(ctx, clnt, smallDV); err != nil {
+		t.Fatal(err)
+	}
+	if getDVImageURL(smallDV) != url {
+		t.Fatalf("smaller disk should not clone from the golden image")
+	}
+	if err := plab.setCachedImageSource(ctx, clnt, dv); err != nil {
+		t.Fatal(err)
+	}
+	if dv.Spec.Source.PVC == nil || dv.Spec.Source.PVC.Namespace != MYNAMESPACE || dv.Spec.Source.PVC.Name != GetGoldenPVCName(imgName) {
+		t.Fatalf("dv doesn't clone from the golden image, %+v", dv.Spec.Source)
+	}
+}
@@ -0,0 +1,106 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// GoldenImageSpec specifies a VM disk image to be imported once into a golden PVC
+type GoldenImageSpec struct {
+	//kubevirt CDI supported URL, either HTTP (http://) or registry source (docker://)
+	URL string `json:"url"`
+	//size of the golden PVC, a lab disk smaller than this can't be cloned from the golden image
+	Size resource.Quantity `json:"size"`
+	//the golden image is never garbage collected if true
+	// +optional
+	// +nullable
+	Retain *bool `json:"retain,omitempty"`
+	//KNLConfig of the lab that created the golden image, it decides garbage collection, default is the default KNLConfig
+	// +optional
+	// +nullable
+	Config *string `json:"config,omitempty"`
+	//storage class of the golden PVC, default is storageClass of the KNLConfig
+	// +optional
+	// +nullable
+	StorageClass *string `json:"storageClass,omitempty"`
+}
+
+type GoldenImagePhase string
+
+const (
+	GoldenImagePhaseImporting GoldenImagePhase = "Importing"
+	GoldenImagePhaseReady     GoldenImagePhase = "Ready"
+	GoldenImagePhaseFailed    GoldenImagePhase = "Failed"
+)
+
+// GoldenImageStatus defines the observed state of GoldenImage.
+type GoldenImageStatus struct {
+	// +optional
+	Phase GoldenImagePhase `json:"phase,omitempty"`
+	//the golden PVC, in format of namespace/name
+	// +optional
+	PVC string `json:"pvc,omitempty"`
+	//number of lab disks cloned from the golden image
+	// +optional
+	RefCount int `json:"refCount,omitempty"`
+	//list of labs using the golden image, in format of namespace/name
+	// +optional
+	Users []string `json:"users,omitempty"`
+	//last time the golden image was found in use
+	// +optional
+	LastUsed *metav1.Time `json:"lastUsed,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:printcolumn:name="URL",type=string,JSONPath=`.spec.url`
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Refs",type=integer,JSONPath=`.status.refCount`
+
+// GoldenImage is the Schema for the goldenimages API,
+// it is created automatically for each VM disk image when image cache is enabled in KNLConfig
+type GoldenImage struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// metadata is a standard object metadata
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty,omitzero"`
+
+	// spec defines the desired state of GoldenImage
+	// +required
+	Spec GoldenImageSpec `json:"spec"`
+
+	// status defines the observed state of GoldenImage
+	// +optional
+	Status GoldenImageStatus `json:"status,omitempty,omitzero"`
+}
+
+// +kubebuilder:object:root=true
+
+// GoldenImageList contains a list of GoldenImage
+type GoldenImageList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []GoldenImage `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&GoldenImage{}, &GoldenImageList{})
+}
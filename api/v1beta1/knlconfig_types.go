@@ -18,11 +18,13 @@ package v1beta1
 
 import (
 	"fmt"
-	"net/netip"
 	"reflect"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/distribution/reference"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -39,9 +41,24 @@ type KNLConfigSpec struct {
 	//SFTPSever address, must have format as addr/hostname:port
 	// +optional
 	SFTPSever *string `json:"fileSvr,omitempty"`
-	//multicast address used by VxLAN tunnel between k8s workers
+	//multicast address used by VxLAN tunnel between k8s workers, its family (IPv4 or IPv6) is the family of the underlay;
+	//in unicast mode, an IPv6 group must be interface-local (ff01::/16), an IPv4 group must not be forwarded by the underlay
 	// +optional
 	VXLANGrpAddr *string `json:"vxlanGrp,omitempty"`
+	//multicast or unicast, default is multicast;
+	//in unicast mode, BUM traffic is replicated to address of VxLAN device of every other worker, for underlays without multicast
+	// +optional
+	// +kubebuilder:validation:Enum=multicast;unicast
+	VxLANMode *VxLANMode `json:"vxlanMode,omitempty"`
+	//container image of the per worker agent programming VxLAN flood lists in unicast mode, it must have ip and bridge commands
+	// +optional
+	VxLANAgentImage *string `json:"vxlanAgentImage,omitempty"`
+	//MTU of VxLAN devices of workers, default is 1500; MTU of a link can't exceed it minus VxLAN overhead (74)
+	// +optional
+	UnderlayMTU *int32 `json:"underlayMTU,omitempty"`
+	//host interfaces that external connectors could attach to links, key is the worker name, "*" applies to all workers
+	// +optional
+	ExternalIntfs map[string][]string `json:"externalInterfaces,omitempty"`
 	//default VxLAN device name, used if not specified in vxlanDevMap
 	// +optional
 	VXLANDefaultDev *string `json:"defaultVxlanDev,omitempty"`
@@ -51,6 +68,9 @@ type KNLConfigSpec struct {
 	//name of k8s storageclass used to create PVCs
 	// +optional
 	PVCStorageClass *string `json:"storageClass,omitempty"`
+	//name of CSI VolumeSnapshotClass used to snapshot disks, the cluster default is used if not specified
+	// +optional
+	VolumeSnapshotClass *string `json:"volumeSnapshotClass,omitempty"`
 	//CPM loader container image, used by vsim, vsri and magc
 	// +optional
 	SRCPMLoaderImage *string `json:"srCPMLoaderImage,omitempty"`
@@ -63,21 +83,76 @@ type KNLConfigSpec struct {
 	// defaultNode specifies default values for types of node
 	// +optional
 	DefaultNode *OneOfSystem `json:"defaultNode,omitempty"`
+	// consoleLog specifies the console logging of VM based nodes, like vsim, vsri, magc and vm
+	// +optional
+	ConsoleLog *ConsoleLogConfig `json:"consoleLog,omitempty"`
+	// imageCache specifies the golden image cache of VM disk images
+	// +optional
+	ImageCache *ImageCacheConfig `json:"imageCache,omitempty"`
 }
 
+// ConsoleLogConfig specifies how the console of VM based nodes are recorded,
+// the log files are stored on the file server under consolelogs/<namespace>/<lab>/
+type ConsoleLogConfig struct {
+	//record the console output of VM based nodes if true
+	// +optional
+	Enabled *bool `json:"enabled,omitempty"`
+	//the log file is rotated when its size exceeds maxSize
+	// +optional
+	MaxSize *resource.Quantity `json:"maxSize,omitempty"`
+	//number of rotated log files to keep per VM
+	// +optional
+	MaxBackups *int32 `json:"maxBackups,omitempty"`
+}
+
+const (
+	DefConsoleLogMaxSize    = "10Mi"
+	DefConsoleLogMaxBackups = 3
+)
+
+// ImageCacheConfig specifies the golden image cache,
+// when enabled, a VM disk image is imported once into a golden PVC via GoldenImage, and labs clone their disks from it
+type ImageCacheConfig struct {
+	//use golden image cache for VM disks if true
+	// +optional
+	Enabled *bool `json:"enabled,omitempty"`
+	//an unused golden image is removed after gcAfter, unless its retain is true
+	// +optional
+	GCAfter *metav1.Duration `json:"gcAfter,omitempty"`
+}
+
+const (
+	DefImageCacheGCAfter = 24 * time.Hour
+)
+
 // this is default knlconfig to use to fill any non-specified field,
 // this is the application default, meaning when user didn't specify the corresponding field in KNLconfig
 func DefKNLConfig() KNLConfigSpec {
 	r := KNLConfigSpec{
-		SFTPSever:      ReturnPointerVal("knl-sftp-service.knl-system.svc.cluster.local:22"),
-		VXLANGrpAddr:   ReturnPointerVal("ff18::100"),
-		SideCarHookImg: ReturnPointerVal("ghcr.io/hujun-open/knl/knlsidecar:latest"),
+		SFTPSever:       ReturnPointerVal("knl-sftp-service.knl-system.svc.cluster.local:22"),
+		VXLANGrpAddr:    ReturnPointerVal("ff18::100"),
+		VxLANMode:       ReturnPointerVal(VxLANModeMulticast),
+		VxLANAgentImage: ReturnPointerVal(DefaultVxLANAgentImage),
+		UnderlayMTU:     ReturnPointerVal(int32(DefaultUnderlayMTU)),
+		SideCarHookImg:  ReturnPointerVal("ghcr.io/hujun-open/knl/knlsidecar:latest"),
+		ConsoleLog: &ConsoleLogConfig{
+			Enabled:    ReturnPointerVal(false),
+			MaxSize:    ReturnPointerVal(resource.MustParse(DefConsoleLogMaxSize)),
+			MaxBackups: ReturnPointerVal(int32(DefConsoleLogMaxBackups)),
+		},
+		ImageCache: &ImageCacheConfig{
+			Enabled: ReturnPointerVal(false),
+			GCAfter: &metav1.Duration{Duration: DefImageCacheGCAfter},
+		},
 	}
 	//create app default for each node type
 	defOne := OneOfSystem{}
 	val := reflect.ValueOf(&defOne)
 	val = val.Elem()
 	for i := 0; i < val.NumField(); i++ {
+		if !isSystemField(val.Type().Field(i)) {
+			continue
+		}
 		newPointerVal := reflect.New(val.Field(i).Type().Elem())
 		newPointerVal.Interface().(System).SetToAppDefVal()
 		val.Field(i).Set(newPointerVal)
@@ -86,6 +161,59 @@ func DefKNLConfig() KNLConfigSpec {
 	return r
 }
 
+// ConditionConfigDrift is true if KNLConfig fields used by existing objects of a lab are changed
+const ConditionConfigDrift = "ConfigDrift"
+
+// DisruptiveValues returns values of fields that are built into objects created for a lab, like VMIs, PVCs and LANs;
+// change of these fields only takes effect after the lab is recreated; key is the json name of the field
+func (spec KNLConfigSpec) DisruptiveValues() map[string]string {
+	strVal := func(s *string) string {
+		if s == nil {
+			return ""
+		}
+		return *s
+	}
+	devList := []string{}
+	for _, worker := range GetSortedKeySlice(spec.VxDevMap) {
+		devList = append(devList, worker+"="+spec.VxDevMap[worker])
+	}
+	return map[string]string{
+		"fileSvr":          strVal(spec.SFTPSever),
+		"vxlanGrp":         strVal(spec.VXLANGrpAddr),
+		"defaultVxlanDev":  strVal(spec.VXLANDefaultDev),
+		"vxlanDevMap":      strings.Join(devList, ","),
+		"storageClass":     strVal(spec.PVCStorageClass),
+		"srCPMLoaderImage": strVal(spec.SRCPMLoaderImage),
+		"srIOMLoaderImage": strVal(spec.SRIOMLoaderImage),
+		"sideCarImage":     strVal(spec.SideCarHookImg),
+		"consoleLog":       strconv.FormatBool(spec.IsConsoleLogEnabled()),
+	}
+}
+
+// IsConsoleLogEnabled return true if console logging of VM based nodes is enabled
+func (spec KNLConfigSpec) IsConsoleLogEnabled() bool {
+	if spec.ConsoleLog == nil || spec.ConsoleLog.Enabled == nil {
+		return false
+	}
+	return *spec.ConsoleLog.Enabled
+}
+
+// IsImageCacheEnabled return true if golden image cache is enabled
+func (spec KNLConfigSpec) IsImageCacheEnabled() bool {
+	if spec.ImageCache == nil || spec.ImageCache.Enabled == nil {
+		return false
+	}
+	return *spec.ImageCache.Enabled
+}
+
+// GetImageCacheGCAfter returns how long an unused golden image is kept
+func (spec KNLConfigSpec) GetImageCacheGCAfter() time.Duration {
+	if spec.ImageCache == nil || spec.ImageCache.GCAfter == nil {
+		return DefImageCacheGCAfter
+	}
+	return spec.ImageCache.GCAfter.Duration
+}
+
 // KNLConfigStatus defines the observed state of KNLConfig.
 type KNLConfigStatus struct {
 	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
@@ -96,10 +224,17 @@ type KNLConfigStatus struct {
 
 	// +optional
 	ObservedGeneration *int64 `json:"observedGeneration"`
+	//number of labs using this config
+	// +optional
+	LabCount int `json:"labCount,omitempty"`
+	//list of labs using this config, in format of namespace/name
+	// +optional
+	Labs []string `json:"labs,omitempty"`
 }
 
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Labs",type=integer,JSONPath=`.status.labCount`
 
 // KNLConfig is the Schema for the configuration of KNL operator
 type KNLConfig struct {
@@ -133,6 +268,14 @@ func init() {
 
 // loadDef load non-specified fields of in with def, using DefaultNode in KNLConfigSpec
 func LoadDef(in *LabSpec, def KNLConfigSpec) error {
+	//link defaults
+	if def.UnderlayMTU != nil {
+		for _, link := range in.LinkList {
+			if link != nil && link.MTU == nil {
+				link.MTU = ReturnPointerVal(def.MaxLinkMTU())
+			}
+		}
+	}
 	//node defaults
 	defVal := reflect.ValueOf(def.DefaultNode).Elem()
 	var err error
@@ -170,11 +313,12 @@ func isStrNotSpecfied(str *string) bool {
 
 func (knlcfg *KNLConfig) Validate() error {
 
-	if addr, err := netip.ParseAddr(*knlcfg.Spec.VXLANGrpAddr); err != nil {
-		return fmt.Errorf("%v is not valid VxLAN Group IP addr", *knlcfg.Spec.VXLANGrpAddr)
-	} else {
-		if addr.Is4() || !addr.IsMulticast() {
-			return fmt.Errorf("VxLAN Group IP addr %v is not a IPv6 multicast address", *knlcfg.Spec.VXLANGrpAddr)
+	if err := knlcfg.Spec.validateVxLAN(); err != nil {
+		return err
+	}
+	if knlcfg.Spec.VxLANAgentImage != nil {
+		if _, err := reference.Parse(*knlcfg.Spec.VxLANAgentImage); err != nil {
+			return fmt.Errorf("%v is not valid container image url: %w", *knlcfg.Spec.VxLANAgentImage, err)
 		}
 	}
 	// if p, err := netip.ParsePrefix(*knlcfg.Spec.VXLANGrpPrefix); err != nil {
@@ -214,6 +358,20 @@ func (knlcfg *KNLConfig) Validate() error {
 		return fmt.Errorf("%v is not valid container image url: %w", *knlcfg.Spec.SideCarHookImg, err)
 	}
 
+	if knlcfg.Spec.ConsoleLog != nil {
+		if knlcfg.Spec.ConsoleLog.MaxSize != nil && knlcfg.Spec.ConsoleLog.MaxSize.Sign() <= 0 {
+			return fmt.Errorf("console log maxSize must be greater than 0")
+		}
+		if knlcfg.Spec.ConsoleLog.MaxBackups != nil && *knlcfg.Spec.ConsoleLog.MaxBackups < 0 {
+			return fmt.Errorf("console log maxBackups can't be negative")
+		}
+	}
+	if knlcfg.Spec.ImageCache != nil && knlcfg.Spec.ImageCache.GCAfter != nil {
+		if knlcfg.Spec.ImageCache.GCAfter.Duration < 0 {
+			return fmt.Errorf("image cache gcAfter can't be negative")
+		}
+	}
+
 	if knlcfg.Spec.SFTPSever != nil {
 		if !IsHostPort(*knlcfg.Spec.SFTPSever) {
 			return fmt.Errorf("%v must be in format as addr/host:port", *knlcfg.Spec.SFTPSever)
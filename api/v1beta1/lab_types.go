@@ -40,6 +40,56 @@ type LabSpec struct {
 	// +optional
 	// +nullable
 	LinkList map[string]*Link `json:"links"`
+	// restoreFrom is the name of a completed LabSnapshot in the same namespace,
+	// configuration of a node in the snapshot is used as its startup configuration on first boot,
+	// disks are created from the VolumeSnapshots if the snapshot includes disks;
+	// nodes are matched by name
+	// +optional
+	// +nullable
+	RestoreFrom *string `json:"restoreFrom,omitempty"`
+	// nodeGroups generates similar nodes and their links, key is the group name;
+	// they are expanded into nodes and links
+	// +optional
+	// +nullable
+	NodeGroups map[string]*NodeGroup `json:"nodeGroups,omitempty"`
+	// generator generates nodes and links of a common topology shape,
+	// they are merged with nodes and links on creation
+	// +optional
+	// +nullable
+	Generator *TopologyGenerator `json:"generator,omitempty"`
+	// template refers to a LabTemplate in the same namespace, the rendered spec is merged into this spec on creation,
+	// nodes and links specified here take precedence; values can't be changed afterwards like the rest of the spec;
+	// later changes of the LabTemplate are reported via condition TemplateOutdated, not applied
+	// +optional
+	// +nullable
+	Template *LabTemplateRef `json:"template,omitempty"`
+	// config is name of the KNLConfig in operator's namespace used by this lab,
+	// if not specified, it is set from label config.kubenetlab.net/name of the lab namespace on creation;
+	// the default KNLConfig "knlcfg" is used if neither is specified
+	// +optional
+	// +nullable
+	Config *string `json:"config,omitempty"`
+	// defaults specifies default values for types of node in this lab, same as defaultNode of KNLConfig;
+	// they take precedence over defaultNode of KNLConfig
+	// +optional
+	// +nullable
+	Defaults *OneOfSystem `json:"defaults,omitempty"`
+	// storageClass overrides storageClass of KNLConfig for PVCs of this lab
+	// +optional
+	// +nullable
+	PVCStorageClass *string `json:"storageClass,omitempty"`
+	// srCPMLoaderImage overrides srCPMLoaderImage of KNLConfig for this lab
+	// +optional
+	// +nullable
+	SRCPMLoaderImage *string `json:"srCPMLoaderImage,omitempty"`
+	// srIOMLoaderImage overrides srIOMLoaderImage of KNLConfig for this lab
+	// +optional
+	// +nullable
+	SRIOMLoaderImage *string `json:"srIOMLoaderImage,omitempty"`
+	// sideCarImage overrides sideCarImage of KNLConfig for this lab
+	// +optional
+	// +nullable
+	SideCarHookImg *string `json:"sideCarImage,omitempty"`
 }
 
 // LabStatus defines the observed state of Lab.
@@ -63,6 +113,23 @@ type LabStatus struct {
 	// +listMapKey=type
 	// +optional
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// consoleLogs lists the console log file on the file server for each recorded VM, key is the VMI name
+	// +optional
+	ConsoleLogs map[string]string `json:"consoleLogs,omitempty"`
+	// configGeneration is the generation of KNLConfig the lab is last reconciled with
+	// +optional
+	ConfigGeneration *int64 `json:"configGeneration,omitempty"`
+	// appliedConfig records values of KNLConfig fields built into objects of the lab when they are created,
+	// key is the field name; condition ConfigDrift is set if they differ from current KNLConfig
+	// +optional
+	AppliedConfig map[string]string `json:"appliedConfig,omitempty"`
+	// coLocatedLinks lists links whose nodes all run on the same worker but still go through VxLAN,
+	// they could be marked local
+	// +optional
+	CoLocatedLinks []string `json:"coLocatedLinks,omitempty"`
+	// crossLabLinks lists lab-to-lab links between this lab and other labs, including ones only declared by the peer lab
+	// +optional
+	CrossLabLinks []CrossLabLink `json:"crossLabLinks,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -128,3 +195,35 @@ func (labspec *LabSpec) GetNodeSortIndex(nodeName string) int {
 	}
 	return -1
 }
+
+// ConfigName returns name of the KNLConfig used by the lab
+func (lab *Lab) ConfigName() string {
+	return getConfigName(lab.Spec.Config)
+}
+
+// getConfigName returns name of the KNLConfig referred by name, the default config is used if name is not specified
+func getConfigName(name *string) string {
+	if isStrNotSpecfied(name) {
+		return DefKNLConfigName
+	}
+	return *name
+}
+
+// GetConfig returns KNLConfig used by the lab, with the lab level overrides;
+// the default config is used if the lab's config is not found
+func (lab *Lab) GetConfig() KNLConfigSpec {
+	r, _ := GCONF.Lookup(lab.ConfigName())
+	if !isStrNotSpecfied(lab.Spec.PVCStorageClass) {
+		r.PVCStorageClass = lab.Spec.PVCStorageClass
+	}
+	if !isStrNotSpecfied(lab.Spec.SRCPMLoaderImage) {
+		r.SRCPMLoaderImage = lab.Spec.SRCPMLoaderImage
+	}
+	if !isStrNotSpecfied(lab.Spec.SRIOMLoaderImage) {
+		r.SRIOMLoaderImage = lab.Spec.SRIOMLoaderImage
+	}
+	if !isStrNotSpecfied(lab.Spec.SideCarHookImg) {
+		r.SideCarHookImg = lab.Spec.SideCarHookImg
+	}
+	return r
+}
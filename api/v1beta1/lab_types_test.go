@@ -0,0 +1,97 @@
+package v1beta1
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestLabDefaults(t *testing.T) {
+	spec := &LabSpec{
+		NodeList: map[string]*OneOfSystem{
+			"frr-1": {FRR: &FRR{}},
+			"frr-2": {FRR: &FRR{Image: ReturnPointerVal("frr:node")}},
+		},
+		Defaults: &OneOfSystem{FRR: &FRR{Image: ReturnPointerVal("frr:lab")}},
+	}
+	//lab defaults are loaded before KNLConfig defaults, same as the webhook
+	if err := LoadDef(spec, KNLConfigSpec{DefaultNode: spec.Defaults}); err != nil {
+		t.Fatal(err)
+	}
+	gconf := KNLConfigSpec{DefaultNode: &OneOfSystem{FRR: &FRR{
+		Image:     ReturnPointerVal("frr:global"),
+		ReqMemory: ReturnPointerVal(resource.MustParse("1Gi")),
+	}}}
+	if err := LoadDef(spec, gconf); err != nil {
+		t.Fatal(err)
+	}
+	if *spec.NodeList["frr-1"].FRR.Image != "frr:lab" || *spec.NodeList["frr-2"].FRR.Image != "frr:node" {
+		t.Fatalf("unexpected node image, %v, %v", *spec.NodeList["frr-1"].FRR.Image, *spec.NodeList["frr-2"].FRR.Image)
+	}
+	if spec.NodeList["frr-1"].FRR.ReqMemory == nil {
+		t.Fatalf("KNLConfig default is not loaded")
+	}
+}
+
+func TestLabGetConfig(t *testing.T) {
+	lab := &Lab{}
+	gconf := GCONF.Get()
+	if lab.GetConfig().PVCStorageClass != gconf.PVCStorageClass {
+		t.Fatalf("storage class should come from KNLConfig")
+	}
+	lab.Spec.PVCStorageClass = ReturnPointerVal("fast")
+	lab.Spec.SideCarHookImg = ReturnPointerVal("example.com/sidecar:1")
+	conf := lab.GetConfig()
+	if *conf.PVCStorageClass != "fast" || *conf.SideCarHookImg != "example.com/sidecar:1" {
+		t.Fatalf("lab overrides are not used, %+v", conf)
+	}
+	if *GCONF.Get().SideCarHookImg == "example.com/sidecar:1" {
+		t.Fatalf("global config is changed")
+	}
+}
+
+func TestConfigLookup(t *testing.T) {
+	cfg := newConfig()
+	if _, ok := cfg.Lookup(DefKNLConfigName); !ok {
+		t.Fatalf("default config not found")
+	}
+	fast := DefKNLConfig()
+	fast.PVCStorageClass = ReturnPointerVal("fast")
+	if !cfg.Set("fast", &fast, 1) || cfg.Set("fast", &fast, 1) {
+		t.Fatalf("unexpected changed result of Set")
+	}
+	if conf, ok := cfg.Lookup("fast"); !ok || *conf.PVCStorageClass != "fast" {
+		t.Fatalf("config fast not found")
+	}
+	if _, ok := cfg.Lookup("slow"); ok {
+		t.Fatalf("config slow should not be found")
+	}
+	cfg.Remove("fast")
+	cfg.Remove(DefKNLConfigName)
+	if names := cfg.Names(); len(names) != 1 || names[0] != DefKNLConfigName {
+		t.Fatalf("unexpected config names %v", names)
+	}
+	lab := &Lab{Spec: LabSpec{Config: ReturnPointerVal("fast")}}
+	if lab.ConfigName() != "fast" || (&Lab{}).ConfigName() != DefKNLConfigName {
+		t.Fatalf("unexpected config name of lab")
+	}
+}
+
+func TestDisruptiveValues(t *testing.T) {
+	conf := DefKNLConfig()
+	conf.VxDevMap = map[string]string{"worker-2": "eth2", "worker-1": "eth1"}
+	vals := conf.DisruptiveValues()
+	if vals["vxlanDevMap"] != "worker-1=eth1,worker-2=eth2" || vals["consoleLog"] != "false" {
+		t.Fatalf("unexpected values %v", vals)
+	}
+	conf.ConsoleLog.MaxBackups = ReturnPointerVal(int32(10))
+	conf.DefaultNode = nil
+	if newVals := conf.DisruptiveValues(); !reflect.DeepEqual(vals, newVals) {
+		t.Fatalf("non-disruptive change changes values, %v", newVals)
+	}
+	conf.SideCarHookImg = ReturnPointerVal("example.com/sidecar:2")
+	if conf.DisruptiveValues()["sideCarImage"] == vals["sideCarImage"] {
+		t.Fatalf("sidecar image change is not detected")
+	}
+}
@@ -0,0 +1,124 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// LabSnapshotSpec specifies which lab and nodes to collect configuration from,
+// the snapshot is taken once, the spec can't be changed afterwards
+type LabSnapshotSpec struct {
+	//name of the lab to take the snapshot, the lab must be in the same namespace
+	Lab string `json:"lab"`
+	//list of nodes to collect configuration, all nodes of the lab if not specified
+	// +optional
+	Nodes []string `json:"nodes,omitempty"`
+	//paths to collect for container based nodes, key is the node name, value is a list of absolute path in the pod;
+	//they must be under the folder the node type persists and restores, e.g. /root for pod, /mnt/flash for ceos
+	//and /xr-storage for xrd where they are required; default is the node's configuration.
+	//vm, vjunos, dummy and trafficgen nodes don't support config snapshot, use disk instead for VM based nodes;
+	//SR OS VMs always collect their config folder on the file server
+	// +optional
+	Paths map[string][]string `json:"paths,omitempty"`
+	//take CSI VolumeSnapshot of all disks of the nodes if true, like VM disks and PVCs of pod based nodes;
+	//the snapshot is crash consistent, the storage class must support VolumeSnapshot
+	// +optional
+	// +nullable
+	Disk *bool `json:"disk,omitempty"`
+}
+
+type SnapshotPhase string
+
+const (
+	SnapshotPhaseInProgress SnapshotPhase = "InProgress"
+	//the archive is written, waiting for VolumeSnapshots of disks
+	SnapshotPhaseArchived  SnapshotPhase = "Archived"
+	SnapshotPhaseCompleted SnapshotPhase = "Completed"
+	SnapshotPhaseFailed    SnapshotPhase = "Failed"
+)
+
+// NodeSnapshotStatus is the collect result of a single node
+type NodeSnapshotStatus struct {
+	//number of files collected
+	// +optional
+	Files int `json:"files,omitempty"`
+	//error message if failed to collect the node
+	// +optional
+	Error string `json:"error,omitempty"`
+}
+
+// LabSnapshotStatus defines the observed state of LabSnapshot.
+type LabSnapshotStatus struct {
+	// +optional
+	Phase SnapshotPhase `json:"phase,omitempty"`
+	//version of the snapshot, increased for each snapshot of the same lab
+	// +optional
+	Version int `json:"version,omitempty"`
+	//path of the archive on the file server
+	// +optional
+	Archive string `json:"archive,omitempty"`
+	//collect result of each node, key is node name
+	// +optional
+	Nodes map[string]NodeSnapshotStatus `json:"nodes,omitempty"`
+	//VolumeSnapshot of each disk, key is the PVC name without lab name prefix, value is the VolumeSnapshot name
+	// +optional
+	Volumes map[string]string `json:"volumes,omitempty"`
+	// +optional
+	Message string `json:"message,omitempty"`
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Lab",type=string,JSONPath=`.spec.lab`
+// +kubebuilder:printcolumn:name="Version",type=integer,JSONPath=`.status.version`
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+
+// LabSnapshot is the Schema for the labsnapshots API,
+// it collects configuration of nodes of a lab into an archive on the file server,
+// and optionally VolumeSnapshots of their disks;
+// a lab could restore from it via restoreFrom
+type LabSnapshot struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// metadata is a standard object metadata
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty,omitzero"`
+
+	// spec defines the desired state of LabSnapshot
+	// +required
+	Spec LabSnapshotSpec `json:"spec"`
+
+	// status defines the observed state of LabSnapshot
+	// +optional
+	Status LabSnapshotStatus `json:"status,omitempty,omitzero"`
+}
+
+// +kubebuilder:object:root=true
+
+// LabSnapshotList contains a list of LabSnapshot
+type LabSnapshotList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []LabSnapshot `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&LabSnapshot{}, &LabSnapshotList{})
+}
@@ -0,0 +1,129 @@
+package v1beta1
+
+import (
+	"bytes"
+	"fmt"
+	"text/template"
+
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	//ConditionTemplateOutdated is true if the LabTemplate is changed after the lab is rendered
+	ConditionTemplateOutdated = "TemplateOutdated"
+)
+
+// LabTemplateRef refers to a LabTemplate in the same namespace
+type LabTemplateRef struct {
+	//+required
+	//name of the LabTemplate
+	Name string `json:"name"`
+	//parameter values, key is the parameter name
+	// +optional
+	Values map[string]string `json:"values,omitempty"`
+}
+
+// Render returns the LabSpec rendered with values, parameters not in values use their defaults
+func (spec *LabTemplateSpec) Render(values map[string]string) (*LabSpec, error) {
+	data := make(map[string]string)
+	for _, p := range spec.Parameters {
+		if v, ok := values[p.Name]; ok {
+			data[p.Name] = v
+		} else if p.Default != nil {
+			data[p.Name] = *p.Default
+		} else {
+			return nil, fmt.Errorf("parameter %v requires a value", p.Name)
+		}
+	}
+	for k := range values {
+		if _, ok := data[k]; !ok {
+			return nil, fmt.Errorf("%v is not a parameter of the template", k)
+		}
+	}
+	tmpl, err := template.New("lab").Option("missingkey=error").Parse(spec.Template)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template, %w", err)
+	}
+	buf := new(bytes.Buffer)
+	if err = tmpl.Execute(buf, data); err != nil {
+		return nil, fmt.Errorf("failed to render template, %w", err)
+	}
+	r := new(LabSpec)
+	if err = yaml.UnmarshalStrict(buf.Bytes(), r); err != nil {
+		return nil, fmt.Errorf("rendered template is not a valid lab spec, %w", err)
+	}
+	if r.Template != nil {
+		return nil, fmt.Errorf("rendered template can't refer to another template")
+	}
+	return r, nil
+}
+
+// MergeFrom merges rendered into spec, nodes, links and lab level fields in spec take precedence,
+// unspecified fields of a node are filled from the rendered node of same type, so are defaults
+func (spec *LabSpec) MergeFrom(rendered *LabSpec) error {
+	if spec.NodeList == nil {
+		spec.NodeList = make(map[string]*OneOfSystem)
+	}
+	if spec.LinkList == nil {
+		spec.LinkList = make(map[string]*Link)
+	}
+	for nodeName, rnode := range rendered.NodeList {
+		node, ok := spec.NodeList[nodeName]
+		if !ok || node == nil {
+			spec.NodeList[nodeName] = rnode
+			continue
+		}
+		sys, sysName := node.GetSystem()
+		rsys, rsysName := rnode.GetSystem()
+		if sys == nil {
+			spec.NodeList[nodeName] = rnode
+			continue
+		}
+		if rsys == nil || sysName != rsysName {
+			continue
+		}
+		if err := FillNilPointers(sys, rsys); err != nil {
+			return fmt.Errorf("failed to merge node %v, %w", nodeName, err)
+		}
+	}
+	for linkName, link := range rendered.LinkList {
+		if _, ok := spec.LinkList[linkName]; !ok {
+			spec.LinkList[linkName] = link
+		}
+	}
+	if spec.NodeGroups == nil {
+		spec.NodeGroups = rendered.NodeGroups
+	} else {
+		for groupName, g := range rendered.NodeGroups {
+			if _, ok := spec.NodeGroups[groupName]; !ok {
+				spec.NodeGroups[groupName] = g
+			}
+		}
+	}
+	if spec.Generator == nil {
+		spec.Generator = rendered.Generator
+	}
+	if spec.RestoreFrom == nil {
+		spec.RestoreFrom = rendered.RestoreFrom
+	}
+	//unspecified defaults of a node type are filled from rendered defaults
+	if spec.Defaults == nil {
+		spec.Defaults = rendered.Defaults
+	} else if rendered.Defaults != nil {
+		if err := FillNilPointers(spec.Defaults, rendered.Defaults); err != nil {
+			return fmt.Errorf("failed to merge defaults, %w", err)
+		}
+	}
+	for _, f := range []struct{ dst, src **string }{
+		{&spec.Config, &rendered.Config},
+		{&spec.PVCStorageClass, &rendered.PVCStorageClass},
+		{&spec.SRCPMLoaderImage, &rendered.SRCPMLoaderImage},
+		{&spec.SRIOMLoaderImage, &rendered.SRIOMLoaderImage},
+		{&spec.SideCarHookImg, &rendered.SideCarHookImg},
+	} {
+		if *f.dst == nil {
+			*f.dst = *f.src
+		}
+	}
+	return nil
+}
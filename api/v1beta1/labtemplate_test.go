@@ -0,0 +1,94 @@
+package v1beta1
+
+import (
+	"testing"
+)
+
+func TestLabTemplateRender(t *testing.T) {
+	tmpl := &LabTemplateSpec{
+		Parameters: []LabTemplateParameter{
+			{Name: "frrImage", Default: ReturnPointerVal("quay.io/frrouting/frr:10.2.1")},
+			{Name: "pool"},
+		},
+		Template: `nodes:
+  frr-1:
+    frr:
+      image: {{ .frrImage }}
+      memory: 512Mi
+  frr-2:
+    frr:
+      image: {{ .frrImage }}
+links:
+  link1:
+    nodes:
+    - node: frr-1
+      addrs: ["{{ .pool }}"]
+    - node: frr-2
+defaults:
+  frr:
+    image: {{ .frrImage }}
+    memory: 256Mi
+  srl:
+    image: ghcr.io/nokia/srlinux:24.10
+config: knlcfg-fast
+storageClass: fast
+srCPMLoaderImage: cpm-loader:1
+srIOMLoaderImage: iom-loader:1
+sideCarImage: sidecar:1
+`,
+	}
+	if _, err := tmpl.Render(nil); err == nil {
+		t.Fatalf("expect error for missing required parameter")
+	}
+	if _, err := tmpl.Render(map[string]string{"pool": "10.0.0.1/24", "foo": "bar"}); err == nil {
+		t.Fatalf("expect error for unknown parameter")
+	}
+	rendered, err := tmpl.Render(map[string]string{"pool": "10.0.0.1/24"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *rendered.NodeList["frr-2"].FRR.Image != "quay.io/frrouting/frr:10.2.1" || rendered.NodeList["frr-1"].FRR.ReqMemory.String() != "512Mi" {
+		t.Fatalf("unexpected rendered nodes %+v", rendered.NodeList)
+	}
+	if rendered.LinkList["link1"].Connectors[0].Addrs[0] != "10.0.0.1/24" {
+		t.Fatalf("unexpected rendered links %+v", rendered.LinkList)
+	}
+	spec := &LabSpec{
+		NodeList: map[string]*OneOfSystem{
+			"frr-1": {FRR: &FRR{Image: ReturnPointerVal("my-frr")}},
+		},
+		Defaults:         &OneOfSystem{FRR: &FRR{Image: ReturnPointerVal("my-frr")}},
+		SRIOMLoaderImage: ReturnPointerVal("my-iom-loader"),
+	}
+	if err = spec.MergeFrom(rendered); err != nil {
+		t.Fatal(err)
+	}
+	if frr := spec.NodeList["frr-1"].FRR; *frr.Image != "my-frr" || frr.ReqMemory.String() != "512Mi" {
+		t.Fatalf("unexpected merged node %+v", frr)
+	}
+	if len(spec.NodeList) != 2 || len(spec.LinkList) != 1 {
+		t.Fatalf("unexpected merged spec %+v", spec)
+	}
+	//lab level fields, values in spec take precedence
+	if d := spec.Defaults; *d.FRR.Image != "my-frr" || d.FRR.ReqMemory.String() != "256Mi" || *d.SRL.Image != "ghcr.io/nokia/srlinux:24.10" {
+		t.Fatalf("unexpected merged defaults %+v", spec.Defaults)
+	}
+	for _, c := range []struct {
+		val    *string
+		expect string
+	}{
+		{spec.Config, "knlcfg-fast"},
+		{spec.PVCStorageClass, "fast"},
+		{spec.SRCPMLoaderImage, "cpm-loader:1"},
+		{spec.SRIOMLoaderImage, "my-iom-loader"},
+		{spec.SideCarHookImg, "sidecar:1"},
+	} {
+		if c.val == nil || *c.val != c.expect {
+			t.Fatalf("expect %v, got %v", c.expect, c.val)
+		}
+	}
+	tmpl.Template = "nodes: {{ .pool "
+	if _, err = tmpl.Render(map[string]string{"pool": "x"}); err == nil {
+		t.Fatalf("expect error for invalid template")
+	}
+}
@@ -0,0 +1,72 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// LabTemplateParameter is a parameter of a LabTemplate
+type LabTemplateParameter struct {
+	//name of the parameter, referred in the template as {{ .<name> }}
+	Name string `json:"name"`
+	//default value, the parameter is required if not specified
+	// +optional
+	// +nullable
+	Default *string `json:"default,omitempty"`
+	// +optional
+	Description string `json:"description,omitempty"`
+}
+
+// LabTemplateSpec is a parameterised LabSpec
+type LabTemplateSpec struct {
+	// +optional
+	Parameters []LabTemplateParameter `json:"parameters,omitempty"`
+	//+required
+	//a LabSpec in YAML, rendered as Go text/template with parameter values, e.g. "image: {{ .srlImage }}"
+	Template string `json:"template"`
+}
+
+// +kubebuilder:object:root=true
+
+// LabTemplate is the Schema for the labtemplates API,
+// a lab in the same namespace could refer to it via spec.template, the lab spec is rendered by the webhook;
+// change of the template is reported in the condition of derived labs, it is not applied to them
+type LabTemplate struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// metadata is a standard object metadata
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty,omitzero"`
+
+	// spec defines the desired state of LabTemplate
+	// +required
+	Spec LabTemplateSpec `json:"spec"`
+}
+
+// +kubebuilder:object:root=true
+
+// LabTemplateList contains a list of LabTemplate
+type LabTemplateList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []LabTemplate `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&LabTemplate{}, &LabTemplateList{})
+}
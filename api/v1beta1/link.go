@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net"
 	"net/netip"
+	"slices"
 	"strconv"
 	"strings"
 
@@ -24,17 +25,135 @@ type Link struct {
 	//+required
 	//a list of nodes connect to the link's layer2 network
 	Connectors []Connector `json:"nodes"`
+	//if true, the link is wired by a local bridge on the worker instead of VxLAN,
+	//and all nodes connected by local links are scheduled on the same worker
+	// +optional
+	// +nullable
+	Local *bool `json:"local,omitempty"`
+	//MTU of the link, default is underlay MTU of KNLConfig minus VxLAN overhead;
+	//it can't exceed that unless the link is local; a smaller MTU on a non-local link is not applied to
+	//interfaces of NOS pods or VMs without addresses, the lab webhook warns about it
+	// +optional
+	// +nullable
+	MTU *int32 `json:"mtu,omitempty"`
+	//if specified, the link is a trunk carrying each listed link tagged with its VLAN ID,
+	//connectors of the trunk link get tagged traffic, VLAN sub-interfaces are configured on the nodes
+	// +optional
+	VLANs []TrunkVLAN `json:"vlans,omitempty"`
+}
+
+// MinLinkMTU is the minimal MTU of a link
+const MinLinkMTU = 68
+
+// ValidateMTU checks MTU of links could be carried by the VxLAN underlay of conf
+func (spec *LabSpec) ValidateMTU(conf KNLConfigSpec) error {
+	for _, linkName := range GetSortedKeySlice(spec.LinkList) {
+		link := spec.LinkList[linkName]
+		if link == nil || link.MTU == nil || link.IsLocal() {
+			continue
+		}
+		if *link.MTU > conf.MaxLinkMTU() {
+			return fmt.Errorf("MTU %d of link %v exceeds %d, the maximum the VxLAN underlay could carry", *link.MTU, linkName, conf.MaxLinkMTU())
+		}
+	}
+	return nil
+}
+
+// MTUWarnings returns a warning for each VxLAN link whose MTU is smaller than what the VxLAN underlay of conf gives,
+// listing nodes the MTU can't be applied to; their interfaces get the MTU of the k8slan LAN instead
+func (spec *LabSpec) MTUWarnings(conf KNLConfigSpec) []string {
+	var r []string
+	for _, linkName := range GetSortedKeySlice(spec.LinkList) {
+		link := spec.LinkList[linkName]
+		if link == nil || link.MTU == nil || link.IsLocal() || *link.MTU >= conf.MaxLinkMTU() {
+			continue
+		}
+		var nodes []string
+		for _, c := range link.Connectors {
+			if c.IsExternal() || c.IsCrossLab() || c.NodeName == nil {
+				continue
+			}
+			node := spec.NodeList[*c.NodeName]
+			if node == nil {
+				continue
+			}
+			sys, _ := node.GetSystem()
+			if !appliesLinkMTU(sys, &c) && !slices.Contains(nodes, *c.NodeName) {
+				nodes = append(nodes, *c.NodeName)
+			}
+		}
+		if len(nodes) > 0 {
+			r = append(r, fmt.Sprintf("link %v: MTU %d is not applied to interfaces of %v, they get %d from the VxLAN underlay",
+				linkName, *link.MTU, strings.Join(nodes, ","), conf.MaxLinkMTU()))
+		}
+	}
+	return r
+}
+
+// appliesLinkMTU returns true if the node configures link MTU on its interface of connector c
+func appliesLinkMTU(sys System, c *Connector) bool {
+	switch sys.(type) {
+	case *Dummy, *TrafficGen:
+		return true
+	case *GeneralVM:
+		//MTU is set in the network config along with the addresses
+		return len(c.Addrs) > 0
+	}
+	return false
 }
 
 func (link *Link) Validate() error {
-	if len(link.Connectors) < 2 {
+	return link.validate(0)
+}
+
+// validate checks the link, trunks is the number of trunk links carrying it,
+// trunk bridge of each carrying trunk link and of the link itself if it is a trunk counts as a node
+func (link *Link) validate(trunks int) error {
+	if link.IsTrunk() {
+		trunks++
+	}
+	if len(link.Connectors) < 1 || len(link.Connectors)+trunks < 2 {
 		return fmt.Errorf("the minimal number of nodes per link is 2")
 	}
+	if link.IsTrunk() {
+		if err := link.validateTrunk(); err != nil {
+			return err
+		}
+	}
+	if link.IsCrossLab() {
+		if err := link.validateCrossLab(); err != nil {
+			return err
+		}
+	}
+	if link.MTU != nil {
+		if *link.MTU < MinLinkMTU {
+			return fmt.Errorf("MTU %d is smaller than %d", *link.MTU, MinLinkMTU)
+		}
+		if link.IsLocal() && *link.MTU > MaxLocalLinkMTU {
+			return fmt.Errorf("MTU %d of local link exceeds %d", *link.MTU, MaxLocalLinkMTU)
+		}
+	}
 
 	for i, c := range link.Connectors {
 		if *c.NodeName == "" {
 			return fmt.Errorf("connector %d node name can't be empty", i)
 		}
+		if c.IsExternal() {
+			if c.External == nil {
+				return fmt.Errorf("external connector %v doesn't specify the host interface", *c.NodeName)
+			}
+			if err := c.External.validate(); err != nil {
+				return fmt.Errorf("external connector %v is invalid, %w", *c.NodeName, err)
+			}
+			if link.IsLocal() {
+				return fmt.Errorf("external connector %v can't be in a local link", *c.NodeName)
+			}
+		} else if c.External != nil {
+			return fmt.Errorf("connector %v is not external, but specifies the host interface", *c.NodeName)
+		}
+		if !c.IsCrossLab() && c.PeerLab != nil {
+			return fmt.Errorf("connector %v is not a lab connector, but specifies the peer lab", *c.NodeName)
+		}
 		// if c.Addr != nil {
 		// 	prefix, err := netip.ParsePrefix(*c.Addr)
 		// 	if !prefix.IsValid() || prefix.Addr().IsMulticast() || err != nil {
@@ -68,13 +187,27 @@ func (link *Link) Validate() error {
 // Connector specifies a node name and how it connects to the link
 type Connector struct {
 	//+required
-	//name of node connects to the link
+	//name of node connects to the link; for external connector, it is the name of the attachment, unique in the lab
 	NodeName *string `json:"node"` //node name
-	//used by srsim for mda port id, by SRVM for IOM slot id and by SRL for interface id
+	//node, external or lab, default is node;
+	//a lab connector is node of another lab specified by peerLab, it makes a lab-to-lab link
+	// +optional
+	// +nullable
+	// +kubebuilder:validation:Enum=node;external;lab
+	Kind *ConnectorKind `json:"kind,omitempty"`
+	//the lab of a lab connector
+	// +optional
+	// +nullable
+	PeerLab *PeerLab `json:"peerLab,omitempty"`
+	//the host interface attached to the link, required by external connector
+	// +optional
+	// +nullable
+	External *ExternalConnector `json:"external,omitempty"`
+	//used by srsim for mda port id, by SRVM for IOM slot id, by SRL for interface id, by frr, crpd and trafficgen for interface name, by ceos for ethN, by vjunos for ge-0/0/N, by xrd for Gi0/0/0/N by sonic for EthernetN and by dummy for interface name if replayPortId is true
 	PortId *string `json:"port,omitempty"`
-	//a list of IP prefix in format `xxxx/yy`, use by node type pod and vm
+	//a list of IP prefix in format `xxxx/yy`, use by node type pod, vm, dummy and trafficgen, rejected by frr
 	Addrs []string `json:"addrs,omitempty"`
-	//a list of static routes in format `<prefix> via <nexthop>`, use by node type pod and vm
+	//a list of static routes in format `<prefix> via <nexthop>`, use by node type pod, vm, dummy and trafficgen, rejected by frr
 	Routes []string `json:"routes,omitempty"`
 	//interface MAC address of the connecting node, used by node type vm
 	Mac *string `json:"mac,omitempty"`
@@ -203,7 +336,7 @@ func getSpokeName(vni int32, connectorIndex int) string {
 // return two maps, first map: 1st key is nodename, 2nd key is link name, val is list of spoke name
 // 2nd map: key is spokename, value is corrsponding connector
 func (plab *ParsedLab) EnsureLinks(ctx context.Context, clnt client.Client) error {
-	gconf := GCONF.Get()
+	gconf := plab.Lab.GetConfig()
 	if plab.SpokeConnectorMap == nil {
 		plab.SpokeConnectorMap = make(map[string]*Connector)
 	}
@@ -213,6 +346,12 @@ func (plab *ParsedLab) EnsureLinks(ctx context.Context, clnt client.Client) erro
 	if plab.SpokeLinkMap == nil {
 		plab.SpokeLinkMap = make(map[string]string)
 	}
+	if plab.LocalLinkMap == nil {
+		plab.LocalLinkMap = make(map[string]int)
+	}
+	if plab.CrossLabLinkMap == nil {
+		plab.CrossLabLinkMap = make(map[string]string)
+	}
 
 	vniList, err := GetAvailableVNIs(ctx, clnt, len(plab.Lab.Spec.LinkList))
 	if err != nil {
@@ -221,6 +360,18 @@ func (plab *ParsedLab) EnsureLinks(ctx context.Context, clnt client.Client) erro
 	for i, linkName := range GetSortedKeySlice(plab.Lab.Spec.LinkList) {
 		// for linkName, link := range plab.Lab.Spec.LinkList {
 		link := plab.Lab.Spec.LinkList[linkName]
+		if link.IsLocal() {
+			if err := plab.ensureLocalLink(ctx, clnt, linkName, link); err != nil {
+				return fmt.Errorf("failed to create local link %v for lab %v, %w", linkName, plab.Lab.Name, err)
+			}
+			continue
+		}
+		if link.IsCrossLab() {
+			if err := plab.ensureCrossLabLink(ctx, clnt, linkName, link, vniList[i]); err != nil {
+				return fmt.Errorf("failed to join shared LAN of lab-to-lab link %v for lab %v, %w", linkName, plab.Lab.Name, err)
+			}
+			continue
+		}
 		lan := new(k8slan.LAN)
 		err := clnt.Get(ctx,
 			types.NamespacedName{Namespace: plab.Lab.Namespace, Name: Getk8lanName(plab.Lab.Name, linkName)},
@@ -248,7 +399,11 @@ func (plab *ParsedLab) EnsureLinks(ctx context.Context, clnt client.Client) erro
 			}
 			lan.Finalizers = append(lan.Finalizers, FinalizerName)
 		}
-		for i, c := range link.Connectors {
+		connectors := []*Connector{}
+		for i := range link.Connectors {
+			connectors = append(connectors, &link.Connectors[i])
+		}
+		for i, c := range append(connectors, plab.getTrunkBridgeConnectors(linkName)...) {
 			spokeName := getSpokeName(*lan.Spec.VNI, i)
 			lan.Spec.SpokeList = append(lan.Spec.SpokeList, spokeName)
 			if _, ok := plab.SpokeMap[*c.NodeName]; !ok {
@@ -258,7 +413,7 @@ func (plab *ParsedLab) EnsureLinks(ctx context.Context, clnt client.Client) erro
 				plab.SpokeMap[*c.NodeName][linkName] = []string{}
 			}
 			plab.SpokeMap[*c.NodeName][linkName] = append(plab.SpokeMap[*c.NodeName][linkName], spokeName)
-			plab.SpokeConnectorMap[spokeName] = &c
+			plab.SpokeConnectorMap[spokeName] = c
 			plab.SpokeLinkMap[spokeName] = linkName
 		}
 
@@ -0,0 +1,315 @@
+package v1beta1
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"strconv"
+
+	k8slan "github.com/hujun-open/k8slan/api/v1beta1"
+	ncv1 "github.com/k8snetworkplumbingwg/network-attachment-definition-client/pkg/apis/k8s.cni.cncf.io/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	kvv1 "kubevirt.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// MaxLocalLinkMTU is the maximum MTU of local link bridge, there is no encapsulation overhead;
+// it is also used if MTU of the link is not specified
+const MaxLocalLinkMTU = 9000
+
+// getLocalMTU returns MTU of the local link bridge
+func (link *Link) getLocalMTU() int {
+	if link.MTU == nil {
+		return MaxLocalLinkMTU
+	}
+	return int(*link.MTU)
+}
+
+// IsLocal returns true if the link is wired by a local bridge instead of VxLAN
+func (link *Link) IsLocal() bool {
+	return link.Local != nil && *link.Local
+}
+
+// GetLocalLinkNADName returns name of the bridge NAD of a local link
+func GetLocalLinkNADName(lab, link string) string {
+	return lab + "-" + link + "-local"
+}
+
+// getLocalSpokeAddrNADName returns name of the bridge NAD with static addresses of a local link spoke
+func getLocalSpokeAddrNADName(lab, link, spokeName string) string {
+	return GetLocalLinkNADName(lab, link) + "-" + spokeName
+}
+
+func getLocalLinkBRName(brIndex int) string {
+	return fmt.Sprintf("knlloc%d", brIndex)
+}
+
+func getLocalSpokeName(brIndex, connectorIndex int) string {
+	return fmt.Sprintf("kloc%d-%d", brIndex, connectorIndex)
+}
+
+type bridgeIPAMAddr struct {
+	Address string `json:"address"`
+}
+
+type bridgeIPAMRoute struct {
+	Dst string `json:"dst"`
+	GW  string `json:"gw"`
+}
+
+type bridgeIPAM struct {
+	Type      string            `json:"type,omitempty"`
+	Addresses []bridgeIPAMAddr  `json:"addresses,omitempty"`
+	Routes    []bridgeIPAMRoute `json:"routes,omitempty"`
+}
+
+type bridgeNetConf struct {
+	CNIVersion string     `json:"cniVersion"`
+	Name       string     `json:"name"`
+	Type       string     `json:"type"`
+	MTU        int        `json:"mtu"`
+	Bridge     string     `json:"bridge"`
+	IPAM       bridgeIPAM `json:"ipam"`
+}
+
+// NewLocalLinkNAD returns a bridge NAD of a local link, the bridge is created on the worker by bridge CNI;
+// addrs and routes are configured via static IPAM if specified
+func NewLocalLinkNAD(nsName, labName, nadName string, brIndex, mtu int, addrs, routes []string) *ncv1.NetworkAttachmentDefinition {
+	conf := bridgeNetConf{
+		CNIVersion: "0.3.1",
+		Name:       nadName,
+		Type:       "bridge",
+		MTU:        mtu,
+		Bridge:     getLocalLinkBRName(brIndex),
+	}
+	if len(addrs) > 0 {
+		conf.IPAM.Type = "static"
+		for _, addr := range addrs {
+			conf.IPAM.Addresses = append(conf.IPAM.Addresses, bridgeIPAMAddr{Address: addr})
+		}
+		for _, routeStr := range routes {
+			route := mustParseRoute(routeStr)
+			conf.IPAM.Routes = append(conf.IPAM.Routes, bridgeIPAMRoute{Dst: route.To.String(), GW: route.Via.String()})
+		}
+	}
+	buf, _ := json.Marshal(conf)
+	r := &ncv1.NetworkAttachmentDefinition{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "k8s.cni.cncf.io/v1",
+			Kind:       "NetworkAttachmentDefinition",
+		},
+		ObjectMeta: GetObjMeta(nadName, labName, nsName, "", ""),
+		Spec: ncv1.NetworkAttachmentDefinitionSpec{
+			Config: string(buf),
+		},
+	}
+	r.Labels[BridgeIndexLabelKey] = strconv.Itoa(brIndex)
+	return r
+}
+
+// ensureLocalLink creates the bridge NAD of a local link and fills in spokes of its connectors
+func (plab *ParsedLab) ensureLocalLink(ctx context.Context, clnt client.Client, linkName string, link *Link) error {
+	nadName := GetLocalLinkNADName(plab.Lab.Name, linkName)
+	nad := new(ncv1.NetworkAttachmentDefinition)
+	err := clnt.Get(ctx, types.NamespacedName{Namespace: plab.Lab.Namespace, Name: nadName}, nad)
+	var brIndex int
+	switch {
+	case err == nil:
+		brIndex, err = strconv.Atoi(nad.Labels[BridgeIndexLabelKey])
+		if err != nil {
+			return fmt.Errorf("invalid %v value found in NAD %v, %w", BridgeIndexLabelKey, nadName, err)
+		}
+	case apierrors.IsNotFound(err):
+		indexList, err := GetAvailableBrIndex(ctx, clnt, 1)
+		if err != nil {
+			return fmt.Errorf("failed to allocate bridge index, %w", err)
+		}
+		brIndex = int(indexList[0])
+		nad = NewLocalLinkNAD(plab.Lab.Namespace, plab.Lab.Name, nadName, brIndex, link.getLocalMTU(), nil, nil)
+	default:
+		return fmt.Errorf("unexpected error getting existing NAD %v, %w", nadName, err)
+	}
+	plab.LocalLinkMap[linkName] = brIndex
+	for i := range link.Connectors {
+		c := &link.Connectors[i]
+		plab.addSpoke(getLocalSpokeName(brIndex, i), linkName, c)
+	}
+	return createIfNotExistsOrRemove(ctx, clnt, plab, nad, true, false)
+}
+
+// ensureLocalSpokeAddrNAD creates the bridge NAD of a local link spoke with connector's addresses and routes
+func (plab *ParsedLab) ensureLocalSpokeAddrNAD(ctx context.Context, clnt client.Client, spokeName string) error {
+	linkName := plab.SpokeLinkMap[spokeName]
+	c := plab.SpokeConnectorMap[spokeName]
+	nad := NewLocalLinkNAD(plab.Lab.Namespace, plab.Lab.Name, getLocalSpokeAddrNADName(plab.Lab.Name, linkName, spokeName),
+		plab.LocalLinkMap[linkName], plab.Lab.Spec.LinkList[linkName].getLocalMTU(), c.Addrs, c.Routes)
+	return createIfNotExistsOrRemove(ctx, clnt, plab, nad, true, false)
+}
+
+// addSpoke adds spoke of connector c of the link
+func (plab *ParsedLab) addSpoke(spokeName, linkName string, c *Connector) {
+	if _, ok := plab.SpokeMap[*c.NodeName]; !ok {
+		plab.SpokeMap[*c.NodeName] = make(map[string][]string)
+	}
+	plab.SpokeMap[*c.NodeName][linkName] = append(plab.SpokeMap[*c.NodeName][linkName], spokeName)
+	plab.SpokeConnectorMap[spokeName] = c
+	plab.SpokeLinkMap[spokeName] = linkName
+}
+
+// isLocalSpoke returns true if the spoke is a connector of a local link
+func (plab *ParsedLab) isLocalSpoke(spokeName string) bool {
+	_, ok := plab.LocalLinkMap[plab.SpokeLinkMap[spokeName]]
+	return ok
+}
+
+// getPodSpokeNAD returns NAD name of the spoke used by a pod,
+// and the resource key of k8slan device plugin, which is empty for local links;
+// addrNAD is true if the NAD configures connector's addresses
+func (plab *ParsedLab) getPodSpokeNAD(spokeName string, addrNAD bool) (string, string) {
+	linkName := plab.SpokeLinkMap[spokeName]
+	if plab.isLocalSpoke(spokeName) {
+		if addrNAD {
+			return getLocalSpokeAddrNADName(plab.Lab.Name, linkName, spokeName), ""
+		}
+		return GetLocalLinkNADName(plab.Lab.Name, linkName), ""
+	}
+	lanName := plab.getLANName(linkName)
+	nadName := k8slan.GetDefNADName(lanName, spokeName, true)
+	if addrNAD {
+		nadName = k8slan.GetAddrNADName(lanName, spokeName)
+	}
+	return plab.getNADRef(spokeName, nadName), fmt.Sprintf("%v/%v", K8sLANResKeyPrefix, k8slan.GetDPResouceName(lanName, spokeName, true))
+}
+
+// getVMISpokeNetwork returns the network and interface of the spoke used by a VMI,
+// macvtap binding is used for VxLAN links, bridge binding for local links
+func (plab *ParsedLab) getVMISpokeNetwork(spokeName string) (kvv1.Network, kvv1.Interface) {
+	linkName := plab.SpokeLinkMap[spokeName]
+	nadName := plab.getNADRef(spokeName, k8slan.GetDefNADName(plab.getLANName(linkName), spokeName, false))
+	intf := kvv1.Interface{
+		Name: spokeName,
+		Binding: &kvv1.PluginBinding{
+			Name: "macvtap",
+		},
+	}
+	if plab.isLocalSpoke(spokeName) {
+		nadName = GetLocalLinkNADName(plab.Lab.Name, linkName)
+		intf.Binding = nil
+		intf.InterfaceBindingMethod = kvv1.InterfaceBindingMethod{Bridge: &kvv1.InterfaceBridge{}}
+	}
+	return kvv1.Network{
+		Name: spokeName,
+		NetworkSource: kvv1.NetworkSource{
+			Multus: &kvv1.MultusNetwork{
+				NetworkName: nadName,
+			},
+		},
+	}, intf
+}
+
+// getLocalGroups returns co-location group of nodes connected by local links, key is the node name;
+// nodes connected directly or indirectly by local links are in the same group
+func getLocalGroups(spec *LabSpec) map[string]string {
+	parent := make(map[string]string)
+	var find func(string) string
+	find = func(n string) string {
+		if p, ok := parent[n]; ok && p != n {
+			parent[n] = find(p)
+			return parent[n]
+		}
+		parent[n] = n
+		return n
+	}
+	for _, linkName := range GetSortedKeySlice(spec.LinkList) {
+		link := spec.LinkList[linkName]
+		if link == nil || !link.IsLocal() || len(link.Connectors) == 0 {
+			continue
+		}
+		root := find(*link.Connectors[0].NodeName)
+		for _, c := range link.Connectors[1:] {
+			if r := find(*c.NodeName); r != root {
+				//keep the smaller name as root, so the group is stable
+				if r < root {
+					parent[root] = r
+					root = r
+				} else {
+					parent[r] = root
+				}
+			}
+		}
+	}
+	r := make(map[string]string)
+	for nodeName := range parent {
+		r[nodeName] = find(nodeName)
+	}
+	return r
+}
+
+// getLocalGroupLabelVal returns label value of co-location group of the node
+func (plab *ParsedLab) getLocalGroupLabelVal(nodeName string) (string, bool) {
+	root, ok := plab.LocalGroupMap[nodeName]
+	if !ok {
+		return "", false
+	}
+	h := fnv.New64a()
+	h.Write([]byte(plab.Lab.Name + "/" + root))
+	return fmt.Sprintf("%x", h.Sum64()), true
+}
+
+// setLocalAffinity sets label and pod affinity so that pods or VMIs of nodes connected by local links are on the same worker
+func (plab *ParsedLab) setLocalAffinity(nodeName string, objMeta *metav1.ObjectMeta, affinity **corev1.Affinity) {
+	group, ok := plab.getLocalGroupLabelVal(nodeName)
+	if !ok {
+		return
+	}
+	if objMeta.Labels == nil {
+		objMeta.Labels = make(map[string]string)
+	}
+	objMeta.Labels[LocalGroupLabelKey] = group
+	if *affinity == nil {
+		*affinity = new(corev1.Affinity)
+	}
+	if (*affinity).PodAffinity == nil {
+		(*affinity).PodAffinity = new(corev1.PodAffinity)
+	}
+	(*affinity).PodAffinity.RequiredDuringSchedulingIgnoredDuringExecution = append(
+		(*affinity).PodAffinity.RequiredDuringSchedulingIgnoredDuringExecution,
+		corev1.PodAffinityTerm{
+			LabelSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{LocalGroupLabelKey: group},
+			},
+			TopologyKey: "kubernetes.io/hostname",
+		})
+}
+
+// GetCoLocatedLinks returns links not marked local whose connectors all run on the same worker,
+// links with external or lab connector, trunk links and links they carry are skipped,
+// nodeWorkers is workers of each node, key is the node name
+func (spec *LabSpec) GetCoLocatedLinks(nodeWorkers map[string]map[string]bool) []string {
+	var r []string
+	trunkMap := spec.getTrunkMap()
+	for _, linkName := range GetSortedKeySlice(spec.LinkList) {
+		link := spec.LinkList[linkName]
+		if link == nil || link.IsLocal() || link.hasNonNodeConnector() || link.IsTrunk() || len(trunkMap[linkName]) > 0 {
+			continue
+		}
+		workers := make(map[string]bool)
+		for _, c := range link.Connectors {
+			if len(nodeWorkers[*c.NodeName]) == 0 {
+				workers = nil
+				break
+			}
+			for w := range nodeWorkers[*c.NodeName] {
+				workers[w] = true
+			}
+		}
+		if len(workers) == 1 {
+			r = append(r, linkName)
+		}
+	}
+	return r
+}
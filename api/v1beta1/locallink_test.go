@@ -0,0 +1,77 @@
+package v1beta1
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func newTestLink(local bool, nodes ...string) *Link {
+	link := new(Link)
+	if local {
+		link.Local = ReturnPointerVal(true)
+	}
+	for _, n := range nodes {
+		link.Connectors = append(link.Connectors, Connector{NodeName: ReturnPointerVal(n)})
+	}
+	return link
+}
+
+func TestLocalLink(t *testing.T) {
+	lab := &Lab{}
+	lab.Name = "lab1"
+	lab.Spec.LinkList = map[string]*Link{
+		"l1": newTestLink(true, "b", "c"),
+		"l2": newTestLink(true, "c", "a"),
+		"l3": newTestLink(false, "a", "d"),
+		"l4": newTestLink(true, "e", "f"),
+		"l5": newTestLink(false, "d", "g"),
+	}
+	plab := ParseLab(lab, nil)
+	for nodeName, group := range map[string]string{"a": "a", "b": "a", "c": "a", "e": "e", "f": "e"} {
+		if plab.LocalGroupMap[nodeName] != group {
+			t.Fatalf("expect node %v in group %v, got %v", nodeName, group, plab.LocalGroupMap[nodeName])
+		}
+	}
+	if _, ok := plab.LocalGroupMap["d"]; ok {
+		t.Fatalf("node d is not connected by local link")
+	}
+	//nodes of the same group get same label and affinity
+	pod := new(corev1.Pod)
+	plab.setLocalAffinity("b", &pod.ObjectMeta, &pod.Spec.Affinity)
+	ga, _ := plab.getLocalGroupLabelVal("a")
+	if pod.Labels[LocalGroupLabelKey] != ga || len(pod.Spec.Affinity.PodAffinity.RequiredDuringSchedulingIgnoredDuringExecution) != 1 {
+		t.Fatalf("unexpected local affinity %+v", pod)
+	}
+	ge, _ := plab.getLocalGroupLabelVal("e")
+	if ge == ga {
+		t.Fatalf("different groups have same label value")
+	}
+	pod = new(corev1.Pod)
+	plab.setLocalAffinity("d", &pod.ObjectMeta, &pod.Spec.Affinity)
+	if pod.Spec.Affinity != nil {
+		t.Fatalf("unexpected affinity for non-local node")
+	}
+
+	workers := map[string]map[string]bool{
+		"a": {"w1": true},
+		"d": {"w1": true},
+		"g": {"w1": true, "w2": true},
+	}
+	if r := lab.Spec.GetCoLocatedLinks(workers); !slices.Equal(r, []string{"l3"}) {
+		t.Fatalf("unexpected co-located links %v", r)
+	}
+
+	nad := NewLocalLinkNAD("ns1", "lab1", "lab1-l1-local-kloc5-0", 5, MaxLocalLinkMTU,
+		[]string{"10.0.0.1/24"}, []string{"0.0.0.0/0 via 10.0.0.254"})
+	conf := bridgeNetConf{}
+	if err := json.Unmarshal([]byte(nad.Spec.Config), &conf); err != nil {
+		t.Fatal(err)
+	}
+	if conf.Bridge != "knlloc5" || conf.IPAM.Type != "static" || conf.IPAM.Routes[0].GW != "10.0.0.254" ||
+		nad.Labels[BridgeIndexLabelKey] != "5" {
+		t.Fatalf("unexpected NAD %+v", nad)
+	}
+}
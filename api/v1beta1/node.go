@@ -3,6 +3,8 @@ package v1beta1
 import (
 	"fmt"
 	"reflect"
+
+	"github.com/distribution/reference"
 )
 
 // OneOfSystem specifies one KNL node type, only one field should be specified.
@@ -28,6 +30,42 @@ type OneOfSystem struct {
 	// +optional
 	// +nullable
 	SRSIM *SRSim `json:"srsim,omitempty"`
+	// +optional
+	// +nullable
+	FRR *FRR `json:"frr,omitempty"`
+	// +optional
+	// +nullable
+	CEOS *CEOS `json:"ceos,omitempty"`
+	// +optional
+	// +nullable
+	CRPD *CRPD `json:"crpd,omitempty"`
+	// +optional
+	// +nullable
+	VJunos *VJunos `json:"vjunos,omitempty"`
+	// +optional
+	// +nullable
+	XRd *XRd `json:"xrd,omitempty"`
+	// +optional
+	// +nullable
+	SONiC *SONiC `json:"sonic,omitempty"`
+	// +optional
+	// +nullable
+	Dummy *Dummy `json:"dummy,omitempty"`
+	// +optional
+	// +nullable
+	TrafficGen *TrafficGen `json:"trafficgen,omitempty"`
+	// name of a NodeProfile in the lab namespace, or a ClusterNodeProfile if no such NodeProfile;
+	// unspecified fields of the node are filled from the profile, then from defaults in KNLConfig
+	// +optional
+	// +nullable
+	Profile *string `json:"profile,omitempty"`
+}
+
+var systemType = reflect.TypeOf((*System)(nil)).Elem()
+
+// isSystemField returns true if f is a node type field of OneOfSystem
+func isSystemField(f reflect.StructField) bool {
+	return f.Type.Implements(systemType)
 }
 
 //nullable marker + omitempty in OneOfSystem is important, it allows have a empty node specific in the CR with `{}`
@@ -38,7 +76,7 @@ func (onesys *OneOfSystem) validate() error {
 	for i := 0; i < v.NumField(); i++ {
 		fieldValue := v.Field(i)
 		// 4. Check if the field is a pointer
-		if fieldValue.Kind() == reflect.Ptr {
+		if fieldValue.Kind() == reflect.Ptr && isSystemField(v.Type().Field(i)) {
 			// 5. Use IsNil() to check if the pointer value is nil
 			if !fieldValue.IsNil() {
 				numberOfSpecified++
@@ -61,7 +99,7 @@ func (onesys *OneOfSystem) GetSystem() (System, string) {
 	for i := 0; i < v.NumField(); i++ {
 		fieldValue := v.Field(i)
 		// 4. Check if the field is a pointer
-		if fieldValue.Kind() == reflect.Ptr {
+		if fieldValue.Kind() == reflect.Ptr && isSystemField(t.Field(i)) {
 			// 5. Use IsNil() to check if the pointer value is nil
 			if !fieldValue.IsNil() {
 				return fieldValue.Interface().(System), t.Field(i).Name
@@ -75,6 +113,30 @@ func (spec *LabSpec) Validate() error {
 	if len(spec.NodeList) == 0 {
 		return fmt.Errorf("no node is specified")
 	}
+	if spec.Generator != nil {
+		if err := spec.Generator.Validate(); err != nil {
+			return fmt.Errorf("generator is invalid, %w", err)
+		}
+	}
+	for groupName, g := range spec.NodeGroups {
+		if err := g.Validate(); err != nil {
+			return fmt.Errorf("node group %v is invalid, %w", groupName, err)
+		}
+	}
+	if spec.Defaults != nil && spec.Defaults.Profile != nil {
+		return fmt.Errorf("profile can't be specified in defaults")
+	}
+	if spec.PVCStorageClass != nil && isStrNotSpecfied(spec.PVCStorageClass) {
+		return fmt.Errorf("storage class can't be empty")
+	}
+	for _, img := range []*string{spec.SRCPMLoaderImage, spec.SRIOMLoaderImage, spec.SideCarHookImg} {
+		if img == nil {
+			continue
+		}
+		if _, err := reference.Parse(*img); err != nil {
+			return fmt.Errorf("%v is not valid container image url: %w", *img, err)
+		}
+	}
 	for nodeName := range spec.NodeList {
 		if err := spec.NodeList[nodeName].validate(); err != nil {
 			return fmt.Errorf("Node %v is invalid, %w", nodeName, err)
@@ -84,10 +146,38 @@ func (spec *LabSpec) Validate() error {
 			return fmt.Errorf("node %v failed validation, %w", nodeName, err)
 		}
 	}
+	externals := make(map[string]string)
+	crossLabs := make(map[string]string)
+	trunkMap := spec.getTrunkMap()
 	for linkName, link := range spec.LinkList {
-		if err := link.Validate(); err != nil {
+		if err := link.validate(len(trunkMap[linkName])); err != nil {
 			return fmt.Errorf("link %v is invalid, %w", linkName, err)
 		}
+		if link.IsCrossLab() {
+			//both labs derive the shared LAN from the two nodes, so parallel links between them are not supported
+			local, peer := link.getCrossLabConnectors()
+			peerNS := ""
+			if peer.PeerLab.Namespace != nil {
+				peerNS = *peer.PeerLab.Namespace
+			}
+			key := fmt.Sprintf("%v/%v/%v/%v", *local.NodeName, peerNS, peer.PeerLab.Name, *peer.NodeName)
+			if prev, ok := crossLabs[key]; ok {
+				return fmt.Errorf("lab-to-lab link %v and %v connect the same nodes", prev, linkName)
+			}
+			crossLabs[key] = linkName
+		}
+		for _, c := range link.Connectors {
+			if !c.IsExternal() {
+				continue
+			}
+			if _, ok := spec.NodeList[*c.NodeName]; ok {
+				return fmt.Errorf("external connector %v in link %v has same name as a node", *c.NodeName, linkName)
+			}
+			if prev, ok := externals[*c.NodeName]; ok {
+				return fmt.Errorf("external connector %v is in both link %v and %v", *c.NodeName, prev, linkName)
+			}
+			externals[*c.NodeName] = linkName
+		}
 		//check port settting
 		// for _, c := range link.Connectors {
 		// 	if c.PortId != nil {
@@ -102,6 +192,40 @@ func (spec *LabSpec) Validate() error {
 		// 	}
 		// }
 	}
-
+	if err := spec.validateTrunks(externals); err != nil {
+		return err
+	}
 	return nil
 }
+
+// Warnings returns warnings of all nodes implementing WarningSystem, spec must be validated first
+func (spec *LabSpec) Warnings() []string {
+	var r []string
+	for _, nodeName := range GetSortedKeySlice(spec.NodeList) {
+		sys, _ := spec.NodeList[nodeName].GetSystem()
+		if wsys, ok := sys.(WarningSystem); ok {
+			for _, w := range wsys.Warnings(spec, nodeName) {
+				r = append(r, fmt.Sprintf("node %v: %v", nodeName, w))
+			}
+		}
+	}
+	return r
+}
+
+// ApplyProfile fills unspecified fields of the node with profile, the node type is taken from profile if the node doesn't specify one;
+// profile must specify exactly one node type, which must be same as the node's
+func (onesys *OneOfSystem) ApplyProfile(profile *OneOfSystem) error {
+	if err := profile.validate(); err != nil {
+		return fmt.Errorf("profile is invalid, %w", err)
+	}
+	psys, psysName := profile.DeepCopy().GetSystem()
+	sys, sysName := onesys.GetSystem()
+	if sys == nil {
+		AssignSystem(psys, onesys)
+		return nil
+	}
+	if sysName != psysName {
+		return fmt.Errorf("profile is for %v, but node is %v", psysName, sysName)
+	}
+	return FillNilPointers(sys, psys)
+}
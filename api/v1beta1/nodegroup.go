@@ -0,0 +1,211 @@
+package v1beta1
+
+import (
+	"fmt"
+	"math/big"
+	"net/netip"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// NodeGroup generates a number of similar nodes and their connectors,
+// it is expanded into nodes and links by the defaulting webhook
+type NodeGroup struct {
+	//+required
+	//name pattern of generated nodes, must contain exactly one "%d" that is replaced by the replica index, e.g. "pod-%d";
+	//node type is derived from the name if template doesn't specify one
+	NamePattern string `json:"namePattern"`
+	//+required
+	//number of nodes to generate
+	Replicas int32 `json:"replicas"`
+	//index of the first replica, default is 1
+	// +optional
+	// +nullable
+	Start *int32 `json:"start,omitempty"`
+	//template of generated nodes
+	// +optional
+	// +nullable
+	Template *OneOfSystem `json:"template,omitempty"`
+	//links each generated node connects to
+	// +optional
+	Links []NodeGroupLink `json:"links,omitempty"`
+}
+
+// NodeGroupLink specifies how each generated node connects to a link
+type NodeGroupLink struct {
+	//+required
+	//name of the link, if shared is true, all generated nodes connect to this link;
+	//otherwise a link is created for each generated node, named as the link name followed by "-" and replica index
+	Link string `json:"link"`
+	//all generated nodes connect to the same link if true
+	// +optional
+	// +nullable
+	Shared *bool `json:"shared,omitempty"`
+	//port id of generated node's connector, "%d" is replaced by the replica index if present
+	// +optional
+	// +nullable
+	PortId *string `json:"port,omitempty"`
+	//a list of address pool in format `xxxx/yy`, the n-th replica gets the address of the pool plus n-1, with same prefix length,
+	//e.g. "10.0.0.10/24" assigns 10.0.0.10/24, 10.0.0.11/24 ...
+	// +optional
+	AddrPools []string `json:"addrPools,omitempty"`
+	//static routes of generated node's connector in format `<prefix> via <nexthop>`
+	// +optional
+	Routes []string `json:"routes,omitempty"`
+	//other connectors of the link, e.g. the port of a BNG that all subscribers connect to;
+	//they are copied into each link if shared is false, and "%d" in their port id is replaced by the replica index
+	// +optional
+	Peers []Connector `json:"peers,omitempty"`
+}
+
+func (g *NodeGroup) start() int {
+	if g.Start == nil {
+		return 1
+	}
+	return int(*g.Start)
+}
+
+func (g *NodeGroup) nodeName(index int) string {
+	return strings.Replace(g.NamePattern, "%d", strconv.Itoa(index), 1)
+}
+
+func (gl *NodeGroupLink) isShared() bool {
+	return gl.Shared != nil && *gl.Shared
+}
+
+// linkName returns name of the link of the replica index
+func (gl *NodeGroupLink) linkName(index int) string {
+	if gl.isShared() {
+		return gl.Link
+	}
+	return fmt.Sprintf("%v-%d", gl.Link, index)
+}
+
+// poolAddr returns the n-th (starts from 0) address of pool
+func poolAddr(pool string, n int) (string, error) {
+	prefix, err := netip.ParsePrefix(pool)
+	if err != nil {
+		return "", fmt.Errorf("%v is not a valid address pool, %w", pool, err)
+	}
+	v := new(big.Int).SetBytes(prefix.Addr().AsSlice())
+	v.Add(v, big.NewInt(int64(n)))
+	buf := v.Bytes()
+	if len(buf) > prefix.Addr().BitLen()/8 {
+		return "", fmt.Errorf("address pool %v is exhausted", pool)
+	}
+	full := make([]byte, prefix.Addr().BitLen()/8)
+	copy(full[len(full)-len(buf):], buf)
+	addr, _ := netip.AddrFromSlice(full)
+	if !prefix.Masked().Contains(addr) {
+		return "", fmt.Errorf("address pool %v is exhausted", pool)
+	}
+	return netip.PrefixFrom(addr, prefix.Bits()).String(), nil
+}
+
+func (g *NodeGroup) Validate() error {
+	if strings.Count(g.NamePattern, "%d") != 1 {
+		return fmt.Errorf("name pattern %v must contain exactly one %%d", g.NamePattern)
+	}
+	if g.Replicas < 0 {
+		return fmt.Errorf("replicas can't be negative")
+	}
+	if g.start() < 0 {
+		return fmt.Errorf("start can't be negative")
+	}
+	for _, gl := range g.Links {
+		if gl.Link == "" {
+			return fmt.Errorf("link name can't be empty")
+		}
+		for _, pool := range gl.AddrPools {
+			if _, err := poolAddr(pool, int(g.Replicas)-1); err != nil {
+				return err
+			}
+		}
+		for _, routeStr := range gl.Routes {
+			if _, err := parseRoute(routeStr); err != nil {
+				return fmt.Errorf("route %v is not valid, %w", routeStr, err)
+			}
+		}
+		for i, c := range gl.Peers {
+			if c.NodeName == nil || *c.NodeName == "" {
+				return fmt.Errorf("peer %d of link %v doesn't specify node", i, gl.Link)
+			}
+		}
+	}
+	return nil
+}
+
+// validateNodeNames checks nodes generated by g don't clash with nodes already in spec,
+// e.g. a node written in the lab whose name matches the name pattern of g
+func (g *NodeGroup) validateNodeNames(spec *LabSpec) error {
+	for n := 0; n < int(g.Replicas); n++ {
+		nodeName := g.nodeName(g.start() + n)
+		if _, ok := spec.NodeList[nodeName]; ok {
+			return fmt.Errorf("node %v already exists", nodeName)
+		}
+	}
+	return nil
+}
+
+// ExpandNodeGroups generates nodes and connectors of all node groups into NodeList and LinkList,
+// it is called once on lab creation, a generated node must not exist in NodeList
+func (spec *LabSpec) ExpandNodeGroups() error {
+	if len(spec.NodeGroups) == 0 {
+		return nil
+	}
+	if spec.NodeList == nil {
+		spec.NodeList = make(map[string]*OneOfSystem)
+	}
+	if spec.LinkList == nil {
+		spec.LinkList = make(map[string]*Link)
+	}
+	for _, groupName := range GetSortedKeySlice(spec.NodeGroups) {
+		g := spec.NodeGroups[groupName]
+		if err := g.Validate(); err != nil {
+			return fmt.Errorf("node group %v is invalid, %w", groupName, err)
+		}
+		if err := g.validateNodeNames(spec); err != nil {
+			return fmt.Errorf("node group %v is invalid, %w", groupName, err)
+		}
+		for n := 0; n < int(g.Replicas); n++ {
+			index := g.start() + n
+			nodeName := g.nodeName(index)
+			if g.Template != nil {
+				spec.NodeList[nodeName] = g.Template.DeepCopy()
+			} else {
+				spec.NodeList[nodeName] = new(OneOfSystem)
+			}
+			for _, gl := range g.Links {
+				c := Connector{
+					NodeName: ReturnPointerVal(nodeName),
+					Routes:   slices.Clone(gl.Routes),
+				}
+				if gl.PortId != nil {
+					c.PortId = ReturnPointerVal(strings.Replace(*gl.PortId, "%d", strconv.Itoa(index), 1))
+				}
+				for _, pool := range gl.AddrPools {
+					addr, _ := poolAddr(pool, n)
+					c.Addrs = append(c.Addrs, addr)
+				}
+				linkName := gl.linkName(index)
+				link, ok := spec.LinkList[linkName]
+				if !ok || link == nil {
+					link = new(Link)
+					spec.LinkList[linkName] = link
+				}
+				if len(link.Connectors) == 0 {
+					for _, peer := range gl.Peers {
+						pc := peer.DeepCopy()
+						if !gl.isShared() && pc.PortId != nil {
+							pc.PortId = ReturnPointerVal(strings.Replace(*pc.PortId, "%d", strconv.Itoa(index), 1))
+						}
+						link.Connectors = append(link.Connectors, *pc)
+					}
+				}
+				link.Connectors = append(link.Connectors, c)
+			}
+		}
+	}
+	return nil
+}
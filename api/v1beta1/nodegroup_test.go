@@ -0,0 +1,68 @@
+package v1beta1
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExpandNodeGroups(t *testing.T) {
+	spec := &LabSpec{
+		NodeList: map[string]*OneOfSystem{
+			"magc-1-1": {},
+		},
+		NodeGroups: map[string]*NodeGroup{
+			"subscribers": {
+				NamePattern: "pod-%d",
+				Replicas:    3,
+				Template:    &OneOfSystem{Pod: &GeneralPod{Image: ReturnPointerVal("alpine")}},
+				Links: []NodeGroupLink{
+					{
+						Link:      "access",
+						Shared:    ReturnPointerVal(true),
+						AddrPools: []string{"10.0.0.10/24", "2001:db8::a/64"},
+						Routes:    []string{"0.0.0.0/0 via 10.0.0.1"},
+						Peers:     []Connector{{NodeName: ReturnPointerVal("magc-1-1"), PortId: ReturnPointerVal("1/1/c1/1")}},
+					},
+					{
+						Link:   "mgmt",
+						PortId: ReturnPointerVal("eth%d"),
+						Peers:  []Connector{{NodeName: ReturnPointerVal("magc-1-1"), PortId: ReturnPointerVal("1/1/c2/%d")}},
+					},
+				},
+			},
+		},
+	}
+	if err := spec.ExpandNodeGroups(); err != nil {
+		t.Fatal(err)
+	}
+	if len(spec.NodeList) != 4 || spec.NodeList["pod-3"].Pod == nil || *spec.NodeList["pod-3"].Pod.Image != "alpine" {
+		t.Fatalf("unexpected nodes %v", GetSortedKeySlice(spec.NodeList))
+	}
+	access := spec.LinkList["access"]
+	if len(access.Connectors) != 4 || *access.Connectors[0].NodeName != "magc-1-1" {
+		t.Fatalf("unexpected access link %+v", access)
+	}
+	if c := access.Connectors[3]; *c.NodeName != "pod-3" || c.Addrs[0] != "10.0.0.12/24" || c.Addrs[1] != "2001:db8::c/64" {
+		t.Fatalf("unexpected connector %+v", c)
+	}
+	mgmt := spec.LinkList["mgmt-2"]
+	if len(mgmt.Connectors) != 2 || *mgmt.Connectors[0].PortId != "1/1/c2/2" || *mgmt.Connectors[1].PortId != "eth2" {
+		t.Fatalf("unexpected mgmt link %+v", mgmt)
+	}
+	//generated node must not exist, e.g. hand-written node matching the name pattern
+	if err := spec.ExpandNodeGroups(); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Fatalf("expect error for existing node, got %v", err)
+	}
+	//pool exhausted
+	delete(spec.NodeList, "pod-1")
+	delete(spec.NodeList, "pod-2")
+	delete(spec.NodeList, "pod-3")
+	spec.NodeGroups["subscribers"].Replicas = 250
+	if err := spec.ExpandNodeGroups(); err == nil || !strings.Contains(err.Error(), "exhausted") {
+		t.Fatalf("expect error for exhausted pool, got %v", err)
+	}
+	spec.NodeGroups["subscribers"].NamePattern = "pod"
+	if err := spec.NodeGroups["subscribers"].Validate(); err == nil {
+		t.Fatalf("expect error for name pattern without %%d")
+	}
+}
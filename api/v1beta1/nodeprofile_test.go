@@ -0,0 +1,42 @@
+package v1beta1
+
+import (
+	"testing"
+)
+
+func TestApplyProfile(t *testing.T) {
+	profile := &OneOfSystem{
+		SRL: &SRLinux{
+			Image:   ReturnPointerVal("ghcr.io/nokia/srlinux:25.7.1"),
+			Chassis: ReturnPointerVal("ixr-d2l"),
+		},
+	}
+	//node type comes from the profile
+	node := &OneOfSystem{Profile: ReturnPointerVal("p1")}
+	if err := node.ApplyProfile(profile); err != nil {
+		t.Fatal(err)
+	}
+	if node.SRL == nil || *node.SRL.Chassis != "ixr-d2l" {
+		t.Fatalf("node doesn't get system from profile, %+v", node)
+	}
+	if node.SRL == profile.SRL {
+		t.Fatalf("node shares system with profile")
+	}
+	//lab values take precedence
+	node = &OneOfSystem{SRL: &SRLinux{Chassis: ReturnPointerVal("ixr-h2")}}
+	if err := node.ApplyProfile(profile); err != nil {
+		t.Fatal(err)
+	}
+	if *node.SRL.Chassis != "ixr-h2" || *node.SRL.Image != "ghcr.io/nokia/srlinux:25.7.1" {
+		t.Fatalf("unexpected node after applying profile, %+v", node.SRL)
+	}
+	//type mismatch
+	node = &OneOfSystem{FRR: &FRR{}}
+	if err := node.ApplyProfile(profile); err == nil {
+		t.Fatalf("expect error for profile of different node type")
+	}
+	//profile with more than one system
+	if err := (&OneOfSystem{}).ApplyProfile(&OneOfSystem{SRL: &SRLinux{}, FRR: &FRR{}}); err == nil {
+		t.Fatalf("expect error for invalid profile")
+	}
+}
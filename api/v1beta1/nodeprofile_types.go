@@ -0,0 +1,77 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +kubebuilder:object:root=true
+
+// NodeProfile is the Schema for the nodeprofiles API,
+// it is a partial node spec that a lab node in the same namespace could refer to via profile;
+// exactly one node type must be specified, profile field of the spec is ignored
+type NodeProfile struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// metadata is a standard object metadata
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty,omitzero"`
+
+	// spec is the partial node spec
+	// +required
+	Spec OneOfSystem `json:"spec"`
+}
+
+// +kubebuilder:object:root=true
+
+// NodeProfileList contains a list of NodeProfile
+type NodeProfileList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []NodeProfile `json:"items"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster
+
+// ClusterNodeProfile is the Schema for the clusternodeprofiles API,
+// it is same as NodeProfile but could be referred by labs in all namespaces
+type ClusterNodeProfile struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// metadata is a standard object metadata
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty,omitzero"`
+
+	// spec is the partial node spec
+	// +required
+	Spec OneOfSystem `json:"spec"`
+}
+
+// +kubebuilder:object:root=true
+
+// ClusterNodeProfileList contains a list of ClusterNodeProfile
+type ClusterNodeProfileList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClusterNodeProfile `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&NodeProfile{}, &NodeProfileList{}, &ClusterNodeProfile{}, &ClusterNodeProfileList{})
+}
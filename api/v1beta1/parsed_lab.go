@@ -27,6 +27,10 @@ type ParsedLab struct {
 	SpokeMap          map[string]map[string][]string //1st key is nodename, 2nd key is link name, val is list of spoke name
 	SpokeConnectorMap map[string]*Connector          //key is the spokename, spokename is per connector
 	SpokeLinkMap      map[string]string              //key is the spokename, value is link name
+	LocalLinkMap      map[string]int                 //key is name of local link, value is its bridge index
+	LocalGroupMap     map[string]string              //key is node name, value is the co-location group of nodes connected by local links
+	CrossLabLinkMap   map[string]string              //key is name of lab-to-lab link, value is its shared LAN name
+	TrunkMap          map[string][]string            //key is link name, value is a list of trunk link carrying it
 }
 
 func ParseLab(lab *Lab, sch *runtime.Scheme) *ParsedLab {
@@ -35,6 +39,10 @@ func ParseLab(lab *Lab, sch *runtime.Scheme) *ParsedLab {
 	r.ConnectorMap = make(map[string][]string)
 	for linkName, link := range r.Lab.Spec.LinkList {
 		for _, c := range link.Connectors {
+			if c.IsCrossLab() {
+				//node of another lab
+				continue
+			}
 			if _, ok := r.ConnectorMap[*c.NodeName]; ok {
 				r.ConnectorMap[*c.NodeName] = append(r.ConnectorMap[*c.NodeName], linkName)
 			} else {
@@ -42,6 +50,8 @@ func ParseLab(lab *Lab, sch *runtime.Scheme) *ParsedLab {
 			}
 		}
 	}
+	r.LocalGroupMap = getLocalGroups(&lab.Spec)
+	r.TrunkMap = lab.Spec.getTrunkMap()
 	r.SetOwnerFunc = func(controlled metav1.Object) error {
 		return ctrl.SetControllerReference(lab, controlled, sch)
 	}
@@ -51,7 +61,7 @@ func ParseLab(lab *Lab, sch *runtime.Scheme) *ParsedLab {
 func (lab *ParsedLab) getLinkandConnector(node, linkName string) (*Link, *Connector) {
 	if link, ok := lab.Lab.Spec.LinkList[linkName]; ok {
 		for _, c := range link.Connectors {
-			if *c.NodeName == node {
+			if c.IsNode(node) {
 				return link, &c
 			}
 		}
@@ -0,0 +1,117 @@
+package v1beta1
+
+import (
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+const (
+	//max length of linux interface name
+	maxLinuxIntfNameLen = 15
+)
+
+// validateLinuxIntfName checks name is a valid linux interface name to be used by a connector of a pod based node
+func validateLinuxIntfName(name string) error {
+	if name == "" || len(name) > maxLinuxIntfNameLen {
+		return fmt.Errorf("interface name %v must be 1 to %d characters", name, maxLinuxIntfNameLen)
+	}
+	if strings.ContainsAny(name, "/: \t") {
+		return fmt.Errorf("interface name %v contains invalid character", name)
+	}
+	if name == "eth0" || name == "lo" {
+		return fmt.Errorf("interface name %v is reserved", name)
+	}
+	return nil
+}
+
+// validatePodPortIds checks PortId of each connector of nodeName via check, and there is no duplicate PortId
+func validatePodPortIds(lab *LabSpec, nodeName string, check func(string) error) error {
+	used := make(map[string]string)
+	for _, linkName := range GetSortedKeySlice(lab.LinkList) {
+		for _, c := range lab.LinkList[linkName].Connectors {
+			if !c.IsNode(nodeName) || c.PortId == nil {
+				continue
+			}
+			if err := check(*c.PortId); err != nil {
+				return fmt.Errorf("port %v in link %v is invalid, %w", *c.PortId, linkName, err)
+			}
+			if prevLink, ok := used[*c.PortId]; ok {
+				return fmt.Errorf("port %v is used by both link %v and %v", *c.PortId, prevLink, linkName)
+			}
+			used[*c.PortId] = linkName
+		}
+	}
+	return nil
+}
+
+// validateNoPodAddrs returns error if any connector of nodeName has addresses or routes,
+// used by nodes that configure interfaces via their own config instead of the NAD
+func validateNoPodAddrs(lab *LabSpec, nodeName string) error {
+	for _, linkName := range GetSortedKeySlice(lab.LinkList) {
+		for _, c := range lab.LinkList[linkName].Connectors {
+			if c.IsNode(nodeName) && (len(c.Addrs) > 0 || len(c.Routes) > 0) {
+				return fmt.Errorf("connector in link %v can't have addresses or routes, they should be specified in the node config", linkName)
+			}
+		}
+	}
+	return nil
+}
+
+// setPodNetworks adds multus annotation and k8slan resource limits of all connectors of nodeName to the first container of pod;
+// interface of a connector is named as portName(PortId) if PortId is specified, otherwise via autoName(i),
+// i starts from 1 and increases in sorted link order, names already used by a PortId are skipped;
+// portName could be nil, in which case PortId is used as it is, if portName returns empty string, the interface is auto named;
+// return interface names in same order
+func setPodNetworks(lab *ParsedLab, nodeName string, pod *corev1.Pod, autoName func(int) string, portName func(string) string) []string {
+	if portName == nil {
+		portName = func(portId string) string { return portId }
+	}
+	used := make(map[string]bool)
+	for _, spokes := range lab.SpokeMap[nodeName] {
+		for _, spokeName := range spokes {
+			if portId := lab.SpokeConnectorMap[spokeName].PortId; portId != nil && portName(*portId) != "" {
+				used[portName(*portId)] = true
+			}
+		}
+	}
+	netList := []string{}
+	intfList := []string{}
+	i := 1
+	if pod.Spec.Containers[0].Resources.Limits == nil {
+		pod.Spec.Containers[0].Resources.Limits = make(corev1.ResourceList)
+	}
+	for _, linkName := range GetSortedKeySlice(lab.SpokeMap[nodeName]) {
+		for _, spokeName := range lab.SpokeMap[nodeName][linkName] {
+			nadName, resKey := lab.getPodSpokeNAD(spokeName, false)
+			intfName := ""
+			if portId := lab.SpokeConnectorMap[spokeName].PortId; portId != nil {
+				intfName = portName(*portId)
+			}
+			if intfName == "" {
+				for {
+					intfName = autoName(i)
+					i++
+					if !used[intfName] {
+						break
+					}
+				}
+			}
+			netList = append(netList, fmt.Sprintf("%v@%v", nadName, intfName))
+			intfList = append(intfList, intfName)
+			if resKey != "" {
+				pod.Spec.Containers[0].Resources.Limits[corev1.ResourceName(resKey)] = resource.MustParse("1")
+			}
+		}
+	}
+	lab.setLocalAffinity(nodeName, &pod.ObjectMeta, &pod.Spec.Affinity)
+	if len(netList) > 0 {
+		if pod.Annotations == nil {
+			pod.Annotations = make(map[string]string)
+		}
+		pod.Annotations[MultusAnnoKey] = strings.Join(netList, ",")
+	}
+	return intfList
+}
@@ -0,0 +1,148 @@
+package v1beta1
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSetPodNetworks(t *testing.T) {
+	lab := &Lab{
+		ObjectMeta: metav1.ObjectMeta{Name: "lab1", Namespace: "default"},
+		Spec: LabSpec{
+			NodeList: map[string]*OneOfSystem{
+				"frr-1": {FRR: &FRR{}},
+				"frr-2": {FRR: &FRR{}},
+			},
+			LinkList: map[string]*Link{
+				"link1": {Connectors: []Connector{{NodeName: ReturnPointerVal("frr-1")}, {NodeName: ReturnPointerVal("frr-2")}}},
+				"link2": {Connectors: []Connector{{NodeName: ReturnPointerVal("frr-1")}, {NodeName: ReturnPointerVal("frr-2")}}},
+				"link3": {Connectors: []Connector{{NodeName: ReturnPointerVal("frr-1"), PortId: ReturnPointerVal("eth1")}, {NodeName: ReturnPointerVal("frr-2")}}},
+			},
+		},
+	}
+	plab := ParseLab(lab, nil)
+	//spokes are normally populated by EnsureLinks
+	plab.SpokeMap = map[string]map[string][]string{}
+	plab.SpokeConnectorMap = map[string]*Connector{}
+	plab.SpokeLinkMap = map[string]string{}
+	for i, linkName := range GetSortedKeySlice(lab.Spec.LinkList) {
+		for j, c := range lab.Spec.LinkList[linkName].Connectors {
+			spokeName := getSpokeName(int32(i), j)
+			if plab.SpokeMap[*c.NodeName] == nil {
+				plab.SpokeMap[*c.NodeName] = map[string][]string{}
+			}
+			plab.SpokeMap[*c.NodeName][linkName] = append(plab.SpokeMap[*c.NodeName][linkName], spokeName)
+			plab.SpokeConnectorMap[spokeName] = &c
+			plab.SpokeLinkMap[spokeName] = linkName
+		}
+	}
+	pod := NewBasePod("lab1", "frr-1", "default", "frr", FRRNode)
+	intfs := setPodNetworks(plab, "frr-1", pod, func(i int) string { return "eth" + string(rune('0'+i)) }, nil)
+	if strings.Join(intfs, ",") != "eth2,eth3,eth1" {
+		t.Fatalf("unexpected interface names %v", intfs)
+	}
+	if len(strings.Split(pod.Annotations[MultusAnnoKey], ",")) != 3 {
+		t.Fatalf("unexpected multus annotation %v", pod.Annotations[MultusAnnoKey])
+	}
+	if len(pod.Spec.Containers[0].Resources.Limits) != 3 {
+		t.Fatalf("expect 3 resource limits, got %v", pod.Spec.Containers[0].Resources.Limits)
+	}
+	if _, ok := pod.Spec.Containers[0].Resources.Limits[corev1.ResourceCPU]; ok {
+		t.Fatalf("unexpected cpu limit")
+	}
+
+	if err := validatePodPortIds(&lab.Spec, "frr-1", validateLinuxIntfName); err != nil {
+		t.Fatal(err)
+	}
+	lab.Spec.LinkList["link2"].Connectors[0].PortId = ReturnPointerVal("eth1")
+	if err := validatePodPortIds(&lab.Spec, "frr-1", validateLinuxIntfName); err == nil {
+		t.Fatalf("expect duplicate port error")
+	}
+	lab.Spec.LinkList["link2"].Connectors[0].PortId = ReturnPointerVal("eth0")
+	if err := validatePodPortIds(&lab.Spec, "frr-1", validateLinuxIntfName); err == nil {
+		t.Fatalf("expect reserved name error")
+	}
+}
+
+func TestSONiCPortConfig(t *testing.T) {
+	if name := sonicLinuxIntfName("Ethernet8"); name != "eth3" {
+		t.Fatalf("expect eth3, got %v", name)
+	}
+	portCfg, laneMap, cfgDB := genSONiCPortConfig("sonic-1", 2)
+	if !strings.Contains(portCfg, "Ethernet4 29,30,31,32 fortyGigE0/4 1 40000") {
+		t.Fatalf("unexpected port_config.ini %v", portCfg)
+	}
+	if laneMap != "eth1:25,26,27,28\neth2:29,30,31,32\n" {
+		t.Fatalf("unexpected lanemap.ini %v", laneMap)
+	}
+	if !strings.Contains(cfgDB, `"Ethernet4"`) || !strings.Contains(cfgDB, `"hostname": "sonic-1"`) {
+		t.Fatalf("unexpected config_db.json %v", cfgDB)
+	}
+}
+
+func TestDummyScript(t *testing.T) {
+	lab := &Lab{
+		ObjectMeta: metav1.ObjectMeta{Name: "lab1", Namespace: "default"},
+		Spec: LabSpec{
+			NodeList: map[string]*OneOfSystem{
+				"dummy-1": {Dummy: &Dummy{Image: ReturnPointerVal(DefaultDummyImage), ReplayPortId: ReturnPointerVal(true)}},
+				"dummy-2": {Dummy: &Dummy{}},
+			},
+			LinkList: map[string]*Link{
+				"link1": {Connectors: []Connector{
+					{NodeName: ReturnPointerVal("dummy-1"), PortId: ReturnPointerVal("1/1/c1/1"),
+						Addrs: []string{"10.1.1.1/24", "2001:db8::1/64"}, Routes: []string{"10.2.0.0/16 via 10.1.1.2", "2001:db8:2::/48 via 2001:db8::2"}},
+					{NodeName: ReturnPointerVal("dummy-2")}}},
+			},
+		},
+	}
+	plab := ParseLab(lab, nil)
+	plab.SpokeMap = map[string]map[string][]string{}
+	plab.SpokeConnectorMap = map[string]*Connector{}
+	plab.SpokeLinkMap = map[string]string{}
+	for i, linkName := range GetSortedKeySlice(lab.Spec.LinkList) {
+		for j, c := range lab.Spec.LinkList[linkName].Connectors {
+			spokeName := getSpokeName(int32(i), j)
+			if plab.SpokeMap[*c.NodeName] == nil {
+				plab.SpokeMap[*c.NodeName] = map[string][]string{}
+			}
+			plab.SpokeMap[*c.NodeName][linkName] = append(plab.SpokeMap[*c.NodeName][linkName], spokeName)
+			plab.SpokeConnectorMap[spokeName] = &c
+			plab.SpokeLinkMap[spokeName] = linkName
+		}
+	}
+	if err := lab.Spec.NodeList["dummy-1"].Dummy.Validate(&lab.Spec, "dummy-1"); err != nil {
+		t.Fatal(err)
+	}
+	for _, replay := range []bool{false, true} {
+		pod := NewBasePod("lab1", "dummy-1", "default", DefaultDummyImage, DummyNode)
+		portName := func(string) string { return "" }
+		expIntf := "eth1"
+		if replay {
+			portName = dummyIntfName
+			expIntf = "1-1-c1-1"
+		}
+		intfs := setPodNetworks(plab, "dummy-1", pod, func(i int) string { return fmt.Sprintf("eth%d", i) }, portName)
+		if strings.Join(intfs, ",") != expIntf {
+			t.Fatalf("unexpected interface names %v", intfs)
+		}
+		script := genDummyScript(plab, "dummy-1", intfs, replay)
+		for _, exp := range []string{
+			"ip link set dev " + expIntf + " up",
+			"ip addr add 2001:db8::1/64 dev " + expIntf,
+			"ip -4 route add 10.2.0.0/16 via 10.1.1.2 dev " + expIntf,
+			"ip -6 route add 2001:db8:2::/48 via 2001:db8::2 dev " + expIntf,
+		} {
+			if !strings.Contains(script, exp) {
+				t.Fatalf("%v not found in script:\n%v", exp, script)
+			}
+		}
+		if strings.Contains(script, "alias") != replay {
+			t.Fatalf("unexpected alias in script:\n%v", script)
+		}
+	}
+}
@@ -0,0 +1,538 @@
+package v1beta1
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	cdiv1 "kubevirt.io/containerized-data-importer-api/pkg/apis/core/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	RestoreCfgMapKey = "cfg.tar.gz"
+	//a marker file with this prefix is created in the config folder after restore,
+	//so the restore is only done once for a given snapshot
+	restoreMarkerPrefix = ".knl-restored-"
+	restoreMountPath    = "/knl-restore"
+	//configmap size limit is 1MiB, leave some room for the metadata
+	maxRestoreCfgSize = 1000 * 1024
+)
+
+var VolumeSnapshotGVK = schema.GroupVersionKind{
+	Group:   "snapshot.storage.k8s.io",
+	Version: "v1",
+	Kind:    "VolumeSnapshot",
+}
+
+// +kubebuilder:object:generate=false
+// +kubebuilder:object:root=false
+// CfgSnapshotSource specifies where the configuration of a node is,
+// either a folder on the file server, or a list of paths in a pod container
+type CfgSnapshotSource struct {
+	//LocalDir is a folder on the file server, the pod fields are ignored if it is not empty
+	LocalDir  string
+	PodName   string
+	Container string
+	//RootDir is the folder in the container that the Paths are relative to
+	RootDir string
+	Paths   []string
+}
+
+// +kubebuilder:object:generate=false
+// +kubebuilder:object:root=false
+// CfgSnapshotSystem is implemented by node types that support config snapshot,
+// VM based nodes other than SR OS VMs don't implement it, their disks could be snapshotted instead
+type CfgSnapshotSystem interface {
+	//GetCfgSnapshotSource returns where the configuration of the node is,
+	//paths is the list of paths specified in the LabSnapshot for the node, could be nil;
+	//return error if paths are not supported, e.g. the files would not be restored
+	GetCfgSnapshotSource(labName, nodeName string, paths []string) (*CfgSnapshotSource, error)
+}
+
+// getCfgSnapshotPaths returns paths relative to rootDir to collect, defPaths if paths is empty;
+// each of paths must be an absolute path under persistDir, the folder persisted and restored in the node
+func getCfgSnapshotPaths(rootDir, persistDir string, paths, defPaths []string) ([]string, error) {
+	if len(paths) == 0 {
+		return defPaths, nil
+	}
+	rlist := []string{}
+	for _, p := range paths {
+		cp := path.Clean(p)
+		if !path.IsAbs(p) || (cp != persistDir && !strings.HasPrefix(cp, persistDir+"/")) {
+			return nil, fmt.Errorf("path %v is not under %v, only files under it are persisted and restored", p, persistDir)
+		}
+		rel := strings.TrimPrefix(strings.TrimPrefix(cp, rootDir), "/")
+		if rel == "" {
+			rel = "."
+		}
+		rlist = append(rlist, rel)
+	}
+	return rlist, nil
+}
+
+// GetCfgSnapshotSource returns where the configuration of nodeName in lab is, with paths specified for the node
+func (spec *LabSnapshotSpec) GetCfgSnapshotSource(lab *Lab, nodeName string) (*CfgSnapshotSource, error) {
+	node, ok := lab.Spec.NodeList[nodeName]
+	if !ok || node == nil {
+		return nil, fmt.Errorf("node %v not found in lab %v", nodeName, lab.Name)
+	}
+	sys, sysName := node.GetSystem()
+	if sys == nil {
+		return nil, fmt.Errorf("node %v doesn't specify any system", nodeName)
+	}
+	cs, ok := sys.(CfgSnapshotSystem)
+	if !ok {
+		return nil, fmt.Errorf("config snapshot is not supported by %v", sysName)
+	}
+	src, err := cs.GetCfgSnapshotSource(lab.Name, nodeName, spec.Paths[nodeName])
+	if err != nil {
+		return nil, fmt.Errorf("invalid paths of node %v, %w", nodeName, err)
+	}
+	return src, nil
+}
+
+// Validate checks nodes exist in lab and paths are supported by their node types
+func (spec *LabSnapshotSpec) Validate(lab *Lab) error {
+	for _, nodeName := range spec.Nodes {
+		if _, ok := lab.Spec.NodeList[nodeName]; !ok {
+			return fmt.Errorf("node %v not found in lab %v", nodeName, lab.Name)
+		}
+	}
+	for _, nodeName := range GetSortedKeySlice(spec.Paths) {
+		if len(spec.Nodes) > 0 && !slices.Contains(spec.Nodes, nodeName) {
+			return fmt.Errorf("paths are specified for node %v that is not in the snapshot", nodeName)
+		}
+		if _, err := spec.GetCfgSnapshotSource(lab, nodeName); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func isRestoreMarker(name string) bool {
+	return strings.HasPrefix(path.Base(name), restoreMarkerPrefix)
+}
+
+// AddTarStream copies all entries in tar stream r into tw with prefix, return number of files copied
+func AddTarStream(tw *tar.Writer, prefix string, r io.Reader) (int, error) {
+	tr := tar.NewReader(r)
+	count := 0
+	for {
+		hdr, err := tr.Next()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return count, nil
+			}
+			return count, err
+		}
+		name := path.Clean(strings.TrimPrefix(hdr.Name, "/"))
+		if name == "." || isRestoreMarker(name) {
+			continue
+		}
+		hdr.Name = path.Join(prefix, name)
+		if hdr.Typeflag == tar.TypeDir {
+			hdr.Name += "/"
+		}
+		if err = tw.WriteHeader(hdr); err != nil {
+			return count, err
+		}
+		if hdr.Typeflag == tar.TypeReg {
+			if _, err = io.Copy(tw, tr); err != nil {
+				return count, err
+			}
+			count++
+		}
+	}
+}
+
+// AddLocalDir adds all files under dir into tw with prefix, return number of files added
+func AddLocalDir(tw *tar.Writer, prefix, dir string) (int, error) {
+	count := 0
+	err := filepath.WalkDir(dir, func(fpath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, fpath)
+		if err != nil {
+			return err
+		}
+		if rel == "." || isRestoreMarker(rel) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() && !info.IsDir() {
+			return nil
+		}
+		hdr, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		hdr.Name = path.Join(prefix, filepath.ToSlash(rel))
+		if info.IsDir() {
+			hdr.Name += "/"
+		}
+		if err = tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		f, err := os.Open(fpath)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		if _, err = io.Copy(tw, f); err != nil {
+			return err
+		}
+		count++
+		return nil
+	})
+	return count, err
+}
+
+// walkNodeCfg calls fn for each entry of nodeName in the archive, with node name prefix removed from the header name;
+// return false if there is no entry for the node
+func walkNodeCfg(archive io.Reader, nodeName string, fn func(hdr *tar.Header, r io.Reader) error) (bool, error) {
+	gzr, err := gzip.NewReader(archive)
+	if err != nil {
+		return false, err
+	}
+	defer gzr.Close()
+	tr := tar.NewReader(gzr)
+	found := false
+	prefix := nodeName + "/"
+	for {
+		hdr, err := tr.Next()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return found, nil
+			}
+			return found, err
+		}
+		if !strings.HasPrefix(hdr.Name, prefix) || hdr.Name == prefix {
+			continue
+		}
+		found = true
+		hdr.Name = strings.TrimPrefix(hdr.Name, prefix)
+		if err = fn(hdr, tr); err != nil {
+			return found, err
+		}
+	}
+}
+
+// limitedBuffer is a bytes.Buffer returns error once more than limit bytes are written
+type limitedBuffer struct {
+	bytes.Buffer
+	limit int
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if b.Len()+len(p) > b.limit {
+		return 0, fmt.Errorf("exceeds %d bytes", b.limit)
+	}
+	return b.Buffer.Write(p)
+}
+
+// ExtractNodeCfg returns entries of nodeName in the archive as a gzipped tar, with node name prefix removed;
+// return nil if there is no entry for the node, error if the gzipped tar is bigger than maxRestoreCfgSize
+func ExtractNodeCfg(archive io.Reader, nodeName string) ([]byte, error) {
+	buf := &limitedBuffer{limit: maxRestoreCfgSize}
+	gzw := gzip.NewWriter(buf)
+	tw := tar.NewWriter(gzw)
+	found, err := walkNodeCfg(archive, nodeName, func(hdr *tar.Header, r io.Reader) error {
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if hdr.Typeflag == tar.TypeReg {
+			if _, err := io.Copy(tw, r); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil || !found {
+		return nil, err
+	}
+	if err = tw.Close(); err != nil {
+		return nil, err
+	}
+	if err = gzw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// restoreToLocalDir extracts entries of nodeName in the archive into dir, without node name prefix,
+// it does nothing if dir has already been restored from the snapshot; the marker is created even if the node is not in the archive
+func restoreToLocalDir(archive io.Reader, nodeName, dir, snapshot string) error {
+	marker := filepath.Join(dir, restoreMarkerPrefix+snapshot)
+	if _, err := os.Stat(marker); err == nil {
+		return nil
+	}
+	_, err := walkNodeCfg(archive, nodeName, func(hdr *tar.Header, r io.Reader) error {
+		target := filepath.Join(dir, filepath.FromSlash(path.Clean("/"+hdr.Name)))
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			return os.MkdirAll(target, 0755)
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
+			f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+			if err != nil {
+				return err
+			}
+			_, err = io.Copy(f, r)
+			f.Close()
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(marker, nil, 0644)
+}
+
+// getRestoreSnapshot returns the completed LabSnapshot that lab restores from, nil if lab doesn't restore from a snapshot;
+// the snapshot is checked by the webhook on lab creation, so a snapshot removed afterwards means nothing to restore
+func (lab *ParsedLab) getRestoreSnapshot(ctx context.Context, clnt client.Client) (*LabSnapshot, error) {
+	if lab.Lab.Spec.RestoreFrom == nil {
+		return nil, nil
+	}
+	snap := new(LabSnapshot)
+	err := clnt.Get(ctx, types.NamespacedName{Namespace: lab.Lab.Namespace, Name: *lab.Lab.Spec.RestoreFrom}, snap)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get snapshot %v, %w", *lab.Lab.Spec.RestoreFrom, err)
+	}
+	if snap.Status.Phase != SnapshotPhaseCompleted {
+		return nil, fmt.Errorf("snapshot %v is not completed", snap.Name)
+	}
+	return snap, nil
+}
+
+// openRestoreArchive opens the archive of the snapshot that lab restores from,
+// return nil if the lab doesn't restore from a snapshot or the snapshot has no archive
+func (lab *ParsedLab) openRestoreArchive(ctx context.Context, clnt client.Client) (*os.File, error) {
+	snap, err := lab.getRestoreSnapshot(ctx, clnt)
+	if err != nil || snap == nil || snap.Status.Archive == "" {
+		return nil, err
+	}
+	f, err := os.Open(snap.Status.Archive)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open archive of snapshot %v, %w", snap.Name, err)
+	}
+	return f, nil
+}
+
+// getRestoreCfg returns the configuration of the node in the snapshot that lab restores from, as a gzipped tar,
+// return nil if the lab doesn't restore from a snapshot or the node is not in the snapshot
+func (lab *ParsedLab) getRestoreCfg(ctx context.Context, clnt client.Client, nodeName string) ([]byte, error) {
+	f, err := lab.openRestoreArchive(ctx, clnt)
+	if err != nil || f == nil {
+		return nil, err
+	}
+	defer f.Close()
+	data, err := ExtractNodeCfg(f, nodeName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config of node %v in snapshot %v, %w", nodeName, *lab.Lab.Spec.RestoreFrom, err)
+	}
+	return data, nil
+}
+
+// restoreLocalDir restores the configuration of the node in the snapshot that lab restores from into dir on the file server,
+// the snapshot is only read if dir has not been restored from it
+func (lab *ParsedLab) restoreLocalDir(ctx context.Context, clnt client.Client, nodeName, dir string) error {
+	if lab.Lab.Spec.RestoreFrom == nil {
+		return nil
+	}
+	marker := filepath.Join(dir, restoreMarkerPrefix+*lab.Lab.Spec.RestoreFrom)
+	if _, err := os.Stat(marker); err == nil {
+		return nil
+	}
+	f, err := lab.openRestoreArchive(ctx, clnt)
+	if err != nil {
+		return err
+	}
+	if f == nil {
+		//nothing to restore, the marker avoids checking the snapshot again
+		return os.WriteFile(marker, nil, 0644)
+	}
+	defer f.Close()
+	if err = restoreToLocalDir(f, nodeName, dir, *lab.Lab.Spec.RestoreFrom); err != nil {
+		return fmt.Errorf("failed to restore config from snapshot %v, %w", *lab.Lab.Spec.RestoreFrom, err)
+	}
+	return nil
+}
+
+// GetVolumeSnapshotKey returns the key of a volume of the lab in LabSnapshot's status.volumes,
+// which is the volume name without lab name prefix, so it could be used by a different lab
+func GetVolumeSnapshotKey(labName, volName string) string {
+	return strings.TrimPrefix(volName, strings.ToLower(labName)+"-")
+}
+
+// NewVolumeSnapshot returns a CSI VolumeSnapshot of the PVC pvcName,
+// unstructured is used to avoid depending on the external-snapshotter client
+func NewVolumeSnapshot(ns, labName, name, pvcName string, snapshotClass *string) *unstructured.Unstructured {
+	r := new(unstructured.Unstructured)
+	r.SetGroupVersionKind(VolumeSnapshotGVK)
+	r.SetNamespace(ns)
+	r.SetName(name)
+	r.SetLabels(map[string]string{
+		K8SLABELAPPKey:   K8SLABELAPPVAL,
+		K8SLABELSETUPKEY: labName,
+	})
+	unstructured.SetNestedField(r.Object, pvcName, "spec", "source", "persistentVolumeClaimName")
+	if snapshotClass != nil {
+		unstructured.SetNestedField(r.Object, *snapshotClass, "spec", "volumeSnapshotClassName")
+	}
+	return r
+}
+
+// setRestoreVolSource sets the data source of a PVC or DataVolume to its VolumeSnapshot in the snapshot that lab restores from,
+// it does nothing if the lab doesn't restore from a snapshot, the volume already exists or it is not in the snapshot
+func (lab *ParsedLab) setRestoreVolSource(ctx context.Context, clnt client.Client, obj client.Object) error {
+	if lab.Lab.Spec.RestoreFrom == nil {
+		return nil
+	}
+	existing := obj.DeepCopyObject().(client.Object)
+	err := clnt.Get(ctx, client.ObjectKeyFromObject(obj), existing)
+	if err == nil {
+		return nil
+	}
+	if !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to get %v, %w", obj.GetName(), err)
+	}
+	snap, err := lab.getRestoreSnapshot(ctx, clnt)
+	if err != nil || snap == nil {
+		return err
+	}
+	vsName, ok := snap.Status.Volumes[GetVolumeSnapshotKey(lab.Lab.Name, obj.GetName())]
+	if !ok {
+		return nil
+	}
+	switch vol := obj.(type) {
+	case *corev1.PersistentVolumeClaim:
+		vol.Spec.DataSource = &corev1.TypedLocalObjectReference{
+			APIGroup: ReturnPointerVal(VolumeSnapshotGVK.Group),
+			Kind:     VolumeSnapshotGVK.Kind,
+			Name:     vsName,
+		}
+	case *cdiv1.DataVolume:
+		vol.Spec.Source = &cdiv1.DataVolumeSource{
+			Snapshot: &cdiv1.DataVolumeSourceSnapshot{
+				Namespace: snap.Namespace,
+				Name:      vsName,
+			},
+		}
+	default:
+		return fmt.Errorf("%v is not a PVC or DataVolume", obj.GetName())
+	}
+	return nil
+}
+
+func getRestoreCfgMapName(labName, nodeName string) string {
+	return strings.ToLower(fmt.Sprintf("%v-%v-restore", labName, nodeName))
+}
+
+// ensureRestoreCfgMap creates a configmap contains the node's configuration in the snapshot that the lab restores from,
+// return nil if there is nothing to restore; the snapshot is only read when the configmap doesn't exist,
+// the configmap is created without data if the node is not in the snapshot
+func (lab *ParsedLab) ensureRestoreCfgMap(ctx context.Context, clnt client.Client, nodeName string, nodeType NodeType) (*corev1.ConfigMap, string, error) {
+	if lab.Lab.Spec.RestoreFrom == nil {
+		return nil, "", nil
+	}
+	cm := new(corev1.ConfigMap)
+	err := clnt.Get(ctx, types.NamespacedName{Namespace: lab.Lab.Namespace, Name: getRestoreCfgMapName(lab.Lab.Name, nodeName)}, cm)
+	if err == nil {
+		if len(cm.BinaryData[RestoreCfgMapKey]) == 0 {
+			return nil, "", nil
+		}
+		return cm, *lab.Lab.Spec.RestoreFrom, nil
+	}
+	if !apierrors.IsNotFound(err) {
+		return nil, "", fmt.Errorf("failed to get restore configmap of node %v, %w", nodeName, err)
+	}
+	data, err := lab.getRestoreCfg(ctx, clnt, nodeName)
+	if err != nil {
+		return nil, "", err
+	}
+	cm = &corev1.ConfigMap{
+		ObjectMeta: GetObjMeta(getRestoreCfgMapName(lab.Lab.Name, nodeName), lab.Lab.Name, lab.Lab.Namespace, nodeName, nodeType),
+	}
+	if data != nil {
+		cm.BinaryData = map[string][]byte{
+			RestoreCfgMapKey: data,
+		}
+	}
+	err = createIfNotExistsOrRemove(ctx, clnt, lab, cm, true, false)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create restore configmap for node %v in lab %v, %w", nodeName, lab.Lab.Name, err)
+	}
+	if data == nil {
+		return nil, "", nil
+	}
+	return cm, *lab.Lab.Spec.RestoreFrom, nil
+}
+
+// getRestoreInitContainer returns an init container and a volume to restore the configuration in restoreCM into targetDir,
+// targetVol is mounted as targetMount, only files extracted into targetMount are persisted;
+// the restore is only done once for the snapshot, a marker file is created in targetMount
+func getRestoreInitContainer(image string, restoreCM *corev1.ConfigMap, snapshot string, targetVol, targetMount, targetDir string) (corev1.Container, corev1.Volume) {
+	marker := path.Join(targetMount, restoreMarkerPrefix+snapshot)
+	cmd := fmt.Sprintf("if [ ! -f %v ]; then mkdir -p %v && tar -xzf %v -C %v && touch %v; fi",
+		marker, targetDir, path.Join(restoreMountPath, RestoreCfgMapKey), targetDir, marker)
+	container := corev1.Container{
+		Name:    "restore-cfg",
+		Image:   image,
+		Command: []string{"sh", "-c", cmd},
+		SecurityContext: &corev1.SecurityContext{
+			RunAsUser:  ReturnPointerVal(int64(0)),
+			RunAsGroup: ReturnPointerVal(int64(0)),
+		},
+		VolumeMounts: []corev1.VolumeMount{
+			{
+				Name:      "restore",
+				MountPath: restoreMountPath,
+			},
+			{
+				Name:      targetVol,
+				MountPath: targetMount,
+			},
+		},
+	}
+	vol := corev1.Volume{
+		Name: "restore",
+		VolumeSource: corev1.VolumeSource{
+			ConfigMap: &corev1.ConfigMapVolumeSource{
+				LocalObjectReference: corev1.LocalObjectReference{
+					Name: restoreCM.Name,
+				},
+			},
+		},
+	}
+	return container, vol
+}
@@ -0,0 +1,260 @@
+package v1beta1
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestSnapshotArchive(t *testing.T) {
+	srcDir := t.TempDir()
+	os.MkdirAll(filepath.Join(srcDir, "sub"), 0755)
+	os.WriteFile(filepath.Join(srcDir, "config.cfg"), []byte("cfg1"), 0644)
+	os.WriteFile(filepath.Join(srcDir, "sub", "a.txt"), []byte("a"), 0644)
+	os.WriteFile(filepath.Join(srcDir, restoreMarkerPrefix+"old"), nil, 0644)
+
+	archive := new(bytes.Buffer)
+	gzw := gzip.NewWriter(archive)
+	tw := tar.NewWriter(gzw)
+	count, err := AddLocalDir(tw, "vsim-1", srcDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 2 {
+		t.Fatalf("expect 2 files collected, got %d", count)
+	}
+	//a tar stream from pod
+	podTar := new(bytes.Buffer)
+	ptw := tar.NewWriter(podTar)
+	ptw.WriteHeader(&tar.Header{Name: "./", Typeflag: tar.TypeDir, Mode: 0755})
+	ptw.WriteHeader(&tar.Header{Name: "./config.json", Typeflag: tar.TypeReg, Mode: 0644, Size: 2})
+	ptw.Write([]byte("{}"))
+	ptw.Close()
+	count, err = AddTarStream(tw, "srl-1", podTar)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Fatalf("expect 1 file copied, got %d", count)
+	}
+	tw.Close()
+	gzw.Close()
+
+	data, err := ExtractNodeCfg(bytes.NewReader(archive.Bytes()), "srl-1")
+	if err != nil || data == nil {
+		t.Fatalf("expect config of srl-1, %v", err)
+	}
+	dstDir := t.TempDir()
+	if err = restoreToLocalDir(bytes.NewReader(archive.Bytes()), "vsim-1", dstDir, "snap1"); err != nil {
+		t.Fatal(err)
+	}
+	for fname, expect := range map[string]string{"config.cfg": "cfg1", "sub/a.txt": "a"} {
+		buf, err := os.ReadFile(filepath.Join(dstDir, fname))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(buf) != expect {
+			t.Fatalf("%v expect %v, got %v", fname, expect, string(buf))
+		}
+	}
+	if _, err = os.Stat(filepath.Join(dstDir, restoreMarkerPrefix+"old")); err == nil {
+		t.Fatalf("restore marker should not be in the archive")
+	}
+	//restore is only done once
+	os.WriteFile(filepath.Join(dstDir, "config.cfg"), []byte("changed"), 0644)
+	if err = restoreToLocalDir(bytes.NewReader(archive.Bytes()), "vsim-1", dstDir, "snap1"); err != nil {
+		t.Fatal(err)
+	}
+	buf, _ := os.ReadFile(filepath.Join(dstDir, "config.cfg"))
+	if string(buf) != "changed" {
+		t.Fatalf("config should not be restored twice")
+	}
+
+	data, err = ExtractNodeCfg(bytes.NewReader(archive.Bytes()), "srl-2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data != nil {
+		t.Fatalf("expect nil for node not in the archive")
+	}
+
+	//config of a node bigger than a configmap can hold
+	archive.Reset()
+	gzw = gzip.NewWriter(archive)
+	tw = tar.NewWriter(gzw)
+	big := make([]byte, maxRestoreCfgSize+1)
+	rand.Read(big)
+	tw.WriteHeader(&tar.Header{Name: "srl-1/big", Typeflag: tar.TypeReg, Mode: 0644, Size: int64(len(big))})
+	tw.Write(big)
+	tw.Close()
+	gzw.Close()
+	if _, err = ExtractNodeCfg(bytes.NewReader(archive.Bytes()), "srl-1"); err == nil {
+		t.Fatalf("expect error for config too big to restore")
+	}
+}
+
+func TestSRVMCfgSnapshot(t *testing.T) {
+	for _, nodeName := range []string{"vsim-1", "vsri-1", "magc-1-a"} {
+		sys := GetNewSystemViaName(nodeName)
+		if sys == nil {
+			t.Fatalf("failed to get system of %v", nodeName)
+		}
+		cs, ok := sys.(CfgSnapshotSystem)
+		if !ok {
+			t.Fatalf("%T doesn't implement CfgSnapshotSystem", sys)
+		}
+		if src, err := cs.GetCfgSnapshotSource("lab1", nodeName, nil); err != nil || src.LocalDir != GetSRConfigFTPSubFolder("lab1", nodeName) {
+			t.Fatalf("unexpected snapshot source %+v of %v, %v", src, nodeName, err)
+		}
+		if _, err := cs.GetCfgSnapshotSource("lab1", nodeName, []string{"/cf3/config.cfg"}); err == nil {
+			t.Fatalf("expect error for paths of %v", nodeName)
+		}
+	}
+}
+
+func TestRestoreOnlyOnCreation(t *testing.T) {
+	archive := filepath.Join(t.TempDir(), "v1-snap1.tar.gz")
+	f, _ := os.Create(archive)
+	gzw := gzip.NewWriter(f)
+	tw := tar.NewWriter(gzw)
+	tw.WriteHeader(&tar.Header{Name: "r1/frr.conf", Typeflag: tar.TypeReg, Mode: 0644, Size: 2})
+	tw.Write([]byte("!\n"))
+	tw.Close()
+	gzw.Close()
+	f.Close()
+
+	sch := runtime.NewScheme()
+	clientgoscheme.AddToScheme(sch)
+	AddToScheme(sch)
+	snap := &LabSnapshot{ObjectMeta: metav1.ObjectMeta{Name: "snap1", Namespace: "default"}}
+	snap.Status = LabSnapshotStatus{Phase: SnapshotPhaseCompleted, Archive: archive, Volumes: map[string]string{"r1-etc": "snap1-r1-etc"}}
+	clnt := fake.NewClientBuilder().WithScheme(sch).WithObjects(snap).Build()
+	lab := newTestCfgPodLab(map[string]*OneOfSystem{"r1": {FRR: &FRR{}}, "r2": {FRR: &FRR{}}})
+	lab.Spec.RestoreFrom = ReturnPointerVal("snap1")
+	plab := newTestParsedLab(lab, sch)
+	ctx := context.Background()
+
+	cm, snapshot, err := plab.ensureRestoreCfgMap(ctx, clnt, "r1", FRRNode)
+	if err != nil || cm == nil || snapshot != "snap1" {
+		t.Fatalf("expect restore configmap of r1, got %v, %v", cm, err)
+	}
+	//r2 is not in the snapshot, an empty configmap is created so the archive is not read again
+	if cm, _, err = plab.ensureRestoreCfgMap(ctx, clnt, "r2", FRRNode); err != nil || cm != nil {
+		t.Fatalf("expect nothing to restore for r2, got %v, %v", cm, err)
+	}
+	pvc := (&cfgPodSpec{NodeType: FRRNode, PVCSuffix: "etc"}).getPVC("default", "r1", "lab1", lab.Spec.PVCStorageClass)
+	if err = plab.setRestoreVolSource(ctx, clnt, pvc); err != nil || pvc.Spec.DataSource == nil || pvc.Spec.DataSource.Name != "snap1-r1-etc" {
+		t.Fatalf("unexpected restore source of pvc %+v, %v", pvc.Spec.DataSource, err)
+	}
+	if err = clnt.Create(ctx, pvc); err != nil {
+		t.Fatal(err)
+	}
+
+	//snapshot and its archive are removed after the lab is created
+	os.Remove(archive)
+	if err = clnt.Delete(ctx, snap); err != nil {
+		t.Fatal(err)
+	}
+	if cm, snapshot, err = plab.ensureRestoreCfgMap(ctx, clnt, "r1", FRRNode); err != nil || cm == nil || snapshot != "snap1" {
+		t.Fatalf("expect existing restore configmap of r1, got %v, %v", cm, err)
+	}
+	if cm, _, err = plab.ensureRestoreCfgMap(ctx, clnt, "r2", FRRNode); err != nil || cm != nil {
+		t.Fatalf("expect nothing to restore for r2, got %v, %v", cm, err)
+	}
+	pvc = (&cfgPodSpec{NodeType: FRRNode, PVCSuffix: "etc"}).getPVC("default", "r1", "lab1", lab.Spec.PVCStorageClass)
+	if err = plab.setRestoreVolSource(ctx, clnt, pvc); err != nil || pvc.Spec.DataSource != nil {
+		t.Fatalf("existing pvc should not be restored, %+v, %v", pvc.Spec.DataSource, err)
+	}
+	if cm, _, err = plab.ensureRestoreCfgMap(ctx, clnt, "r3", FRRNode); err != nil || cm != nil {
+		t.Fatalf("expect nothing to restore after snapshot is removed, got %v, %v", cm, err)
+	}
+
+	//snapshot is not completed yet for a new node
+	snap = &LabSnapshot{ObjectMeta: metav1.ObjectMeta{Name: "snap1", Namespace: "default"}}
+	snap.Status.Phase = SnapshotPhaseInProgress
+	if err = clnt.Create(ctx, snap); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err = plab.ensureRestoreCfgMap(ctx, clnt, "r4", FRRNode); err == nil {
+		t.Fatalf("expect error for snapshot not completed")
+	}
+}
+
+func TestRestoreLocalDir(t *testing.T) {
+	sch := runtime.NewScheme()
+	clientgoscheme.AddToScheme(sch)
+	AddToScheme(sch)
+	clnt := fake.NewClientBuilder().WithScheme(sch).Build()
+	lab := newTestCfgPodLab(map[string]*OneOfSystem{"vsim-1": {}})
+	lab.Spec.RestoreFrom = ReturnPointerVal("snap1")
+	plab := newTestParsedLab(lab, sch)
+	dir := t.TempDir()
+	//snapshot is removed, nothing to restore and the marker is created
+	if err := plab.restoreLocalDir(context.Background(), clnt, "vsim-1", dir); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, restoreMarkerPrefix+"snap1")); err != nil {
+		t.Fatalf("expect restore marker, %v", err)
+	}
+}
+
+func TestCfgSnapshotPaths(t *testing.T) {
+	lab := &Lab{ObjectMeta: metav1.ObjectMeta{Name: "lab1", Namespace: "default"}}
+	lab.Spec.NodeList = map[string]*OneOfSystem{
+		"pod1":  {Pod: &GeneralPod{}},
+		"r1":    {CEOS: &CEOS{}},
+		"xr1":   {XRd: &XRd{}},
+		"vm1":   {VM: &GeneralVM{}},
+		"dummy": {Dummy: &Dummy{}},
+	}
+	spec := &LabSnapshotSpec{Paths: map[string][]string{
+		"pod1": {"/root/.bashrc", "/root/"},
+		"r1":   {"/mnt/flash"},
+		"xr1":  {"/xr-storage/config"},
+	}}
+	if err := spec.Validate(lab); err != nil {
+		t.Fatal(err)
+	}
+	for nodeName, expect := range map[string]string{"pod1": "root/.bashrc,root", "r1": ".", "xr1": "config"} {
+		src, err := spec.GetCfgSnapshotSource(lab, nodeName)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Join(src.Paths, ",") != expect {
+			t.Fatalf("expect paths %v of %v, got %v", expect, nodeName, src.Paths)
+		}
+	}
+	//files outside of the persisted folder are not restored
+	for nodeName, paths := range map[string][]string{
+		"pod1": {"/etc/hosts"},
+		"r1":   {"/mnt/flash-other/a"},
+		"xr1":  {"config"},
+		"vm1":  {"/root"},
+	} {
+		spec = &LabSnapshotSpec{Paths: map[string][]string{nodeName: paths}}
+		if err := spec.Validate(lab); err == nil {
+			t.Fatalf("expect error for paths %v of %v", paths, nodeName)
+		}
+	}
+	//xrd requires paths, vm and dummy don't support config snapshot
+	for _, nodeName := range []string{"xr1", "vm1", "dummy"} {
+		if _, err := (&LabSnapshotSpec{}).GetCfgSnapshotSource(lab, nodeName); err == nil {
+			t.Fatalf("expect error for %v", nodeName)
+		}
+	}
+	spec = &LabSnapshotSpec{Nodes: []string{"r1"}, Paths: map[string][]string{"pod1": {"/root"}}}
+	if err := spec.Validate(lab); err == nil {
+		t.Fatalf("expect error for paths of node not in the snapshot")
+	}
+}
@@ -0,0 +1,222 @@
+package v1beta1
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"syscall"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func init() {
+	NewSysRegistry[SONiCNode] = func() System { return new(SONiC) }
+}
+
+const (
+	SONiCNode         NodeType = "sonic"
+	DefaultSONiCMem   string   = "2Gi"
+	SONiCEtcDir       string   = "/etc/sonic"
+	SONiCCfgDBKey     string   = "config_db.json"
+	sonicPortCfgKey   string   = "port_config.ini"
+	sonicLaneMapKey   string   = "lanemap.ini"
+	sonicPlatform     string   = "x86_64-kvm_x86_64-r0"
+	sonicHWSKU        string   = "Force10-S6000"
+	sonicLanesPerPort int      = 4
+	sonicFirstLane    int      = 25
+	sonicPortSpeed    string   = "40000"
+	sonicCfgMountPath string   = "/knl-cfg"
+)
+
+var sonicPortRegex = regexp.MustCompile(`^Ethernet([0-9]+)$`)
+
+// SONiC specifies a SONiC virtual switch using sonic-vs container image
+type SONiC struct {
+	//sonic-vs container image
+	Image *string `json:"image,omitempty"`
+	//a k8s configmap in the lab namespace with key "config_db.json",
+	//it is copied into /etc/sonic/config_db.json on first boot; a config_db.json with all ports is generated if not specified
+	// +optional
+	// +nullable
+	StartupConfig *string `json:"startupConfig,omitempty"`
+	//requested memory in k8s resource unit
+	// +optional
+	// +nullable
+	ReqMemory *resource.Quantity `json:"memory,omitempty"`
+	//requested cpu in k8s resource unit
+	// +optional
+	// +nullable
+	ReqCPU *resource.Quantity `json:"cpu,omitempty"`
+}
+
+func (sonic *SONiC) SetToAppDefVal() {
+	sonic.ReqMemory = ReturnPointerVal(resource.MustParse(DefaultSONiCMem))
+}
+
+func (sonic *SONiC) FillDefaultVal(nodeName string) {
+
+}
+
+func (sonic *SONiC) Validate(lab *LabSpec, nodeName string) error {
+	if sonic.Image == nil {
+		return fmt.Errorf("image not specified")
+	}
+	return validatePodPortIds(lab, nodeName, func(portId string) error {
+		m := sonicPortRegex.FindStringSubmatch(portId)
+		if m == nil {
+			return fmt.Errorf("expect EthernetN")
+		}
+		if n, _ := strconv.Atoi(m[1]); n%sonicLanesPerPort != 0 {
+			return fmt.Errorf("N must be multiple of %d", sonicLanesPerPort)
+		}
+		return nil
+	})
+}
+
+// sonicLinuxIntfName returns linux interface name of front panel port, EthernetN is eth(N/4+1)
+func sonicLinuxIntfName(portId string) string {
+	n, _ := strconv.Atoi(sonicPortRegex.FindStringSubmatch(portId)[1])
+	return fmt.Sprintf("eth%d", n/sonicLanesPerPort+1)
+}
+
+// genSONiCPortConfig returns port_config.ini, lanemap.ini and default config_db.json of numOfPorts front panel ports
+func genSONiCPortConfig(hostname string, numOfPorts int) (string, string, string) {
+	portCfg := "# name lanes alias index speed\n"
+	laneMap := ""
+	portTable := make(map[string]map[string]string)
+	for i := 0; i < numOfPorts; i++ {
+		lanes := []string{}
+		for l := 0; l < sonicLanesPerPort; l++ {
+			lanes = append(lanes, strconv.Itoa(sonicFirstLane+i*sonicLanesPerPort+l))
+		}
+		name := fmt.Sprintf("Ethernet%d", i*sonicLanesPerPort)
+		alias := fmt.Sprintf("fortyGigE0/%d", i*sonicLanesPerPort)
+		portCfg += fmt.Sprintf("%v %v %v %d %v\n", name, strings.Join(lanes, ","), alias, i, sonicPortSpeed)
+		laneMap += fmt.Sprintf("eth%d:%v\n", i+1, strings.Join(lanes, ","))
+		portTable[name] = map[string]string{
+			"lanes":        strings.Join(lanes, ","),
+			"alias":        alias,
+			"index":        strconv.Itoa(i),
+			"speed":        sonicPortSpeed,
+			"admin_status": "up",
+			"mtu":          "9100",
+		}
+	}
+	cfgDB := map[string]any{
+		"DEVICE_METADATA": map[string]any{
+			"localhost": map[string]string{
+				"hostname": hostname,
+				"hwsku":    sonicHWSKU,
+				"platform": sonicPlatform,
+				"type":     "LeafRouter",
+			},
+		},
+		"PORT": portTable,
+	}
+	buf, _ := json.MarshalIndent(cfgDB, "", "  ")
+	return portCfg, laneMap, string(buf)
+}
+
+func (sonic *SONiC) Ensure(ctx context.Context, nodeName string, clnt client.Client, forceRemoval bool) error {
+	lab, err := getParsedLab(ctx)
+	if err != nil {
+		return err
+	}
+	hwskuCMName := fmt.Sprintf("%v-%v-hwsku", lab.Lab.Name, nodeName)
+	//init-container copies the startup config, generated config and the image defaults without overwriting existing files,
+	//so the saved config in pvc is kept across restarts
+	pod, intfList, err := lab.newCfgPod(ctx, clnt, nodeName, &cfgPodSpec{
+		NodeType:      SONiCNode,
+		Image:         *sonic.Image,
+		PVCSuffix:     "etc",
+		PVCSize:       resource.MustParse(EtcPVCSize),
+		MountPath:     SONiCEtcDir,
+		StartupConfig: sonic.StartupConfig,
+		InitCmds: []string{
+			copyStartupCfgCmd(SONiCCfgDBKey),
+			fmt.Sprintf("cp -n %v/%v %v/", sonicCfgMountPath, SONiCCfgDBKey, cfgPodInitMountPath),
+			fmt.Sprintf("cp -rn %v/. %v/", SONiCEtcDir, cfgPodInitMountPath),
+		},
+		InitMounts: []corev1.VolumeMount{
+			{
+				Name:      "hwsku",
+				MountPath: sonicCfgMountPath,
+			},
+		},
+		//front panel port EthernetN is linux interface eth(N/4+1)
+		AutoIntfName: func(i int) string { return fmt.Sprintf("eth%d", i) },
+		PortIntfName: sonicLinuxIntfName,
+		ReqCPU:       sonic.ReqCPU,
+		ReqMemory:    sonic.ReqMemory,
+	})
+	if err != nil {
+		return err
+	}
+	numOfPorts := 1
+	for _, intf := range intfList {
+		if n, _ := strconv.Atoi(strings.TrimPrefix(intf, "eth")); n > numOfPorts {
+			numOfPorts = n
+		}
+	}
+	//create configmap for the hwsku
+	portCfg, laneMap, cfgDB := genSONiCPortConfig(nodeName, numOfPorts)
+	hwskuCM := &corev1.ConfigMap{
+		ObjectMeta: GetObjMeta(hwskuCMName, lab.Lab.Name, lab.Lab.Namespace, nodeName, SONiCNode),
+		Data: map[string]string{
+			sonicPortCfgKey: portCfg,
+			sonicLaneMapKey: laneMap,
+			SONiCCfgDBKey:   cfgDB,
+		},
+	}
+	err = createIfNotExistsOrRemove(ctx, clnt, lab, hwskuCM, true, false)
+	if err != nil {
+		return fmt.Errorf("failed to create hwsku configmap for SONiC %v in lab %v, %w", nodeName, lab.Lab.Name, err)
+	}
+	hwskuDir := fmt.Sprintf("/usr/share/sonic/device/%v/%v", sonicPlatform, sonicHWSKU)
+	addCfgMapVolume(pod, &pod.Spec.Containers[0], "hwsku", hwskuCMName, hwskuDir+"/"+sonicPortCfgKey, sonicPortCfgKey)
+	pod.Spec.Containers[0].VolumeMounts = append(pod.Spec.Containers[0].VolumeMounts, corev1.VolumeMount{
+		Name:      "hwsku",
+		MountPath: hwskuDir + "/" + sonicLaneMapKey,
+		SubPath:   sonicLaneMapKey,
+	})
+	err = createIfNotExistsOrRemove(ctx, clnt, lab, pod, true, false)
+	if err != nil {
+		return fmt.Errorf("failed to create SONiC pod %v in lab %v, %w", nodeName, lab.Lab.Name, err)
+	}
+	return nil
+}
+
+// GetCfgSnapshotSource implements CfgSnapshotSystem interface, config_db.json is collected
+func (sonic *SONiC) GetCfgSnapshotSource(labName, nodeName string, paths []string) (*CfgSnapshotSource, error) {
+	plist, err := getCfgSnapshotPaths(SONiCEtcDir, SONiCEtcDir, paths, []string{SONiCCfgDBKey})
+	if err != nil {
+		return nil, err
+	}
+	return &CfgSnapshotSource{
+		PodName:   GetPodName(labName, nodeName),
+		Container: "main",
+		RootDir:   SONiCEtcDir,
+		Paths:     plist,
+	}, nil
+}
+
+// Shell runs bash in the pod, where SONiC CLI like show and config are available
+func (sonic *SONiC) Shell(ctx context.Context, clnt client.Client, ns, lab, chassis, username string) {
+	envList := []string{fmt.Sprintf("HOME=%v", os.Getenv("HOME"))}
+	fmt.Printf("connecting to %v\n", GetPodName(lab, chassis))
+	syscall.Exec("/bin/sh",
+		[]string{"sh", "-c",
+			fmt.Sprintf("kubectl -n %v exec -it %v -- bash",
+				ns, GetPodName(lab, chassis))},
+		envList)
+}
+
+func (sonic *SONiC) Console(ctx context.Context, clnt client.Client, ns, lab, chassis string) {
+	sonic.Shell(ctx, clnt, ns, lab, chassis, "")
+}
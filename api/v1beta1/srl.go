@@ -11,7 +11,6 @@ import (
 	"syscall"
 
 	"github.com/goccy/go-yaml"
-	k8slan "github.com/hujun-open/k8slan/api/v1beta1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/types"
@@ -173,14 +172,13 @@ type SRLChassis struct {
 	SlotConfig           map[int]slotConfig   `yaml:"slot_configuration"`
 }
 
-func (srl *SRLinux) getEtcPVC(ns, nodeName, labName string) *corev1.PersistentVolumeClaim {
-	gconf := GCONF.Get()
+func (srl *SRLinux) getEtcPVC(ns, nodeName, labName string, storageClass *string) *corev1.PersistentVolumeClaim {
 	name := fmt.Sprintf("%v-%v-etc", labName, nodeName)
 	return &corev1.PersistentVolumeClaim{
 		ObjectMeta: GetObjMeta(name, labName, ns, nodeName, SRL),
 		Spec: corev1.PersistentVolumeClaimSpec{
 			AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOncePod},
-			StorageClassName: GetPointerVal(*gconf.PVCStorageClass),
+			StorageClassName: GetPointerVal(*storageClass),
 			Resources: corev1.VolumeResourceRequirements{
 				Requests: map[corev1.ResourceName]resource.Quantity{
 					corev1.ResourceStorage: resource.MustParse(EtcPVCSize),
@@ -229,7 +227,11 @@ func (srl *SRLinux) Ensure(ctx context.Context, nodeName string, clnt client.Cli
 		return fmt.Errorf("failed to create topology configmap for SRL %v in lab %v, %w", nodeName, lab.Lab.Name, err)
 	}
 	//create PVC for etc
-	etcPVC := srl.getEtcPVC(lab.Lab.Namespace, nodeName, lab.Lab.Name)
+	etcPVC := srl.getEtcPVC(lab.Lab.Namespace, nodeName, lab.Lab.Name, lab.Lab.GetConfig().PVCStorageClass)
+	err = lab.setRestoreVolSource(ctx, clnt, etcPVC)
+	if err != nil {
+		return err
+	}
 	err = createIfNotExistsOrRemove(ctx, clnt, lab, etcPVC, false, false)
 	if err != nil {
 		return fmt.Errorf("failed to create etc pvc for SRL %v in lab %v, %w", nodeName, lab.Lab.Name, err)
@@ -259,6 +261,17 @@ func (srl *SRLinux) Ensure(ctx context.Context, nodeName string, clnt client.Cli
 			},
 		},
 	}
+	//restore config from snapshot into the persisted etc before it is synced to emptyDir
+	restoreCM, snapshot, err := lab.ensureRestoreCfgMap(ctx, clnt, nodeName, SRL)
+	if err != nil {
+		return err
+	}
+	var restoreVol corev1.Volume
+	if restoreCM != nil {
+		var restoreContainer corev1.Container
+		restoreContainer, restoreVol = getRestoreInitContainer(*srl.Image, restoreCM, snapshot, "persis-etc", "/persis-etc/", "/persis-etc")
+		pod.Spec.InitContainers = append(pod.Spec.InitContainers, restoreContainer)
+	}
 	pod.Spec.InitContainers = append(pod.Spec.InitContainers, initContainer)
 	pod.Spec.Containers[0].Command = []string{"/tini", "--", "fixuid", "-q", "/entrypoint.sh", "sudo", "bash", "/opt/srlinux/bin/sr_linux"}
 	pod.Spec.Containers[0].SecurityContext = &corev1.SecurityContext{
@@ -316,6 +329,9 @@ func (srl *SRLinux) Ensure(ctx context.Context, nodeName string, clnt client.Cli
 			},
 		},
 	}
+	if restoreCM != nil {
+		pod.Spec.Volumes = append(pod.Spec.Volumes, restoreVol)
+	}
 	//add lic if specified
 	if srl.LicSecret != nil {
 		pod.Spec.Containers[0].VolumeMounts = append(pod.Spec.Containers[0].VolumeMounts, corev1.VolumeMount{
@@ -341,16 +357,15 @@ func (srl *SRLinux) Ensure(ctx context.Context, nodeName string, clnt client.Cli
 		spokes := lab.SpokeMap[nodeName][linkName]
 		// for _, spokes := range lab.SpokeMap[nodeName] {
 		for _, spokeName := range spokes {
-			lanName := Getk8lanName(lab.Lab.Name, lab.SpokeLinkMap[spokeName])
-			nadName := k8slan.GetDefNADName(lanName, spokeName, true)
+			nadName, resKey := lab.getPodSpokeNAD(spokeName, false)
 			if lab.SpokeConnectorMap[spokeName].PortId != nil {
 				netStr += fmt.Sprintf("%v@%v,", nadName, *lab.SpokeConnectorMap[spokeName].PortId)
 			} else {
 				netStr += fmt.Sprintf("%v@e1-%d,", nadName, i)
 			}
-			resName := k8slan.GetDPResouceName(lanName, spokeName, true)
-			resKey := fmt.Sprintf("%v/%v", K8sLANResKeyPrefix, resName)
-			pod.Spec.Containers[0].Resources.Limits[corev1.ResourceName(resKey)] = resource.MustParse("1")
+			if resKey != "" {
+				pod.Spec.Containers[0].Resources.Limits[corev1.ResourceName(resKey)] = resource.MustParse("1")
+			}
 			i += 1
 		}
 
@@ -361,6 +376,7 @@ func (srl *SRLinux) Ensure(ctx context.Context, nodeName string, clnt client.Cli
 			MultusAnnoKey: netStr,
 		}
 	}
+	lab.setLocalAffinity(nodeName, &pod.ObjectMeta, &pod.Spec.Affinity)
 	//add resource request
 	pod.Spec.Containers[0].Resources.Requests = make(corev1.ResourceList)
 	if srl.ReqCPU != nil {
@@ -378,6 +394,21 @@ func (srl *SRLinux) Ensure(ctx context.Context, nodeName string, clnt client.Cli
 
 }
 
+// GetCfgSnapshotSource implements CfgSnapshotSystem interface,
+// the config is collected from the running etc folder, since the persisted etc is only synced when pod stops
+func (srl *SRLinux) GetCfgSnapshotSource(labName, nodeName string, paths []string) (*CfgSnapshotSource, error) {
+	plist, err := getCfgSnapshotPaths("/etc/opt/srlinux", "/etc/opt/srlinux", paths, []string{"."})
+	if err != nil {
+		return nil, err
+	}
+	return &CfgSnapshotSource{
+		PodName:   GetPodName(labName, nodeName),
+		Container: "main",
+		RootDir:   "/etc/opt/srlinux",
+		Paths:     plist,
+	}, nil
+}
+
 func (srl *SRLinux) Shell(ctx context.Context, clnt client.Client, ns, lab, chassis, username string) {
 	pod := &corev1.Pod{}
 	podKey := types.NamespacedName{Namespace: ns, Name: GetPodName(lab, chassis)}
@@ -0,0 +1,156 @@
+package v1beta1
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// SROSImageFiles are files a SR OS release folder must have
+var SROSImageFiles = []string{"i386-boot.tim", "i386-iom.tim", "both.tim"}
+
+// GetSROSImageFolder returns the release folder path on the file server
+func GetSROSImageFolder(release string) string {
+	return filepath.Join("/"+KNLROOTName, IMGSubFolder, release)
+}
+
+// ExtractSROSImage extracts release tarball r into dir, r could be tar or gzipped tar
+func ExtractSROSImage(r io.Reader, dir string) error {
+	br := bufio.NewReader(r)
+	var tr *tar.Reader
+	//check gzip magic
+	if magic, err := br.Peek(2); err == nil && bytes.Equal(magic, []byte{0x1f, 0x8b}) {
+		gzr, err := gzip.NewReader(br)
+		if err != nil {
+			return err
+		}
+		defer gzr.Close()
+		tr = tar.NewReader(gzr)
+	} else {
+		tr = tar.NewReader(br)
+	}
+	for {
+		hdr, err := tr.Next()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		target := filepath.Join(dir, filepath.FromSlash(path.Clean("/"+hdr.Name)))
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err = os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err = os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
+			f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+			if err != nil {
+				return err
+			}
+			_, err = io.Copy(f, tr)
+			f.Close()
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// FindSROSImageRoot returns the folder under dir that contains i386-boot.tim,
+// release tarball might have the files in a sub folder
+func FindSROSImageRoot(dir string) (string, error) {
+	root := ""
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && d.Name() == SROSImageFiles[0] {
+			root = filepath.Dir(p)
+			return fs.SkipAll
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	if root == "" {
+		return "", fmt.Errorf("%v not found in the release", SROSImageFiles[0])
+	}
+	return root, nil
+}
+
+func fileSHA256(fname string) (string, error) {
+	f, err := os.Open(fname)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err = io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// VerifySROSImage checks release folder dir has all SROSImageFiles,
+// and files listed in checksums match the expected sha256;
+// return sha256 of SROSImageFiles
+func VerifySROSImage(dir string, checksums map[string]string) (map[string]string, error) {
+	r := make(map[string]string)
+	for _, fname := range SROSImageFiles {
+		sum, err := fileSHA256(filepath.Join(dir, fname))
+		if err != nil {
+			return nil, fmt.Errorf("required file %v is missing, %w", fname, err)
+		}
+		r[fname] = sum
+	}
+	for _, fname := range GetSortedKeySlice(checksums) {
+		sum, ok := r[fname]
+		if !ok {
+			var err error
+			sum, err = fileSHA256(filepath.Join(dir, filepath.FromSlash(path.Clean("/"+fname))))
+			if err != nil {
+				return nil, fmt.Errorf("failed to checksum %v, %w", fname, err)
+			}
+		}
+		if !strings.EqualFold(sum, checksums[fname]) {
+			return nil, fmt.Errorf("checksum mismatch for %v, expect %v, got %v", fname, checksums[fname], sum)
+		}
+	}
+	return r, nil
+}
+
+// Validate checks one and only one source is specified
+func (spec *SROSImageSpec) Validate() error {
+	if (spec.URL == nil) == (spec.PVC == nil) {
+		return fmt.Errorf("one and only one of url or pvc must be specified")
+	}
+	if spec.URL != nil && !strings.HasPrefix(*spec.URL, "http://") && !strings.HasPrefix(*spec.URL, "https://") {
+		return fmt.Errorf("%v is not a http or https url", *spec.URL)
+	}
+	if spec.PVC != nil && (spec.PVC.Namespace == "" || spec.PVC.Name == "" || spec.PVC.Path == "") {
+		return fmt.Errorf("namespace, name and path of pvc must be specified")
+	}
+	return nil
+}
+
+// GetConfig returns KNLConfig used to ingest the release
+func (img *SROSImage) GetConfig() KNLConfigSpec {
+	r, _ := GCONF.Lookup(getConfigName(img.Spec.Config))
+	return r
+}
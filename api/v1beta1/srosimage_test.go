@@ -0,0 +1,46 @@
+package v1beta1
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+)
+
+func TestSROSImageIngest(t *testing.T) {
+	buf := new(bytes.Buffer)
+	gzw := gzip.NewWriter(buf)
+	tw := tar.NewWriter(gzw)
+	tw.WriteHeader(&tar.Header{Name: "TiMOS-SR-25.10.R1/", Typeflag: tar.TypeDir, Mode: 0755})
+	for _, fname := range SROSImageFiles {
+		tw.WriteHeader(&tar.Header{Name: "TiMOS-SR-25.10.R1/" + fname, Typeflag: tar.TypeReg, Mode: 0644, Size: int64(len(fname))})
+		tw.Write([]byte(fname))
+	}
+	tw.Close()
+	gzw.Close()
+
+	dir := t.TempDir()
+	if err := ExtractSROSImage(bytes.NewReader(buf.Bytes()), dir); err != nil {
+		t.Fatal(err)
+	}
+	root, err := FindSROSImageRoot(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256([]byte("both.tim"))
+	files, err := VerifySROSImage(root, map[string]string{"both.tim": hex.EncodeToString(sum[:])})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != len(SROSImageFiles) {
+		t.Fatalf("expect %d checksums, got %d", len(SROSImageFiles), len(files))
+	}
+	if _, err = VerifySROSImage(root, map[string]string{"both.tim": "00"}); err == nil {
+		t.Fatalf("expect checksum mismatch")
+	}
+	if _, err = VerifySROSImage(dir, nil); err == nil {
+		t.Fatalf("expect missing required files")
+	}
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SROSImageSpec specifies where to get a SR OS release tarball,
+// the release is extracted into the release folder on the file server named after the SROSImage,
+// and could be used by SRVM node via image "filesvr:<name>"
+type SROSImageSpec struct {
+	//HTTP(S) URL of the release tarball, tar or gzipped tar;
+	//one of url or pvc must be specified
+	// +optional
+	// +nullable
+	URL *string `json:"url,omitempty"`
+	//PVC contains the release tarball
+	// +optional
+	// +nullable
+	PVC *SROSImagePVCSource `json:"pvc,omitempty"`
+	//expected sha256 checksum of files in the release, key is the file name relative to the release folder, e.g. "both.tim"
+	// +optional
+	Checksums map[string]string `json:"checksums,omitempty"`
+	//KNLConfig used to ingest the release, e.g. the image of the helper pod, default is the default KNLConfig
+	// +optional
+	// +nullable
+	Config *string `json:"config,omitempty"`
+}
+
+// SROSImagePVCSource specifies a release tarball in a PVC
+type SROSImagePVCSource struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	//path of the tarball in the PVC
+	Path string `json:"path"`
+}
+
+type SROSImagePhase string
+
+const (
+	SROSImagePhaseIngesting SROSImagePhase = "Ingesting"
+	SROSImagePhaseAvailable SROSImagePhase = "Available"
+	SROSImagePhaseFailed    SROSImagePhase = "Failed"
+)
+
+// SROSImageStatus defines the observed state of SROSImage.
+type SROSImageStatus struct {
+	// +optional
+	Phase SROSImagePhase `json:"phase,omitempty"`
+	//the generation of spec that is ingested
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	//path of the release folder on the file server
+	// +optional
+	Path string `json:"path,omitempty"`
+	//sha256 checksum of required files of the release, key is file name
+	// +optional
+	Files map[string]string `json:"files,omitempty"`
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Path",type=string,JSONPath=`.status.path`
+
+// SROSImage is the Schema for the srosimages API,
+// it ingests a SR OS release tarball into the file server;
+// the release folder is removed when the SROSImage is deleted
+type SROSImage struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// metadata is a standard object metadata
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty,omitzero"`
+
+	// spec defines the desired state of SROSImage
+	// +required
+	Spec SROSImageSpec `json:"spec"`
+
+	// status defines the observed state of SROSImage
+	// +optional
+	Status SROSImageStatus `json:"status,omitempty,omitzero"`
+}
+
+// +kubebuilder:object:root=true
+
+// SROSImageList contains a list of SROSImage
+type SROSImageList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []SROSImage `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&SROSImage{}, &SROSImageList{})
+}
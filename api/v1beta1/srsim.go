@@ -9,7 +9,6 @@ import (
 	"strings"
 	"syscall"
 
-	k8slan "github.com/hujun-open/k8slan/api/v1beta1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/types"
@@ -71,7 +70,7 @@ func (srsim *SRSim) Validate(lab *LabSpec, nodeName string) error {
 	re := regexp.MustCompile(`^e\d{1,2}((-[a-z]\d{1,2})?-\d{1,2}){1,2}$`)
 	for linkName, link := range lab.LinkList {
 		for _, c := range link.Connectors {
-			if *c.NodeName != nodeName {
+			if !c.IsNode(nodeName) {
 				continue
 			}
 			if c.PortId != nil {
@@ -84,14 +83,12 @@ func (srsim *SRSim) Validate(lab *LabSpec, nodeName string) error {
 	return srsim.Chassis.Validate()
 }
 
-func (srsim *SRSim) getCFPVC(ns, nodeName, labName, slot string, id int) *corev1.PersistentVolumeClaim {
-	gconf := GCONF.Get()
-
+func (srsim *SRSim) getCFPVC(ns, nodeName, labName, slot string, id int, storageClass *string) *corev1.PersistentVolumeClaim {
 	return &corev1.PersistentVolumeClaim{
 		ObjectMeta: GetObjMeta(srsim.getCFPVCName(nodeName, labName, slot, id), labName, ns, nodeName, SRSIM),
 		Spec: corev1.PersistentVolumeClaimSpec{
 			AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOncePod},
-			StorageClassName: GetPointerVal(*gconf.PVCStorageClass),
+			StorageClassName: GetPointerVal(*storageClass),
 			Resources: corev1.VolumeResourceRequirements{
 				Requests: map[corev1.ResourceName]resource.Quantity{
 					corev1.ResourceStorage: resource.MustParse(CFSize),
@@ -193,8 +190,12 @@ func (srsim *SRSim) Ensure(ctx context.Context, nodeName string, clnt client.Cli
 			}
 			//cf cards
 			for i := 1; i <= 3; i++ {
-				cfPVC := srsim.getCFPVC(lab.Lab.Namespace, nodeName, lab.Lab.Name, slotid, i)
-				err := createIfNotExistsOrRemove(ctx, clnt, lab, cfPVC, false, false)
+				cfPVC := srsim.getCFPVC(lab.Lab.Namespace, nodeName, lab.Lab.Name, slotid, i, lab.Lab.GetConfig().PVCStorageClass)
+				err := lab.setRestoreVolSource(ctx, clnt, cfPVC)
+				if err != nil {
+					return err
+				}
+				err = createIfNotExistsOrRemove(ctx, clnt, lab, cfPVC, false, false)
 				if err != nil {
 					return fmt.Errorf("failed to create cf card %d pvc for SRSIM %v in lab %v, %w", i, nodeName, lab.Lab.Name, err)
 				}
@@ -236,6 +237,18 @@ func (srsim *SRSim) Ensure(ctx context.Context, nodeName string, clnt client.Cli
 		},
 	})
 
+	//restore config from snapshot into cf3 of the first CPM
+	restoreCM, snapshot, err := lab.ensureRestoreCfgMap(ctx, clnt, nodeName, SRSIM)
+	if err != nil {
+		return err
+	}
+	if restoreCM != nil {
+		initContainer, restoreVol := getRestoreInitContainer(*srsim.Image, restoreCM, snapshot,
+			srsim.getCFPVCName(nodeName, lab.Lab.Name, srsim.getFirstCPMSlot(), 3), "/cf3", "/cf3")
+		pod.Spec.InitContainers = append(pod.Spec.InitContainers, initContainer)
+		pod.Spec.Volumes = append(pod.Spec.Volumes, restoreVol)
+	}
+
 	//refer to NADs
 	netStr := ""
 	i := 1
@@ -244,17 +257,16 @@ func (srsim *SRSim) Ensure(ctx context.Context, nodeName string, clnt client.Cli
 		spokes := lab.SpokeMap[nodeName][linkName]
 		// for _, spokes := range lab.SpokeMap[nodeName] {
 		for _, spokeName := range spokes {
-			lanName := Getk8lanName(lab.Lab.Name, lab.SpokeLinkMap[spokeName])
-			nadName := k8slan.GetDefNADName(lanName, spokeName, true)
+			nadName, resKey := lab.getPodSpokeNAD(spokeName, false)
 			if lab.SpokeConnectorMap[spokeName].PortId != nil {
 				netStr += fmt.Sprintf("%v@%v,", nadName, *(lab.SpokeConnectorMap[spokeName].PortId))
 			} else {
 				//if port is not specified, default to mda 1 of first IOM slot
 				netStr += fmt.Sprintf("%v@e%v-1-%d,", nadName, srsim.Chassis.GetDefaultMDASlot(), i)
 			}
-			resName := k8slan.GetDPResouceName(lanName, spokeName, true)
-			resKey := fmt.Sprintf("%v/%v", K8sLANResKeyPrefix, resName)
-			pod.Spec.Containers[0].Resources.Limits[corev1.ResourceName(resKey)] = resource.MustParse("1")
+			if resKey != "" {
+				pod.Spec.Containers[0].Resources.Limits[corev1.ResourceName(resKey)] = resource.MustParse("1")
+			}
 			i += 1
 		}
 
@@ -265,13 +277,38 @@ func (srsim *SRSim) Ensure(ctx context.Context, nodeName string, clnt client.Cli
 			MultusAnnoKey: netStr,
 		}
 	}
-	err := createIfNotExistsOrRemove(ctx, clnt, lab, pod, true, false)
+	lab.setLocalAffinity(nodeName, &pod.ObjectMeta, &pod.Spec.Affinity)
+	err = createIfNotExistsOrRemove(ctx, clnt, lab, pod, true, false)
 	if err != nil {
 		return fmt.Errorf("failed to create SRL pod %v in lab %v, %w", nodeName, lab.Lab.Name, err)
 	}
 	return nil
 }
 
+// getFirstCPMSlot returns the first CPM slot in sorted order, which is the active CPM
+func (srsim *SRSim) getFirstCPMSlot() string {
+	for _, slot := range GetSortedKeySlice(srsim.Chassis.Cards) {
+		if IsCPM(slot) {
+			return slot
+		}
+	}
+	return ""
+}
+
+// GetCfgSnapshotSource implements CfgSnapshotSystem interface, the config is in the cf3 of the first CPM
+func (srsim *SRSim) GetCfgSnapshotSource(labName, nodeName string, paths []string) (*CfgSnapshotSource, error) {
+	plist, err := getCfgSnapshotPaths("/cf3", "/cf3", paths, []string{"."})
+	if err != nil {
+		return nil, err
+	}
+	return &CfgSnapshotSource{
+		PodName:   GetPodName(labName, nodeName),
+		Container: strings.ToLower("slot-" + srsim.getFirstCPMSlot()),
+		RootDir:   "/cf3",
+		Paths:     plist,
+	}, nil
+}
+
 func (srsim *SRSim) Shell(ctx context.Context, clnt client.Client, ns, lab, chassis, username string) {
 	pod := &corev1.Pod{}
 	podKey := types.NamespacedName{Namespace: ns, Name: GetPodName(lab, chassis)}
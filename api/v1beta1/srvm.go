@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
 	"strings"
 	"syscall"
 
@@ -79,6 +80,9 @@ const (
 	DefaultVSRLicSecName  = "vsrlic"
 	DefaultMAGCLicSecName = "magclic"
 	SRVMConsoleTCPPort    = 2222
+	//when console logging is enabled, the VM's telnet console listens on this loopback port,
+	//and the sidecar multiplexes it to SRVMConsoleTCPPort
+	SRVMConsoleRawTCPPort = 2223
 )
 
 func (srvm *SRVM) setToAppDefVal() {
@@ -172,7 +176,7 @@ func (srvm *SRVM) Validate(lab *LabSpec, nodeName string) error {
 	}
 	for linkName, link := range lab.LinkList {
 		for _, c := range link.Connectors {
-			if c.PortId != nil && *c.NodeName == nodeName {
+			if c.PortId != nil && c.IsNode(nodeName) {
 				if _, ok := srvm.Chassis.Cards[*c.PortId]; !ok {
 					return fmt.Errorf("port %v of node %v in link %v doesn't exists in its chassis spec", *c.PortId, nodeName, linkName)
 				}
@@ -182,6 +186,38 @@ func (srvm *SRVM) Validate(lab *LabSpec, nodeName string) error {
 	return srvm.Chassis.Validate()
 }
 
+// Warnings returns a warning if the release folder of a "filesvr:" image is missing on the file server
+func (srvm *SRVM) Warnings(lab *LabSpec, nodeName string) []string {
+	if srvm.Image == nil || !strings.HasPrefix(*srvm.Image, FTPImagePrefix) {
+		return nil
+	}
+	release := strings.TrimPrefix(*srvm.Image, FTPImagePrefix)
+	folder := GetSROSImageFolder(release)
+	var missing []string
+	for _, fname := range SROSImageFiles {
+		if _, err := os.Stat(filepath.Join(folder, fname)); err != nil {
+			missing = append(missing, fname)
+		}
+	}
+	if len(missing) > 0 {
+		return []string{fmt.Sprintf("release %v is missing %v on the file server, create a SROSImage named %v to ingest it",
+			release, strings.Join(missing, ","), release)}
+	}
+	return nil
+}
+
+func (vsim *VSIM) Warnings(lab *LabSpec, nodeName string) []string {
+	return (*SRVM)(vsim).Warnings(lab, nodeName)
+}
+
+func (vsri *VSRI) Warnings(lab *LabSpec, nodeName string) []string {
+	return (*SRVM)(vsri).Warnings(lab, nodeName)
+}
+
+func (magc *MAGC) Warnings(lab *LabSpec, nodeName string) []string {
+	return (*SRVM)(magc).Warnings(lab, nodeName)
+}
+
 func GetSRVMviaSys(nodeName string, sys System) *SRVM {
 	switch GetNodeTypeViaName(nodeName) {
 	case SRVMMAGC:
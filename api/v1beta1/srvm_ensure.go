@@ -9,7 +9,6 @@ import (
 	"path/filepath"
 	"strings"
 
-	k8slan "github.com/hujun-open/k8slan/api/v1beta1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -30,7 +29,6 @@ var (
 )
 
 func (srvm *SRVM) Ensure(ctx context.Context, nodeName string, clnt client.Client, forceRemoval bool) error {
-	gconf := GCONF.Get()
 	val := ctx.Value(ParsedLabKey)
 	if val == nil {
 		return MakeErr(fmt.Errorf("failed to get parsed lab obj from context"))
@@ -40,6 +38,7 @@ func (srvm *SRVM) Ensure(ctx context.Context, nodeName string, clnt client.Clien
 	if lab, ok = val.(*ParsedLab); !ok {
 		return MakeErr(fmt.Errorf("context stored value is not a ParsedLabSpec"))
 	}
+	gconf := lab.Lab.GetConfig()
 	vmt, _ := ParseSRVMName_New(nodeName)
 	//networking
 	indexNum := 1
@@ -94,6 +93,11 @@ func (srvm *SRVM) Ensure(ctx context.Context, nodeName string, clnt client.Clien
 			return MakeErr(err)
 		}
 	}
+	//restore config from snapshot
+	err = lab.restoreLocalDir(ctx, clnt, nodeName, absPath)
+	if err != nil {
+		return MakeErr(err)
+	}
 	var licFullPath string
 	for slot := range srvm.Chassis.Cards {
 		if IsCPM(slot) {
@@ -123,9 +127,17 @@ func (srvm *SRVM) Ensure(ctx context.Context, nodeName string, clnt client.Clien
 				diskSize = &SRCPMVMDiskSize
 			}
 
-			dv := NewDV(lab.Lab.Namespace, lab.Lab.Name,
+			dv := NewDV(lab.Lab.Namespace, lab.Lab.Name, nodeName, *srvm.Chassis.Type,
 				GetSRVMDVName(lab.Lab.Name, nodeName, slot),
 				cpmImage, gconf.PVCStorageClass, diskSize)
+			err = lab.setCachedImageSource(ctx, clnt, dv)
+			if err != nil {
+				return MakeErr(err)
+			}
+			err = lab.setRestoreVolSource(ctx, clnt, dv)
+			if err != nil {
+				return MakeErr(err)
+			}
 			err = createIfNotExistsOrRemove(ctx, clnt, lab, dv, false, forceRemoval)
 			if err != nil {
 				return MakeErr(err)
@@ -155,9 +167,32 @@ func (srvm *SRVM) Ensure(ctx context.Context, nodeName string, clnt client.Clien
 	return nil
 }
 
+// GetCfgSnapshotSource implements CfgSnapshotSystem interface, the config is in the cfg folder on the file server
+// GetCfgSnapshotSource returns the config folder of the node on the file server, paths are not supported
+func (srvm *SRVM) GetCfgSnapshotSource(labName, nodeName string, paths []string) (*CfgSnapshotSource, error) {
+	if len(paths) > 0 {
+		return nil, fmt.Errorf("paths are not supported, the config folder on the file server is collected")
+	}
+	return &CfgSnapshotSource{
+		LocalDir: GetSRConfigFTPSubFolder(labName, nodeName),
+	}, nil
+}
+
+func (vsim *VSIM) GetCfgSnapshotSource(labName, nodeName string, paths []string) (*CfgSnapshotSource, error) {
+	return (*SRVM)(vsim).GetCfgSnapshotSource(labName, nodeName, paths)
+}
+
+func (vsri *VSRI) GetCfgSnapshotSource(labName, nodeName string, paths []string) (*CfgSnapshotSource, error) {
+	return (*SRVM)(vsri).GetCfgSnapshotSource(labName, nodeName, paths)
+}
+
+func (magc *MAGC) GetCfgSnapshotSource(labName, nodeName string, paths []string) (*CfgSnapshotSource, error) {
+	return (*SRVM)(magc).GetCfgSnapshotSource(labName, nodeName, paths)
+}
+
 func (srvm *SRVM) getVMI(lab *ParsedLab, chassisName, cardslot, licPath, sftpuser, sftppass string) *kvv1.VirtualMachineInstance {
 	vmt, _ := ParseSRVMName_New(chassisName)
-	gconf := GCONF.Get()
+	gconf := lab.Lab.GetConfig()
 	isCPM := IsCPM(cardslot)
 	r := new(kvv1.VirtualMachineInstance)
 	r.ObjectMeta = GetObjMeta(
@@ -201,6 +236,9 @@ func (srvm *SRVM) getVMI(lab *ParsedLab, chassisName, cardslot, licPath, sftpuse
 		KvirtSideCarAnnontation: fmt.Sprintf(`[{"image": "%v"}]`, *gconf.SideCarHookImg),
 		VSROSSysinfoAnno:        GenSysinfo(*srvm.Chassis.Cards[cardslot].SysInfo, cfgURL, fixedLicLocalFTPURL),
 	}
+	if gconf.IsConsoleLogEnabled() {
+		r.ObjectMeta.Annotations[ConsoleLogAnnotation] = "true"
+	}
 
 	//can't set pc here will be rejected by adminssion webhook
 
@@ -385,25 +423,9 @@ func (srvm *SRVM) getVMI(lab *ParsedLab, chassisName, cardslot, licPath, sftpuse
 			if *lab.SpokeConnectorMap[spokeName].PortId != cardslot {
 				continue
 			}
-			lanName := Getk8lanName(lab.Lab.Name, lab.SpokeLinkMap[spokeName])
-			nadName := k8slan.GetDefNADName(lanName, spokeName, false)
-			r.Spec.Networks = append(r.Spec.Networks,
-				kvv1.Network{
-					Name: spokeName,
-					NetworkSource: kvv1.NetworkSource{
-						Multus: &kvv1.MultusNetwork{
-							NetworkName: nadName,
-						},
-					},
-				})
-			r.Spec.Domain.Devices.Interfaces = append(r.Spec.Domain.Devices.Interfaces,
-				kvv1.Interface{
-					Name: spokeName,
-					Binding: &kvv1.PluginBinding{
-						Name: "macvtap",
-					},
-				},
-			)
+			spokeNet, spokeIf := lab.getVMISpokeNetwork(spokeName)
+			r.Spec.Networks = append(r.Spec.Networks, spokeNet)
+			r.Spec.Domain.Devices.Interfaces = append(r.Spec.Domain.Devices.Interfaces, spokeIf)
 		}
 	}
 	//define inter-pod affinity for distributed VSROS VM like vsime and magc, so that all vms of given system are on same node
@@ -425,6 +447,6 @@ func (srvm *SRVM) getVMI(lab *ParsedLab, chassisName, cardslot, licPath, sftpuse
 			},
 		},
 	}
-
+	lab.setLocalAffinity(chassisName, &r.ObjectMeta, &r.Spec.Affinity)
 	return r
 }
@@ -26,6 +26,14 @@ type System interface {
 	Console(ctx context.Context, clnt client.Client, ns, lab, chassis string)
 }
 
+// +kubebuilder:object:generate=false
+// +kubebuilder:object:root=false
+// WarningSystem is implemented by node types that could return warnings in validation webhook,
+// warnings don't reject the lab
+type WarningSystem interface {
+	Warnings(lab *LabSpec, nodeName string) []string
+}
+
 type NodeType string
 
 const (
@@ -0,0 +1,81 @@
+package v1beta1
+
+// telnet commands, see RFC 854
+const (
+	TelnetIAC  byte = 255
+	TelnetDONT byte = 254
+	TelnetDO   byte = 253
+	TelnetWONT byte = 252
+	TelnetWILL byte = 251
+	TelnetSB   byte = 250
+	TelnetSE   byte = 240
+)
+
+const (
+	telnetStateData = iota
+	telnetStateIAC
+	telnetStateOption
+	telnetStateSB
+	telnetStateSBIAC
+)
+
+// +kubebuilder:object:generate=false
+// +kubebuilder:object:root=false
+// TelnetFilter removes telnet commands from a telnet byte stream, keeping only the data,
+// the filter keeps its state between calls so that a command could span across multiple reads
+type TelnetFilter struct {
+	state int
+}
+
+// Filter return data bytes in buf
+func (tf *TelnetFilter) Filter(buf []byte) []byte {
+	r := make([]byte, 0, len(buf))
+	for _, b := range buf {
+		switch tf.state {
+		case telnetStateData:
+			if b == TelnetIAC {
+				tf.state = telnetStateIAC
+			} else {
+				r = append(r, b)
+			}
+		case telnetStateIAC:
+			switch b {
+			case TelnetIAC:
+				//escaped 255
+				r = append(r, b)
+				tf.state = telnetStateData
+			case TelnetWILL, TelnetWONT, TelnetDO, TelnetDONT:
+				tf.state = telnetStateOption
+			case TelnetSB:
+				tf.state = telnetStateSB
+			default:
+				tf.state = telnetStateData
+			}
+		case telnetStateOption:
+			tf.state = telnetStateData
+		case telnetStateSB:
+			if b == TelnetIAC {
+				tf.state = telnetStateSBIAC
+			}
+		case telnetStateSBIAC:
+			if b == TelnetSE {
+				tf.state = telnetStateData
+			} else {
+				tf.state = telnetStateSB
+			}
+		}
+	}
+	return r
+}
+
+// TelnetEscape escapes byte 255 in data
+func TelnetEscape(buf []byte) []byte {
+	r := make([]byte, 0, len(buf))
+	for _, b := range buf {
+		r = append(r, b)
+		if b == TelnetIAC {
+			r = append(r, TelnetIAC)
+		}
+	}
+	return r
+}
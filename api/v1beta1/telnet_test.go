@@ -0,0 +1,45 @@
+package v1beta1
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestTelnetFilter(t *testing.T) {
+	testCases := []struct {
+		input  [][]byte
+		expect []byte
+	}{
+		{
+			input:  [][]byte{[]byte("login:")},
+			expect: []byte("login:"),
+		},
+		{
+			input:  [][]byte{{TelnetIAC, TelnetWILL, 1, TelnetIAC, TelnetWILL, 3}, []byte("abc")},
+			expect: []byte("abc"),
+		},
+		{
+			//command spans across two reads
+			input:  [][]byte{[]byte("a"), {TelnetIAC}, {TelnetDO, 1}, []byte("b")},
+			expect: []byte("ab"),
+		},
+		{
+			input:  [][]byte{{'a', TelnetIAC, TelnetSB, 24, 0, TelnetIAC, TelnetSE, 'b'}},
+			expect: []byte("ab"),
+		},
+		{
+			input:  [][]byte{TelnetEscape([]byte{'a', 255, 'b'})},
+			expect: []byte{'a', 255, 'b'},
+		},
+	}
+	for i, c := range testCases {
+		f := new(TelnetFilter)
+		r := []byte{}
+		for _, in := range c.input {
+			r = append(r, f.Filter(in)...)
+		}
+		if !bytes.Equal(r, c.expect) {
+			t.Fatalf("case %d failed, expect %v got %v", i, c.expect, r)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package v1beta1
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+type TopologyShape string
+
+const (
+	ShapeLeafSpine TopologyShape = "leaf-spine"
+	ShapeRing      TopologyShape = "ring"
+	ShapeFullMesh  TopologyShape = "full-mesh"
+	ShapeHubSpoke  TopologyShape = "hub-spoke"
+	ShapePEP       TopologyShape = "pe-p"
+)
+
+// ShapeRoles lists roles of each shape
+var ShapeRoles = map[TopologyShape][]string{
+	ShapeLeafSpine: {"spine", "leaf"},
+	ShapeRing:      {"node"},
+	ShapeFullMesh:  {"node"},
+	ShapeHubSpoke:  {"hub", "spoke"},
+	ShapePEP:       {"p", "pe"},
+}
+
+// TopologyGenerator generates nodes and links of a common shape:
+//   - leaf-spine: roles spine and leaf, each leaf connects to every spine
+//   - ring: role node, node i connects to node i+1, the last connects to the first, at least 3 nodes
+//   - full-mesh: role node, every node connects to all other nodes
+//   - hub-spoke: roles hub and spoke, each spoke connects to every hub
+//   - pe-p: roles p and pe, P nodes are full mesh, each PE connects to two adjacent P nodes
+//
+// generated nodes and links are merged with the ones in nodes and links, the specified ones take precedence;
+// link between node a and b is named "<a>-<b>"
+type TopologyGenerator struct {
+	//+required
+	//one of leaf-spine, ring, full-mesh, hub-spoke and pe-p
+	Shape TopologyShape `json:"shape"`
+	//+required
+	//key is the role name of the shape
+	Roles map[string]*GeneratorRole `json:"roles"`
+}
+
+// GeneratorRole specifies nodes of a role
+type GeneratorRole struct {
+	//+required
+	//number of nodes of the role
+	Count int32 `json:"count"`
+	//+required
+	//name pattern of nodes, must contain exactly one "%d" that is replaced by the node index, e.g. "srl-%d";
+	//node type is derived from the name if template doesn't specify one
+	NamePattern string `json:"namePattern"`
+	//index of the first node, default is 1
+	// +optional
+	// +nullable
+	Start *int32 `json:"start,omitempty"`
+	//template of the nodes, node type and chassis come from here
+	// +optional
+	// +nullable
+	Template *OneOfSystem `json:"template,omitempty"`
+	//port id pattern of generated connectors, "%d" is replaced by the port number of the node, starts from 1 and increase per link,
+	//port numbers used by other connectors of the node are skipped; port id is not set if not specified
+	// +optional
+	// +nullable
+	PortPattern *string `json:"portPattern,omitempty"`
+}
+
+// NodeName returns name of the i-th (starts from 0) node of the role
+func (role *GeneratorRole) NodeName(i int) string {
+	start := 1
+	if role.Start != nil {
+		start = int(*role.Start)
+	}
+	return strings.Replace(role.NamePattern, "%d", strconv.Itoa(start+i), 1)
+}
+
+// PortId returns port id of port number n, nil if PortPattern is not specified
+func (role *GeneratorRole) PortId(n int) *string {
+	if role.PortPattern == nil {
+		return nil
+	}
+	return ReturnPointerVal(strings.Replace(*role.PortPattern, "%d", strconv.Itoa(n), 1))
+}
+
+func (gen *TopologyGenerator) Validate() error {
+	roles, ok := ShapeRoles[gen.Shape]
+	if !ok {
+		return fmt.Errorf("unsupported shape %v", gen.Shape)
+	}
+	names := make(map[string]string)
+	for _, roleName := range roles {
+		role, ok := gen.Roles[roleName]
+		if !ok || role == nil {
+			return fmt.Errorf("role %v of shape %v is not specified", roleName, gen.Shape)
+		}
+		if strings.Count(role.NamePattern, "%d") != 1 {
+			return fmt.Errorf("name pattern %v of role %v must contain exactly one %%d", role.NamePattern, roleName)
+		}
+		if role.PortPattern != nil && strings.Count(*role.PortPattern, "%d") != 1 {
+			return fmt.Errorf("port pattern %v of role %v must contain exactly one %%d", *role.PortPattern, roleName)
+		}
+		if role.Count < 1 {
+			return fmt.Errorf("role %v requires at least 1 node", roleName)
+		}
+		for i := 0; i < int(role.Count); i++ {
+			name := role.NodeName(i)
+			if prev, ok := names[name]; ok {
+				return fmt.Errorf("node %v is generated by both role %v and %v", name, prev, roleName)
+			}
+			names[name] = roleName
+		}
+	}
+	for roleName := range gen.Roles {
+		if !slices.Contains(roles, roleName) {
+			return fmt.Errorf("role %v is not a role of shape %v", roleName, gen.Shape)
+		}
+	}
+	switch gen.Shape {
+	case ShapeRing:
+		if gen.Roles["node"].Count < 3 {
+			return fmt.Errorf("ring requires at least 3 nodes")
+		}
+	case ShapeFullMesh:
+		if gen.Roles["node"].Count < 2 {
+			return fmt.Errorf("full-mesh requires at least 2 nodes")
+		}
+	}
+	return nil
+}
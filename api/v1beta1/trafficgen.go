@@ -0,0 +1,441 @@
+package v1beta1
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+
+	"github.com/goccy/go-yaml"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func init() {
+	NewSysRegistry[TrafficGenNode] = func() System { return new(TrafficGen) }
+}
+
+type TrafficGenEngine string
+
+const (
+	TrafficGenNode         NodeType         = "trafficgen"
+	TrafficGenIPerf3       TrafficGenEngine = "iperf3"
+	TrafficGenTRex         TrafficGenEngine = "trex"
+	DefaultIPerf3Image     string           = "nicolaka/netshoot:v0.13"
+	DefaultTrafficGenMem   string           = "256Mi"
+	DefaultTRexMem         string           = "4Gi"
+	DefaultTrafficDuration int32            = 10
+	//iperf3 servers listen on port from IPerf3FirstPort to IPerf3FirstPort+IPerf3NumOfServers-1
+	IPerf3FirstPort      = 5201
+	IPerf3NumOfServers   = 8
+	trafficProfilePath   = "/knl-profile"
+	trafficRunDir        = "/tmp/knl-run"
+	trexCfgMountPath     = "/knl-trex"
+	trexCfgKey           = "trex_cfg.yaml"
+	trexRunScriptKey     = "knl_run.py"
+	trafficRunResultFile = "result.json"
+	trafficRunDoneFile   = "done"
+)
+
+// TrafficGen specifies a traffic generator node using iperf3 or TRex stateless;
+// a run is started and stopped via a TrafficRun
+type TrafficGen struct {
+	//iperf3 or trex
+	Engine *TrafficGenEngine `json:"engine,omitempty"`
+	//container image, default is nicolaka/netshoot for iperf3;
+	//for trex, it must be specified, the container working dir must be the TRex install folder
+	Image *string `json:"image,omitempty"`
+	//a k8s configmap in the lab namespace as the traffic profile, each key is a stream:
+	//for iperf3, value is the iperf3 client arguments like "-c 192.168.1.2 -p 5202 -u -b 100M", streams run in parallel;
+	//for trex, key must end with ".py" and value is a TRex stateless profile, streams are loaded onto ports in key order, round robin
+	// +optional
+	// +nullable
+	Profile *string `json:"profile,omitempty"`
+	//requested memory in k8s resource unit
+	// +optional
+	// +nullable
+	ReqMemory *resource.Quantity `json:"memory,omitempty"`
+	//requested cpu in k8s resource unit
+	// +optional
+	// +nullable
+	ReqCPU *resource.Quantity `json:"cpu,omitempty"`
+}
+
+func (tg *TrafficGen) SetToAppDefVal() {
+	tg.Engine = ReturnPointerVal(TrafficGenIPerf3)
+}
+
+func (tg *TrafficGen) FillDefaultVal(nodeName string) {
+	if tg.Engine == nil || *tg.Engine != TrafficGenIPerf3 {
+		if tg.ReqMemory == nil {
+			tg.ReqMemory = ReturnPointerVal(resource.MustParse(DefaultTRexMem))
+		}
+		return
+	}
+	if tg.Image == nil {
+		tg.Image = ReturnPointerVal(DefaultIPerf3Image)
+	}
+	if tg.ReqMemory == nil {
+		tg.ReqMemory = ReturnPointerVal(resource.MustParse(DefaultTrafficGenMem))
+	}
+}
+
+func (tg *TrafficGen) Validate(lab *LabSpec, nodeName string) error {
+	if tg.Engine == nil {
+		return fmt.Errorf("engine not specified")
+	}
+	switch *tg.Engine {
+	case TrafficGenIPerf3:
+	case TrafficGenTRex:
+		n := 0
+		for _, link := range lab.LinkList {
+			for _, c := range link.Connectors {
+				if c.IsNode(nodeName) {
+					n++
+				}
+			}
+		}
+		if n%2 != 0 {
+			return fmt.Errorf("trex requires even number of ports, got %d", n)
+		}
+	default:
+		return fmt.Errorf("unsupported engine %v", *tg.Engine)
+	}
+	if tg.Image == nil {
+		return fmt.Errorf("image not specified")
+	}
+	return validatePodPortIds(lab, nodeName, validateLinuxIntfName)
+}
+
+type trexPortInfo struct {
+	IP        string `yaml:"ip,omitempty"`
+	DefaultGW string `yaml:"default_gw,omitempty"`
+	DestMac   string `yaml:"dest_mac,omitempty"`
+}
+
+type trexCfg struct {
+	Version    int            `yaml:"version"`
+	PortLimit  int            `yaml:"port_limit"`
+	Interfaces []string       `yaml:"interfaces"`
+	PortInfo   []trexPortInfo `yaml:"port_info"`
+}
+
+// genTRexCfg returns trex_cfg.yaml using linux interfaces in intfList via af_packet,
+// port uses the first IPv4 address of the connector and nexthop of its first IPv4 route as gateway if specified
+func genTRexCfg(lab *ParsedLab, nodeName string, intfList []string) string {
+	cfg := trexCfg{
+		Version:   2,
+		PortLimit: len(intfList),
+	}
+	i := 0
+	for _, linkName := range GetSortedKeySlice(lab.SpokeMap[nodeName]) {
+		for _, spokeName := range lab.SpokeMap[nodeName][linkName] {
+			c := lab.SpokeConnectorMap[spokeName]
+			cfg.Interfaces = append(cfg.Interfaces, fmt.Sprintf("--vdev=net_af_packet%d,iface=%v", i, intfList[i]))
+			i++
+			info := trexPortInfo{DestMac: "ff:ff:ff:ff:ff:ff"}
+			for _, addrStr := range c.Addrs {
+				if p := netip.MustParsePrefix(addrStr); p.Addr().Is4() {
+					info.IP = p.Addr().String()
+					break
+				}
+			}
+			for _, routeStr := range c.Routes {
+				if r, _ := parseRoute(routeStr); r.Via.Is4() {
+					info.DefaultGW = r.Via.String()
+					break
+				}
+			}
+			if info.IP != "" && info.DefaultGW != "" {
+				info.DestMac = ""
+			} else {
+				info.IP, info.DefaultGW = "", ""
+			}
+			cfg.PortInfo = append(cfg.PortInfo, info)
+		}
+	}
+	buf, _ := yaml.Marshal([]trexCfg{cfg})
+	return string(buf)
+}
+
+// trexRunScript runs all profiles in a folder for a duration using TRex stateless python API,
+// stop traffic on SIGTERM and print stats as a json object of TrafficStreamStats keyed by stream name
+const trexRunScript = `import glob, json, os, signal, sys, time
+from trex.stl.api import STLClient, STLProfile
+
+duration, profile_dir = float(sys.argv[1]), sys.argv[2]
+stopped = False
+def on_term(signum, frame):
+    global stopped
+    stopped = True
+signal.signal(signal.SIGTERM, on_term)
+
+c = STLClient()
+c.connect()
+c.reset()
+ports = c.get_all_ports()
+profiles = sorted(glob.glob(os.path.join(profile_dir, "*.py")))
+pgids = {}
+for i, p in enumerate(profiles):
+    name = os.path.basename(p)[:-3]
+    streams = STLProfile.load_py(p).get_streams()
+    for s in streams:
+        pgid = s.get_flow_stats_id()
+        if pgid is not None:
+            pgids[pgid] = name
+    c.add_streams(streams, ports=[ports[i % len(ports)]])
+c.clear_stats()
+start = time.time()
+c.start(ports=ports, duration=duration)
+while c.is_traffic_active(ports=ports) and not stopped:
+    time.sleep(1)
+c.stop(ports=ports)
+time.sleep(1)
+elapsed = max(time.time() - start, 1)
+stats = c.get_stats()
+result = {}
+for i, p in enumerate(ports):
+    st = stats[p]
+    result["port%d" % p] = {"txPackets": st["opackets"], "rxPackets": st["ipackets"],
+        "txBytes": st["obytes"], "rxBytes": st["ibytes"], "bitsPerSecond": int(st["ibytes"] * 8 / elapsed)}
+for pgid, st in stats.get("flow_stats", {}).items():
+    if pgid not in pgids:
+        continue
+    tx, rx = st["tx_pkts"]["total"], st["rx_pkts"]["total"]
+    result["%s-%s" % (pgids[pgid], pgid)] = {"txPackets": tx, "rxPackets": rx,
+        "txBytes": st["tx_bytes"]["total"], "rxBytes": st["rx_bytes"]["total"],
+        "lostPackets": max(tx - rx, 0), "bitsPerSecond": int(st["rx_bytes"]["total"] * 8 / elapsed)}
+c.disconnect()
+print(json.dumps(result))
+`
+
+func (tg *TrafficGen) Ensure(ctx context.Context, nodeName string, clnt client.Client, forceRemoval bool) error {
+	val := ctx.Value(ParsedLabKey)
+	if val == nil {
+		return MakeErr(fmt.Errorf("failed to get parsed lab obj from context"))
+	}
+	var lab *ParsedLab
+	var ok bool
+	if lab, ok = val.(*ParsedLab); !ok {
+		return MakeErr(fmt.Errorf("context stored value is not a ParsedLabSpec"))
+	}
+	pod := NewBasePod(lab.Lab.Name, nodeName, lab.Lab.Namespace, *tg.Image, TrafficGenNode)
+	//refer to NADs
+	intfList := setPodNetworks(lab, nodeName, pod, func(i int) string { return fmt.Sprintf("eth%d", i) }, nil)
+	lines := genIntfCmds(lab, nodeName, intfList, false)
+	pod.Spec.Containers[0].SecurityContext = &corev1.SecurityContext{
+		Capabilities: &corev1.Capabilities{
+			Add: []corev1.Capability{"NET_ADMIN"},
+		},
+	}
+	if *tg.Engine == TrafficGenTRex {
+		trexCM := &corev1.ConfigMap{
+			ObjectMeta: GetObjMeta(fmt.Sprintf("%v-%v-trex", lab.Lab.Name, nodeName), lab.Lab.Name, lab.Lab.Namespace, nodeName, TrafficGenNode),
+			Data: map[string]string{
+				trexCfgKey:       genTRexCfg(lab, nodeName, intfList),
+				trexRunScriptKey: trexRunScript,
+			},
+		}
+		err := createIfNotExistsOrRemove(ctx, clnt, lab, trexCM, true, false)
+		if err != nil {
+			return fmt.Errorf("failed to create trex configmap for trafficgen %v in lab %v, %w", nodeName, lab.Lab.Name, err)
+		}
+		pod.Spec.Volumes = append(pod.Spec.Volumes, corev1.Volume{
+			Name: "trex",
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{
+						Name: trexCM.Name,
+					},
+				},
+			},
+		})
+		pod.Spec.Containers[0].VolumeMounts = append(pod.Spec.Containers[0].VolumeMounts, corev1.VolumeMount{
+			Name:      "trex",
+			MountPath: trexCfgMountPath,
+		})
+		//TRex server runs in interactive mode, in foreground
+		pod.Spec.Containers[0].SecurityContext = &corev1.SecurityContext{
+			Privileged: ReturnPointerVal(true),
+		}
+		lines = append(lines, fmt.Sprintf("exec ./t-rex-64 -i --cfg %v", filepath.Join(trexCfgMountPath, trexCfgKey)))
+	} else {
+		for i := 0; i < IPerf3NumOfServers; i++ {
+			lines = append(lines, fmt.Sprintf("iperf3 -s -D -p %d", IPerf3FirstPort+i))
+		}
+		lines = append(lines, podIdleCmds...)
+	}
+	pod.Spec.Containers[0].Command = []string{"sh", "-c", strings.Join(lines, "\n")}
+	if tg.Profile != nil {
+		pod.Spec.Volumes = append(pod.Spec.Volumes, corev1.Volume{
+			Name: "profile",
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{
+						Name: *tg.Profile,
+					},
+				},
+			},
+		})
+		pod.Spec.Containers[0].VolumeMounts = append(pod.Spec.Containers[0].VolumeMounts, corev1.VolumeMount{
+			Name:      "profile",
+			MountPath: trafficProfilePath,
+		})
+	}
+	//add resource request
+	pod.Spec.Containers[0].Resources.Requests = make(corev1.ResourceList)
+	if tg.ReqCPU != nil {
+		pod.Spec.Containers[0].Resources.Requests[corev1.ResourceCPU] = *tg.ReqCPU
+	}
+	if tg.ReqMemory != nil {
+		pod.Spec.Containers[0].Resources.Requests[corev1.ResourceMemory] = *tg.ReqMemory
+	}
+	err := createIfNotExistsOrRemove(ctx, clnt, lab, pod, true, false)
+	if err != nil {
+		return fmt.Errorf("failed to create trafficgen pod %v in lab %v, %w", nodeName, lab.Lab.Name, err)
+	}
+	return nil
+}
+
+func getTrafficRunDir(runName string) string {
+	return filepath.Join(trafficRunDir, runName)
+}
+
+// StartCmd returns the command to start run runName in background for duration seconds,
+// the result is saved in the run folder in the pod
+func (tg *TrafficGen) StartCmd(runName string, duration int32) []string {
+	dir := getTrafficRunDir(runName)
+	var run string
+	if *tg.Engine == TrafficGenTRex {
+		run = fmt.Sprintf("PYTHONPATH=$TREXDIR/automation/trex_control_plane/interactive python3 %v %d %v > %v 2> err.log",
+			filepath.Join(trexCfgMountPath, trexRunScriptKey), duration, trafficProfilePath, trafficRunResultFile)
+	} else {
+		run = fmt.Sprintf("for f in %v/*; do iperf3 $(cat $f) -J -t %d > $(basename $f).json & done; wait",
+			trafficProfilePath, duration)
+	}
+	return []string{"sh", "-c",
+		fmt.Sprintf("TREXDIR=$(pwd); rm -rf %v; mkdir -p %v && cd %v && (%v; touch %v) > /dev/null 2>&1 &",
+			dir, dir, dir, run, trafficRunDoneFile)}
+}
+
+// StopCmd returns the command to stop a running run, the partial result is still collected via CollectCmd
+func (tg *TrafficGen) StopCmd(runName string) []string {
+	if *tg.Engine == TrafficGenTRex {
+		return []string{"sh", "-c", fmt.Sprintf("pkill -TERM -f %v || true", trexRunScriptKey)}
+	}
+	return []string{"sh", "-c", "pkill -INT -f 'iperf3 .*-J' || true"}
+}
+
+// CollectCmd returns the command to print result of run runName, nothing is printed if the run is not finished
+func (tg *TrafficGen) CollectCmd(runName string) []string {
+	dir := getTrafficRunDir(runName)
+	check := fmt.Sprintf("cd %v 2>/dev/null && test -f %v || exit 0; ", dir, trafficRunDoneFile)
+	if *tg.Engine == TrafficGenTRex {
+		return []string{"sh", "-c", check + fmt.Sprintf("cat %v; cat err.log >&2", trafficRunResultFile)}
+	}
+	//print a json object with stream name as key and iperf3 json output as value
+	return []string{"sh", "-c", check +
+		`printf '{'; sep=''; for f in *.json; do printf '%s"%s":' "$sep" "${f%.json}"; cat "$f"; sep=','; done; printf '}'`}
+}
+
+type iperf3Sum struct {
+	Bytes         int64   `json:"bytes"`
+	BitsPerSecond float64 `json:"bits_per_second"`
+	Packets       int64   `json:"packets"`
+	LostPackets   int64   `json:"lost_packets"`
+}
+
+type iperf3Result struct {
+	End struct {
+		Sum         *iperf3Sum `json:"sum"`
+		SumSent     *iperf3Sum `json:"sum_sent"`
+		SumReceived *iperf3Sum `json:"sum_received"`
+	} `json:"end"`
+	Error string `json:"error"`
+}
+
+// ParseResult parses output of CollectCmd into stats sorted by stream name,
+// nil is returned if output is empty, which means the run is not finished
+func (tg *TrafficGen) ParseResult(out []byte) ([]TrafficStreamStats, error) {
+	if len(strings.TrimSpace(string(out))) == 0 {
+		return nil, nil
+	}
+	r := []TrafficStreamStats{}
+	if *tg.Engine == TrafficGenTRex {
+		streams := make(map[string]TrafficStreamStats)
+		if err := json.Unmarshal(out, &streams); err != nil {
+			return nil, fmt.Errorf("failed to parse trex result, %w", err)
+		}
+		for name, st := range streams {
+			st.Name = name
+			r = append(r, st)
+		}
+	} else {
+		streams := make(map[string]json.RawMessage)
+		if err := json.Unmarshal(out, &streams); err != nil {
+			return nil, fmt.Errorf("failed to parse iperf3 result, %w", err)
+		}
+		for name, raw := range streams {
+			st := TrafficStreamStats{Name: name}
+			ir := new(iperf3Result)
+			if err := json.Unmarshal(raw, ir); err != nil {
+				st.Error = fmt.Sprintf("invalid iperf3 output, %v", err)
+				r = append(r, st)
+				continue
+			}
+			st.Error = ir.Error
+			if s := ir.End.SumSent; s != nil {
+				st.TxBytes = s.Bytes
+			}
+			if s := ir.End.SumReceived; s != nil {
+				st.RxBytes = s.Bytes
+				st.BitsPerSecond = int64(s.BitsPerSecond)
+			}
+			//udp test
+			if s := ir.End.Sum; s != nil {
+				st.TxPackets = s.Packets
+				st.LostPackets = s.LostPackets
+				st.RxPackets = s.Packets - s.LostPackets
+				if st.TxBytes == 0 {
+					st.TxBytes = s.Bytes
+				}
+				if st.BitsPerSecond == 0 {
+					st.BitsPerSecond = int64(s.BitsPerSecond)
+				}
+			}
+			r = append(r, st)
+		}
+	}
+	sort.Slice(r, func(i, j int) bool { return r[i].Name < r[j].Name })
+	return r, nil
+}
+
+func (tg *TrafficGen) Shell(ctx context.Context, clnt client.Client, ns, lab, chassis, username string) {
+	envList := []string{fmt.Sprintf("HOME=%v", os.Getenv("HOME"))}
+	fmt.Printf("connecting to %v\n", GetPodName(lab, chassis))
+	cmd := "sh"
+	if tg.Engine != nil && *tg.Engine == TrafficGenTRex {
+		cmd = "./trex-console"
+	}
+	syscall.Exec("/bin/sh",
+		[]string{"sh", "-c",
+			fmt.Sprintf("kubectl -n %v exec -it %v -- %v",
+				ns, GetPodName(lab, chassis), cmd)},
+		envList)
+}
+
+func (tg *TrafficGen) Console(ctx context.Context, clnt client.Client, ns, lab, chassis string) {
+	envList := []string{fmt.Sprintf("HOME=%v", os.Getenv("HOME"))}
+	fmt.Printf("connecting to %v\n", GetPodName(lab, chassis))
+	syscall.Exec("/bin/sh",
+		[]string{"sh", "-c",
+			fmt.Sprintf("kubectl -n %v exec -it %v -- sh",
+				ns, GetPodName(lab, chassis))},
+		envList)
+}
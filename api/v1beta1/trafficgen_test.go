@@ -0,0 +1,47 @@
+package v1beta1
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTrafficGenParseResult(t *testing.T) {
+	tg := &TrafficGen{Engine: ReturnPointerVal(TrafficGenIPerf3)}
+	if r, err := tg.ParseResult([]byte("  \n")); err != nil || r != nil {
+		t.Fatalf("expect nil result for unfinished run, got %v, %v", r, err)
+	}
+	out := `{"udp":{"end":{"sum":{"bytes":1000,"bits_per_second":800,"packets":10,"lost_packets":2}}},` +
+		`"tcp":{"end":{"sum_sent":{"bytes":2000,"bits_per_second":1600},"sum_received":{"bytes":1900,"bits_per_second":1520}}},` +
+		`"bad":{"error":"unable to connect to server"}}`
+	r, err := tg.ParseResult([]byte(out))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r) != 3 || r[0].Name != "bad" || r[1].Name != "tcp" || r[2].Name != "udp" {
+		t.Fatalf("unexpected result %+v", r)
+	}
+	if r[0].Error != "unable to connect to server" {
+		t.Fatalf("unexpected error %v", r[0].Error)
+	}
+	if r[1].TxBytes != 2000 || r[1].RxBytes != 1900 || r[1].BitsPerSecond != 1520 {
+		t.Fatalf("unexpected tcp stats %+v", r[1])
+	}
+	if r[2].TxPackets != 10 || r[2].RxPackets != 8 || r[2].LostPackets != 2 || r[2].TxBytes != 1000 || r[2].BitsPerSecond != 800 {
+		t.Fatalf("unexpected udp stats %+v", r[2])
+	}
+
+	tg.Engine = ReturnPointerVal(TrafficGenTRex)
+	r, err = tg.ParseResult([]byte(`{"port0":{"txPackets":5,"rxPackets":4,"txBytes":500,"rxBytes":400,"bitsPerSecond":3200}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r) != 1 || r[0].Name != "port0" || r[0].RxPackets != 4 || r[0].BitsPerSecond != 3200 {
+		t.Fatalf("unexpected trex result %+v", r)
+	}
+	if _, err = tg.ParseResult([]byte("Traceback")); err == nil {
+		t.Fatalf("expect error for invalid output")
+	}
+	if cmd := tg.StartCmd("run1", 30); !strings.Contains(cmd[2], "/tmp/knl-run/run1") || !strings.Contains(cmd[2], " 30 ") {
+		t.Fatalf("unexpected start command %v", cmd)
+	}
+}
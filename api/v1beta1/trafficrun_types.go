@@ -0,0 +1,123 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TrafficRunSpec specifies a run of a trafficgen node in a lab, the run starts once it is created
+type TrafficRunSpec struct {
+	//name of the lab, the lab must be in the same namespace
+	Lab string `json:"lab"`
+	//name of the trafficgen node
+	Node string `json:"node"`
+	//duration of the run in seconds, default is 10
+	// +optional
+	// +nullable
+	Duration *int32 `json:"duration,omitempty"`
+	//stop the run if true, stats collected so far are kept
+	// +optional
+	// +nullable
+	Stop *bool `json:"stop,omitempty"`
+}
+
+type TrafficRunPhase string
+
+const (
+	TrafficRunPhasePending   TrafficRunPhase = "Pending"
+	TrafficRunPhaseRunning   TrafficRunPhase = "Running"
+	TrafficRunPhaseCompleted TrafficRunPhase = "Completed"
+	TrafficRunPhaseStopped   TrafficRunPhase = "Stopped"
+	TrafficRunPhaseFailed    TrafficRunPhase = "Failed"
+)
+
+// TrafficStreamStats is the stats of a stream when the run finishes
+type TrafficStreamStats struct {
+	//name of the stream, key in the profile for iperf3;
+	//for trex, "port<N>" for port stats, and "<profile>-<pg_id>" for stream with flow stats
+	Name string `json:"name"`
+	// +optional
+	TxPackets int64 `json:"txPackets,omitempty"`
+	// +optional
+	RxPackets int64 `json:"rxPackets,omitempty"`
+	// +optional
+	LostPackets int64 `json:"lostPackets,omitempty"`
+	// +optional
+	TxBytes int64 `json:"txBytes,omitempty"`
+	// +optional
+	RxBytes int64 `json:"rxBytes,omitempty"`
+	//average received throughput
+	// +optional
+	BitsPerSecond int64 `json:"bitsPerSecond,omitempty"`
+	//error message of the stream
+	// +optional
+	Error string `json:"error,omitempty"`
+}
+
+// TrafficRunStatus defines the observed state of TrafficRun.
+type TrafficRunStatus struct {
+	// +optional
+	Phase TrafficRunPhase `json:"phase,omitempty"`
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+	//per stream stats when the run finishes
+	// +optional
+	Streams []TrafficStreamStats `json:"streams,omitempty"`
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Lab",type=string,JSONPath=`.spec.lab`
+// +kubebuilder:printcolumn:name="Node",type=string,JSONPath=`.spec.node`
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+
+// TrafficRun is the Schema for the trafficruns API,
+// it runs the traffic profile of a trafficgen node and records per stream stats in status;
+// only one run of a node is running at a time, others stay pending
+type TrafficRun struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// metadata is a standard object metadata
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty,omitzero"`
+
+	// spec defines the desired state of TrafficRun
+	// +required
+	Spec TrafficRunSpec `json:"spec"`
+
+	// status defines the observed state of TrafficRun
+	// +optional
+	Status TrafficRunStatus `json:"status,omitempty,omitzero"`
+}
+
+// +kubebuilder:object:root=true
+
+// TrafficRunList contains a list of TrafficRun
+type TrafficRunList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []TrafficRun `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&TrafficRun{}, &TrafficRunList{})
+}
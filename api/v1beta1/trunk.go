@@ -0,0 +1,212 @@
+package v1beta1
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// TrunkType is the chassis type label value of trunk bridge pods
+	TrunkType NodeType = "trunk"
+	// trunkPodIntf is the interface name of the trunk link spoke in trunk bridge pod
+	trunkPodIntf = "trunk0"
+	trunkPodBR   = "br0"
+)
+
+// TrunkVLAN binds a VLAN of a trunk link to another link of the lab
+type TrunkVLAN struct {
+	//+required
+	//VLAN ID
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=4094
+	ID int32 `json:"id"`
+	//+required
+	//name of the link carried by the VLAN
+	Link string `json:"link"`
+}
+
+// IsTrunk returns true if the link carries other links as VLANs
+func (link *Link) IsTrunk() bool {
+	return len(link.VLANs) > 0
+}
+
+// validateTrunk checks VLANs of trunk link, VLAN IDs and links must be unique per trunk
+func (link *Link) validateTrunk() error {
+	if link.IsLocal() {
+		return fmt.Errorf("trunk link can't be local")
+	}
+	if link.IsCrossLab() {
+		return fmt.Errorf("trunk link can't be a lab-to-lab link")
+	}
+	ids := make(map[int32]string)
+	links := make(map[string]bool)
+	for _, v := range link.VLANs {
+		if v.ID < 1 || v.ID > 4094 {
+			return fmt.Errorf("invalid VLAN ID %d", v.ID)
+		}
+		if v.Link == "" {
+			return fmt.Errorf("link of VLAN %d not specified", v.ID)
+		}
+		if prev, ok := ids[v.ID]; ok {
+			return fmt.Errorf("VLAN %d is used by both link %v and %v", v.ID, prev, v.Link)
+		}
+		if links[v.Link] {
+			return fmt.Errorf("link %v is carried by more than one VLAN", v.Link)
+		}
+		ids[v.ID] = v.Link
+		links[v.Link] = true
+	}
+	for _, c := range link.Connectors {
+		if len(c.Addrs) > 0 {
+			return fmt.Errorf("connector %v of trunk link can't have addresses, they should be configured on VLAN sub-interfaces", *c.NodeName)
+		}
+	}
+	return nil
+}
+
+// GetTrunkBridgeName returns name of the pod bridging VLANs of a trunk link to the links they carry
+func GetTrunkBridgeName(trunkLink string) string {
+	return trunkLink + "-vlanbr"
+}
+
+// getTrunkMap returns trunks carrying each link, key is name of the carried link, value is a sorted list of trunk link names
+func (spec *LabSpec) getTrunkMap() map[string][]string {
+	r := make(map[string][]string)
+	for _, linkName := range GetSortedKeySlice(spec.LinkList) {
+		link := spec.LinkList[linkName]
+		if link == nil {
+			continue
+		}
+		for _, v := range link.VLANs {
+			r[v.Link] = append(r[v.Link], linkName)
+		}
+	}
+	return r
+}
+
+// validateTrunks checks links carried by trunks exist and could be carried,
+// and trunk bridge names don't clash with nodes or external connectors in names
+func (spec *LabSpec) validateTrunks(names map[string]string) error {
+	for _, linkName := range GetSortedKeySlice(spec.LinkList) {
+		link := spec.LinkList[linkName]
+		if !link.IsTrunk() {
+			continue
+		}
+		brName := GetTrunkBridgeName(linkName)
+		if _, ok := spec.NodeList[brName]; ok {
+			return fmt.Errorf("trunk bridge of link %v has same name as a node", linkName)
+		}
+		if _, ok := names[brName]; ok {
+			return fmt.Errorf("trunk bridge of link %v has same name as external connector in link %v", linkName, names[brName])
+		}
+		for _, v := range link.VLANs {
+			carried, ok := spec.LinkList[v.Link]
+			if !ok || carried == nil {
+				return fmt.Errorf("link %v of VLAN %d in trunk link %v doesn't exist", v.Link, v.ID, linkName)
+			}
+			switch {
+			case v.Link == linkName:
+				return fmt.Errorf("trunk link %v can't carry itself", linkName)
+			case carried.IsTrunk():
+				return fmt.Errorf("link %v of VLAN %d in trunk link %v is also a trunk", v.Link, v.ID, linkName)
+			case carried.IsLocal():
+				return fmt.Errorf("local link %v can't be carried by trunk link %v", v.Link, linkName)
+			case carried.IsCrossLab():
+				return fmt.Errorf("lab-to-lab link %v can't be carried by trunk link %v", v.Link, linkName)
+			}
+		}
+	}
+	return nil
+}
+
+// getTrunkBridgeConnectors returns connectors of trunk bridges attached to the link, in order of spoke index after link's own connectors;
+// the trunk bridge of a trunk link attaches to it and all links it carries
+func (plab *ParsedLab) getTrunkBridgeConnectors(linkName string) []*Connector {
+	var r []*Connector
+	if plab.Lab.Spec.LinkList[linkName].IsTrunk() {
+		r = append(r, &Connector{NodeName: ReturnPointerVal(GetTrunkBridgeName(linkName))})
+	}
+	for _, trunkName := range plab.TrunkMap[linkName] {
+		r = append(r, &Connector{NodeName: ReturnPointerVal(GetTrunkBridgeName(trunkName))})
+	}
+	return r
+}
+
+func getTrunkVLANIntfName(id int32) string {
+	return fmt.Sprintf("vlan%d", id)
+}
+
+// genTrunkScript returns the shell script of trunk bridge pod, it creates a VLAN-aware bridge,
+// the trunk link spoke is a tagged member of all VLANs, spoke of each carried link is an untagged member of its VLAN;
+// the script could be re-run after a container restart in the same pod, adding an existing VLAN to a port only updates its flags
+func genTrunkScript(vlans []TrunkVLAN, mtu *int32) []string {
+	lines := []string{
+		"set -e",
+		fmt.Sprintf("ip link show %v >/dev/null 2>&1 || ip link add %v type bridge vlan_filtering 1 vlan_default_pvid 0", trunkPodBR, trunkPodBR),
+		fmt.Sprintf("ip link set %v master %v", trunkPodIntf, trunkPodBR),
+	}
+	intfs := []string{trunkPodIntf}
+	for _, v := range vlans {
+		intf := getTrunkVLANIntfName(v.ID)
+		lines = append(lines,
+			fmt.Sprintf("bridge vlan add dev %v vid %d", trunkPodIntf, v.ID),
+			fmt.Sprintf("ip link set %v master %v", intf, trunkPodBR),
+			fmt.Sprintf("bridge vlan add dev %v vid %d pvid untagged", intf, v.ID),
+		)
+		intfs = append(intfs, intf)
+	}
+	for _, intf := range append(intfs, trunkPodBR) {
+		if mtu != nil {
+			lines = append(lines, fmt.Sprintf("ip link set dev %v mtu %d || true", intf, *mtu))
+		}
+		lines = append(lines, fmt.Sprintf("ip link set dev %v up", intf))
+	}
+	lines = append(lines, "set +e")
+	return append(lines, podIdleCmds...)
+}
+
+// EnsureTrunks creates a trunk bridge pod for each trunk link,
+// the pod connects to the trunk link and all links it carries as a spoke
+func (plab *ParsedLab) EnsureTrunks(ctx context.Context, clnt client.Client) error {
+	gconf := plab.Lab.GetConfig()
+	for _, linkName := range GetSortedKeySlice(plab.Lab.Spec.LinkList) {
+		link := plab.Lab.Spec.LinkList[linkName]
+		if !link.IsTrunk() {
+			continue
+		}
+		name := GetTrunkBridgeName(linkName)
+		vlans := slices.Clone(link.VLANs)
+		slices.SortFunc(vlans, func(a, b TrunkVLAN) int { return int(a.ID - b.ID) })
+		pod := NewBasePod(plab.Lab.Name, name, plab.Lab.Namespace, *gconf.VxLANAgentImage, TrunkType)
+		pod.Spec.Containers[0].SecurityContext = &corev1.SecurityContext{
+			Capabilities: &corev1.Capabilities{
+				Add: []corev1.Capability{"NET_ADMIN"},
+			},
+		}
+		pod.Spec.Containers[0].Command = []string{"sh", "-c", strings.Join(genTrunkScript(vlans, link.MTU), "\n")}
+		pod.Spec.Containers[0].Resources.Limits = make(corev1.ResourceList)
+		netList := []string{}
+		addSpokes := func(spokeLink, intf string) {
+			for _, spokeName := range plab.SpokeMap[name][spokeLink] {
+				nadName, resKey := plab.getPodSpokeNAD(spokeName, false)
+				netList = append(netList, fmt.Sprintf("%v@%v", nadName, intf))
+				pod.Spec.Containers[0].Resources.Limits[corev1.ResourceName(resKey)] = resource.MustParse("1")
+			}
+		}
+		addSpokes(linkName, trunkPodIntf)
+		for _, v := range vlans {
+			addSpokes(v.Link, getTrunkVLANIntfName(v.ID))
+		}
+		pod.Annotations[MultusAnnoKey] = strings.Join(netList, ",")
+		if err := createIfNotExistsOrRemove(ctx, clnt, plab, pod, true, false); err != nil {
+			return fmt.Errorf("failed to create trunk bridge pod %v in lab %v, %w", name, plab.Lab.Name, err)
+		}
+	}
+	return nil
+}
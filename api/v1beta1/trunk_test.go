@@ -0,0 +1,121 @@
+package v1beta1
+
+import (
+	"strings"
+	"testing"
+)
+
+func newTestTrunkLink(vlans map[int32]string, nodes ...string) *Link {
+	link := newTestLink(false, nodes...)
+	for _, id := range GetSortedKeySlice(vlans) {
+		link.VLANs = append(link.VLANs, TrunkVLAN{ID: id, Link: vlans[id]})
+	}
+	return link
+}
+
+func TestTrunkLink(t *testing.T) {
+	testList := []struct {
+		link  *Link
+		valid bool
+	}{
+		{newTestTrunkLink(map[int32]string{100: "v100", 200: "v200"}, "r1"), true},
+		{newTestTrunkLink(map[int32]string{100: "v100", 200: "v200"}, "r1", "r2"), true},
+		{newTestTrunkLink(map[int32]string{100: "v100", 4095: "v200"}, "r1"), false},
+		{newTestTrunkLink(map[int32]string{100: "v100", 200: "v100"}, "r1"), false},
+		{newTestTrunkLink(map[int32]string{100: ""}, "r1"), false},
+		{newTestTrunkLink(nil, "r1"), false},
+		{func() *Link {
+			link := newTestTrunkLink(map[int32]string{100: "v100", 200: "v200"}, "r1")
+			link.VLANs = append(link.VLANs, TrunkVLAN{ID: 100, Link: "v300"})
+			return link
+		}(), false},
+		{func() *Link {
+			link := newTestTrunkLink(map[int32]string{100: "v100"}, "r1")
+			link.Local = ReturnPointerVal(true)
+			return link
+		}(), false},
+		{func() *Link {
+			link := newTestTrunkLink(map[int32]string{100: "v100"}, "r1")
+			link.Connectors[0].Addrs = []string{"10.0.0.1/24"}
+			return link
+		}(), false},
+	}
+	for i, c := range testList {
+		err := c.link.Validate()
+		if (err == nil) != c.valid {
+			t.Fatalf("case %d: expect valid %v, got error %v", i, c.valid, err)
+		}
+	}
+
+	newSpec := func() *LabSpec {
+		return &LabSpec{
+			LinkList: map[string]*Link{
+				"trunk1": newTestTrunkLink(map[int32]string{100: "v100", 200: "v200"}, "r1"),
+				"v100":   newTestLink(false, "h1"),
+				"v200":   newTestLink(false, "h2", "h3"),
+			},
+		}
+	}
+	spec := newSpec()
+	if err := spec.validateTrunks(nil); err != nil {
+		t.Fatal(err)
+	}
+	trunkMap := spec.getTrunkMap()
+	//v100 only has one node, the trunk bridge is the other
+	if err := spec.LinkList["v100"].validate(len(trunkMap["v100"])); err != nil {
+		t.Fatal(err)
+	}
+	if err := spec.LinkList["v100"].Validate(); err == nil {
+		t.Fatalf("expect error for link with one node not carried by trunk")
+	}
+	for _, c := range []struct {
+		modify func(spec *LabSpec)
+		errStr string
+	}{
+		{func(spec *LabSpec) { delete(spec.LinkList, "v200") }, "doesn't exist"},
+		{func(spec *LabSpec) { spec.LinkList["v200"].VLANs = []TrunkVLAN{{ID: 300, Link: "v100"}} }, "also a trunk"},
+		{func(spec *LabSpec) { spec.LinkList["v200"].Local = ReturnPointerVal(true) }, "local link"},
+		{func(spec *LabSpec) { spec.LinkList["trunk1"].VLANs[0].Link = "trunk1" }, "carry itself"},
+		{func(spec *LabSpec) { spec.NodeList = map[string]*OneOfSystem{"trunk1-vlanbr": {}} }, "same name as a node"},
+	} {
+		spec := newSpec()
+		c.modify(spec)
+		if err := spec.validateTrunks(nil); err == nil || !strings.Contains(err.Error(), c.errStr) {
+			t.Fatalf("expect error containing %v, got %v", c.errStr, err)
+		}
+	}
+
+	//trunk bridge spokes follow connectors of the link
+	lab := &Lab{}
+	lab.Name = "lab1"
+	lab.Spec = *newSpec()
+	plab := ParseLab(lab, nil)
+	for linkName, expect := range map[string][]string{
+		"trunk1": {"trunk1-vlanbr"},
+		"v100":   {"trunk1-vlanbr"},
+		"v200":   {"trunk1-vlanbr"},
+	} {
+		r := plab.getTrunkBridgeConnectors(linkName)
+		if len(r) != len(expect) || *r[0].NodeName != expect[0] {
+			t.Fatalf("unexpected trunk bridges of link %v: %v", linkName, r)
+		}
+	}
+	workers := map[string]map[string]bool{"r1": {"w1": true}, "h1": {"w1": true}, "h2": {"w1": true}, "h3": {"w1": true}}
+	if r := lab.Spec.GetCoLocatedLinks(workers); len(r) != 0 {
+		t.Fatalf("unexpected co-located links %v", r)
+	}
+
+	script := strings.Join(genTrunkScript(lab.Spec.LinkList["trunk1"].VLANs, ReturnPointerVal(int32(1500))), "\n")
+	for _, s := range []string{
+		"ip link show br0 >/dev/null 2>&1 || ip link add br0 type bridge vlan_filtering 1 vlan_default_pvid 0",
+		"bridge vlan add dev trunk0 vid 100",
+		"bridge vlan add dev trunk0 vid 200",
+		"ip link set vlan200 master br0",
+		"bridge vlan add dev vlan200 vid 200 pvid untagged",
+		"ip link set dev trunk0 mtu 1500",
+	} {
+		if !strings.Contains(script, s) {
+			t.Fatalf("script doesn't contain %v:\n%v", s, script)
+		}
+	}
+}
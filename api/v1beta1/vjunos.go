@@ -0,0 +1,312 @@
+package v1beta1
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"strconv"
+	"syscall"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	kvv1 "kubevirt.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func init() {
+	NewSysRegistry[VJunosNode] = func() System { return new(VJunos) }
+}
+
+const (
+	VJunosNode            NodeType = "vjunos"
+	VJunosVariantRouter   string   = "router"
+	VJunosVariantSwitch   string   = "switch"
+	DefVJunosCPU          string   = "4"
+	DefVJunosMem          string   = "5Gi"
+	DefVJunosDiskSize     string   = "40Gi"
+	vjunosCfgVolName      string   = "vmm-data"
+	vjunosRouterProduct   string   = "VM-VMX"
+	vjunosSwitchProduct   string   = "VM-VEX"
+	VJunosSMBIOSFamily    string   = "lab"
+	vjunosMgmtSSHPort     int32    = 22
+	vjunosMgmtNETCONFPort int32    = 830
+)
+
+var vjunosPortRegex = regexp.MustCompile(`^ge-0/0/([0-9]+)$`)
+
+// VJunos specifies a Juniper vJunos-router or vJunos-switch VM
+type VJunos struct {
+	//kubevirt CDI supported URL of the vJunos qcow2 image, either HTTP (http://) or registry source (docker://)
+	Image *string `json:"image,omitempty"`
+	//router or switch
+	Variant *string `json:"variant,omitempty"`
+	//requested memory for the VM in k8s resource unit
+	ReqMemory *resource.Quantity `json:"memory,omitempty"`
+	//requested cpu for the VM in k8s resource unit
+	ReqCPU *resource.Quantity `json:"cpu,omitempty"`
+	//the VM disk size in k8s resource unit
+	DiskSize *resource.Quantity `json:"diskSize,omitempty"`
+	//a k8s configmap in the lab namespace with key "juniper.conf",
+	//it is attached to the VM as a USB disk labeled "vmm-data", which vJunos loads on boot
+	// +optional
+	// +nullable
+	StartupConfig *string `json:"startupConfig,omitempty"`
+}
+
+func (vj *VJunos) SetToAppDefVal() {
+	vj.Variant = ReturnPointerVal(VJunosVariantRouter)
+	vj.ReqCPU = ReturnPointerVal(resource.MustParse(DefVJunosCPU))
+	vj.ReqMemory = ReturnPointerVal(resource.MustParse(DefVJunosMem))
+	vj.DiskSize = ReturnPointerVal(resource.MustParse(DefVJunosDiskSize))
+}
+
+func (vj *VJunos) FillDefaultVal(nodeName string) {
+
+}
+
+func (vj *VJunos) Validate(lab *LabSpec, nodeName string) error {
+	if vj.Image == nil {
+		return fmt.Errorf("image not specified")
+	}
+	if vj.Variant == nil {
+		return fmt.Errorf("variant not specified")
+	}
+	if *vj.Variant != VJunosVariantRouter && *vj.Variant != VJunosVariantSwitch {
+		return fmt.Errorf("unknown variant %v, expect %v or %v", *vj.Variant, VJunosVariantRouter, VJunosVariantSwitch)
+	}
+	if vj.DiskSize == nil {
+		return fmt.Errorf("diskSize not specified")
+	}
+	if vj.ReqCPU == nil {
+		return fmt.Errorf("cpu not specified")
+	}
+	if vj.ReqMemory == nil {
+		return fmt.Errorf("memory not specified")
+	}
+	//interfaces are attached in order of ge-0/0/N, so N must be less than number of connectors
+	numOfConnectors := 0
+	for _, link := range lab.LinkList {
+		for _, c := range link.Connectors {
+			if c.IsNode(nodeName) {
+				numOfConnectors++
+			}
+		}
+	}
+	return validatePodPortIds(lab, nodeName, func(portId string) error {
+		m := vjunosPortRegex.FindStringSubmatch(portId)
+		if m == nil {
+			return fmt.Errorf("expect ge-0/0/N")
+		}
+		if n, _ := strconv.Atoi(m[1]); n >= numOfConnectors {
+			return fmt.Errorf("N must be less than number of links of the node %d", numOfConnectors)
+		}
+		return nil
+	})
+}
+
+// getSMBIOSProduct returns the SMBIOS product vJunos uses to identify its variant
+func (vj *VJunos) getSMBIOSProduct() string {
+	if *vj.Variant == VJunosVariantSwitch {
+		return vjunosSwitchProduct
+	}
+	return vjunosRouterProduct
+}
+
+// getOrderedSpokes returns spokes of the node in order of ge-0/0/N,
+// connector without port id takes the lowest unused N in sorted link order
+func getVJunosOrderedSpokes(lab *ParsedLab, nodeName string) []string {
+	var spokeList []string
+	for _, linkName := range GetSortedKeySlice(lab.SpokeMap[nodeName]) {
+		spokeList = append(spokeList, lab.SpokeMap[nodeName][linkName]...)
+	}
+	r := make([]string, len(spokeList))
+	var unassigned []string
+	for _, spokeName := range spokeList {
+		if portId := lab.SpokeConnectorMap[spokeName].PortId; portId != nil {
+			if m := vjunosPortRegex.FindStringSubmatch(*portId); m != nil {
+				if n, _ := strconv.Atoi(m[1]); n < len(r) && r[n] == "" {
+					r[n] = spokeName
+					continue
+				}
+			}
+		}
+		unassigned = append(unassigned, spokeName)
+	}
+	for i := range r {
+		if r[i] == "" {
+			r[i] = unassigned[0]
+			unassigned = unassigned[1:]
+		}
+	}
+	return r
+}
+
+func (vj *VJunos) Ensure(ctx context.Context, nodeName string, clnt client.Client, forceRemoval bool) error {
+	val := ctx.Value(ParsedLabKey)
+	if val == nil {
+		return MakeErr(fmt.Errorf("failed to get parsed lab obj from context"))
+	}
+	var lab *ParsedLab
+	var ok bool
+	if lab, ok = val.(*ParsedLab); !ok {
+		return MakeErr(fmt.Errorf("context stored value is not a ParsedLabSpec"))
+	}
+	gconf := lab.Lab.GetConfig()
+	//create DV
+	dv := NewDV(lab.Lab.Namespace, lab.Lab.Name, nodeName, VJunosNode,
+		GetVMPCDVName(lab.Lab.Name, nodeName),
+		*vj.Image, gconf.PVCStorageClass, vj.DiskSize)
+	err := lab.setCachedImageSource(ctx, clnt, dv)
+	if err != nil {
+		return MakeErr(err)
+	}
+	err = lab.setRestoreVolSource(ctx, clnt, dv)
+	if err != nil {
+		return MakeErr(err)
+	}
+	err = createIfNotExistsOrRemove(ctx, clnt, lab, dv, false, forceRemoval)
+	if err != nil {
+		return MakeErr(err)
+	}
+	//create vm
+	vmi := vj.getVMI(lab, nodeName)
+	err = createIfNotExistsOrFailedOrRemove(ctx, clnt, lab, vmi, checkVMIfail, true, forceRemoval)
+	if err != nil {
+		return MakeErr(err)
+	}
+	return nil
+}
+
+func (vj *VJunos) getVMI(lab *ParsedLab, vmname string) *kvv1.VirtualMachineInstance {
+	gconf := lab.Lab.GetConfig()
+	r := new(kvv1.VirtualMachineInstance)
+	r.ObjectMeta = GetObjMeta(
+		GetPodName(lab.Lab.Name, vmname),
+		lab.Lab.Name,
+		lab.Lab.Namespace,
+		vmname,
+		VJunosNode,
+	)
+	//SMBIOS product is set by the sidecar
+	r.ObjectMeta.Annotations = map[string]string{
+		KvirtSideCarAnnontation: fmt.Sprintf(`[{"image": "%v"}]`, *gconf.SideCarHookImg),
+		SMBIOSProductAnnotation: vj.getSMBIOSProduct(),
+	}
+	if gconf.IsConsoleLogEnabled() {
+		r.ObjectMeta.Annotations[ConsoleLogAnnotation] = "true"
+	}
+	r.Spec.Domain.CPU = &kvv1.CPU{
+		Model: "host-passthrough",
+		Cores: uint32(vj.ReqCPU.AsApproximateFloat64()),
+	}
+	r.Spec.Domain.Memory = &kvv1.Memory{
+		Guest: vj.ReqMemory,
+	}
+	//disk
+	r.Spec.Volumes = append(r.Spec.Volumes,
+		kvv1.Volume{
+			Name: "root",
+			VolumeSource: kvv1.VolumeSource{
+				DataVolume: &kvv1.DataVolumeSource{
+					Name: GetVMPCDVName(lab.Lab.Name, vmname),
+				},
+			},
+		},
+	)
+	r.Spec.Domain.Devices.Disks = append(r.Spec.Domain.Devices.Disks,
+		kvv1.Disk{
+			Name: "root",
+			DiskDevice: kvv1.DiskDevice{
+				Disk: &kvv1.DiskTarget{
+					Bus: kvv1.DiskBusVirtio,
+				},
+			},
+		})
+	//startup config
+	if vj.StartupConfig != nil {
+		r.Spec.Volumes = append(r.Spec.Volumes,
+			kvv1.Volume{
+				Name: vjunosCfgVolName,
+				VolumeSource: kvv1.VolumeSource{
+					ConfigMap: &kvv1.ConfigMapVolumeSource{
+						LocalObjectReference: corev1.LocalObjectReference{
+							Name: *vj.StartupConfig,
+						},
+						VolumeLabel: vjunosCfgVolName,
+					},
+				},
+			})
+		r.Spec.Domain.Devices.Disks = append(r.Spec.Domain.Devices.Disks,
+			kvv1.Disk{
+				Name: vjunosCfgVolName,
+				DiskDevice: kvv1.DiskDevice{
+					Disk: &kvv1.DiskTarget{
+						Bus: kvv1.DiskBusUSB,
+					},
+				},
+			})
+	}
+	//1st interface is fxp0
+	r.Spec.Networks = append(r.Spec.Networks,
+		kvv1.Network{
+			Name: "pod-net",
+			NetworkSource: kvv1.NetworkSource{
+				Pod: &kvv1.PodNetwork{},
+			},
+		})
+	r.Spec.Domain.Devices.Interfaces = append(r.Spec.Domain.Devices.Interfaces,
+		kvv1.Interface{
+			Name: "pod-net",
+			//the port is needed here to prevent all traffic go into VM
+			Ports: []kvv1.Port{
+				{Name: "ssh", Protocol: "TCP", Port: vjunosMgmtSSHPort},
+				{Name: "netconf", Protocol: "TCP", Port: vjunosMgmtNETCONFPort},
+			},
+			MacAddress: VMBaseMAC.String(),
+			InterfaceBindingMethod: kvv1.InterfaceBindingMethod{
+				Masquerade: &kvv1.InterfaceMasquerade{},
+			},
+		})
+	//port links, in order of ge-0/0/N
+	for _, spokeName := range getVJunosOrderedSpokes(lab, vmname) {
+		addVMISpokeIntf(lab, r, spokeName)
+	}
+	lab.setLocalAffinity(vmname, &r.ObjectMeta, &r.Spec.Affinity)
+	return r
+}
+
+func (vj *VJunos) getPod(ctx context.Context, clnt client.Client, ns, lab, chassis string) *corev1.Pod {
+	podList := &corev1.PodList{}
+	labelSelector := client.MatchingLabels{
+		"vm.kubevirt.io/name": GetPodName(lab, chassis),
+	}
+	err := clnt.List(ctx, podList, client.InNamespace(ns), labelSelector)
+	if err != nil {
+		log.Fatalf("failed to list pods: %v", err)
+	}
+	if len(podList.Items) == 0 {
+		log.Fatalf("failed to find vm pod %v", GetPodName(lab, chassis))
+	}
+	return &podList.Items[0]
+}
+
+func (vj *VJunos) Shell(ctx context.Context, clnt client.Client, ns, lab, chassis, username string) {
+	pod := vj.getPod(ctx, clnt, ns, lab, chassis)
+	if username == "" {
+		username = "admin"
+	}
+	fmt.Println("connecting to", chassis, "at", pod.Status.PodIP, "username", username)
+	SysCallSSH(username, pod.Status.PodIP)
+}
+
+func (vj *VJunos) Console(ctx context.Context, clnt client.Client, ns, lab, chassis string) {
+	pod := vj.getPod(ctx, clnt, ns, lab, chassis)
+	envList := []string{fmt.Sprintf("HOME=%v", os.Getenv("HOME"))}
+	fmt.Println("connecting to console of", chassis, "at", pod.Status.PodIP)
+	syscall.Exec("/bin/sh",
+		[]string{"sh", "-c",
+			fmt.Sprintf("telnet %v %d", pod.Status.PodIP, SRVMConsoleTCPPort)},
+		envList)
+}
@@ -0,0 +1,110 @@
+package v1beta1
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	kvv1 "kubevirt.io/api/core/v1"
+	cdiv1 "kubevirt.io/containerized-data-importer-api/pkg/apis/core/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+func TestVJunos(t *testing.T) {
+	vj := &VJunos{}
+	vj.SetToAppDefVal()
+	vj.Image = ReturnPointerVal("docker://vjunos-router:24.2")
+	vj.StartupConfig = ReturnPointerVal("r1-cfg")
+	lab := newTestCfgPodLab(map[string]*OneOfSystem{"r1": {VJunos: vj}, "peer": {VJunos: &VJunos{}}}, "", "ge-0/0/0", "")
+	lab.Spec.SideCarHookImg = ReturnPointerVal("sidecar:1")
+	if err := vj.Validate(&lab.Spec, "r1"); err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []struct {
+		modify func()
+		errStr string
+	}{
+		{func() { vj.Variant = ReturnPointerVal("firewall") }, "unknown variant"},
+		{func() { lab.Spec.LinkList["link1"].Connectors[0].PortId = ReturnPointerVal("ge-0/0/3") }, "less than number of links"},
+		{func() { lab.Spec.LinkList["link1"].Connectors[0].PortId = ReturnPointerVal("xe-0/0/1") }, "ge-0/0/N"},
+	} {
+		saved := lab.DeepCopy()
+		c.modify()
+		if err := vj.Validate(&lab.Spec, "r1"); err == nil || !strings.Contains(err.Error(), c.errStr) {
+			t.Fatalf("expect error containing %v, got %v", c.errStr, err)
+		}
+		lab.Spec.LinkList = saved.Spec.LinkList
+		vj.Variant = ReturnPointerVal(VJunosVariantRouter)
+	}
+
+	sch := runtime.NewScheme()
+	for _, f := range []func(*runtime.Scheme) error{AddToScheme, kvv1.AddToScheme, cdiv1.AddToScheme} {
+		if err := f(sch); err != nil {
+			t.Fatal(err)
+		}
+	}
+	plab := newTestParsedLab(lab, sch)
+	//link2 is ge-0/0/0, link1 and link3 take the rest in sorted link order
+	spokes := getVJunosOrderedSpokes(plab, "r1")
+	expect := []string{"link2", "link1", "link3"}
+	for i, spokeName := range spokes {
+		if plab.SpokeLinkMap[spokeName] != expect[i] {
+			t.Fatalf("ge-0/0/%d is on %v, expect %v", i, plab.SpokeLinkMap[spokeName], expect[i])
+		}
+	}
+
+	//fake client can't track VMI due to its uint64 fields, VMI is captured on creation
+	var vmi *kvv1.VirtualMachineInstance
+	clnt := fake.NewClientBuilder().WithScheme(sch).WithInterceptorFuncs(interceptor.Funcs{
+		Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+			if r, ok := obj.(*kvv1.VirtualMachineInstance); ok {
+				vmi = r
+				return nil
+			}
+			return c.Create(ctx, obj, opts...)
+		},
+	}).Build()
+	ctx := context.WithValue(context.Background(), ParsedLabKey, plab)
+	if err := vj.Ensure(ctx, "r1", clnt, false); err != nil {
+		t.Fatal(err)
+	}
+	dv := new(cdiv1.DataVolume)
+	if err := clnt.Get(ctx, types.NamespacedName{Namespace: "default", Name: GetVMPCDVName("lab1", "r1")}, dv); err != nil {
+		t.Fatal(err)
+	}
+	if dv.Labels[ChassisNameAnnotation] != "r1" || *dv.Spec.PVC.StorageClassName != "local" || getDVImageURL(dv) != *vj.Image {
+		t.Fatalf("unexpected dv %+v", dv)
+	}
+	if vmi == nil || vmi.Name != GetPodName("lab1", "r1") || len(vmi.OwnerReferences) != 1 {
+		t.Fatalf("vmi is not created, %+v", vmi)
+	}
+	if vmi.Annotations[SMBIOSProductAnnotation] != vjunosRouterProduct || vmi.Spec.Domain.CPU.Cores != 4 {
+		t.Fatalf("unexpected vmi %+v", vmi.ObjectMeta)
+	}
+	disks := vmi.Spec.Domain.Devices.Disks
+	if len(disks) != 2 || disks[1].Name != vjunosCfgVolName || disks[1].Disk.Bus != kvv1.DiskBusUSB {
+		t.Fatalf("unexpected disks %+v", disks)
+	}
+	if vmi.Spec.Volumes[1].ConfigMap == nil || vmi.Spec.Volumes[1].ConfigMap.VolumeLabel != vjunosCfgVolName {
+		t.Fatalf("startup config is not attached, %+v", vmi.Spec.Volumes)
+	}
+	//fxp0 first, then ge-0/0/N in order
+	intfs := vmi.Spec.Domain.Devices.Interfaces
+	if len(intfs) != 4 || intfs[0].Masquerade == nil {
+		t.Fatalf("unexpected interfaces %+v", intfs)
+	}
+	for i, spokeName := range spokes {
+		if net, _ := plab.getVMISpokeNetwork(spokeName); vmi.Spec.Networks[i+1].Name != net.Name {
+			t.Fatalf("network %d is %v, expect %v", i+1, vmi.Spec.Networks[i+1].Name, net.Name)
+		}
+	}
+
+	vj.Variant = ReturnPointerVal(VJunosVariantSwitch)
+	if vj.getSMBIOSProduct() != vjunosSwitchProduct {
+		t.Fatalf("unexpected SMBIOS product for switch")
+	}
+}
@@ -12,7 +12,6 @@ import (
 	"syscall"
 
 	ignitiontypes "github.com/coreos/ignition/v2/config/v3_5/types"
-	k8slan "github.com/hujun-open/k8slan/api/v1beta1"
 	"github.com/tredoe/osutil/user/crypt/sha512_crypt"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
@@ -107,7 +106,6 @@ func (gvm *GeneralVM) Validate(lab *LabSpec, nodeName string) error {
 	return nil
 }
 func (gvm *GeneralVM) Ensure(ctx context.Context, nodeName string, clnt client.Client, forceRemoval bool) error {
-	gconf := GCONF.Get()
 	val := ctx.Value(ParsedLabKey)
 	if val == nil {
 		return MakeErr(fmt.Errorf("failed to get parsed lab obj from context"))
@@ -117,11 +115,20 @@ func (gvm *GeneralVM) Ensure(ctx context.Context, nodeName string, clnt client.C
 	if lab, ok = val.(*ParsedLab); !ok {
 		return MakeErr(fmt.Errorf("context stored value is not a ParsedLabSpec"))
 	}
+	gconf := lab.Lab.GetConfig()
 	//create DV
-	dv := NewDV(lab.Lab.Namespace, lab.Lab.Name,
+	dv := NewDV(lab.Lab.Namespace, lab.Lab.Name, nodeName, VM,
 		GetVMPCDVName(lab.Lab.Name, nodeName),
 		*gvm.Image, gconf.PVCStorageClass, gvm.DiskSize)
-	err := createIfNotExistsOrRemove(ctx, clnt, lab, dv, false, forceRemoval)
+	err := lab.setCachedImageSource(ctx, clnt, dv)
+	if err != nil {
+		return MakeErr(err)
+	}
+	err = lab.setRestoreVolSource(ctx, clnt, dv)
+	if err != nil {
+		return MakeErr(err)
+	}
+	err = createIfNotExistsOrRemove(ctx, clnt, lab, dv, false, forceRemoval)
 	if err != nil {
 		return MakeErr(err)
 	}
@@ -136,7 +143,7 @@ func (gvm *GeneralVM) Ensure(ctx context.Context, nodeName string, clnt client.C
 
 func (gvm *GeneralVM) getVMI(lab *ParsedLab, vmname string) *kvv1.VirtualMachineInstance {
 	// log := ctrl.Log.WithName("Discover")
-	gconf := GCONF.Get()
+	gconf := lab.Lab.GetConfig()
 	r := new(kvv1.VirtualMachineInstance)
 	r.ObjectMeta = GetObjMeta(
 		GetPodName(lab.Lab.Name, vmname),
@@ -150,6 +157,9 @@ func (gvm *GeneralVM) getVMI(lab *ParsedLab, vmname string) *kvv1.VirtualMachine
 		// ChassisTypeAnnotation:   string(VM),
 		KvirtSideCarAnnontation: fmt.Sprintf(`[{"image": "%v"}]`, *gconf.SideCarHookImg),
 	}
+	if gconf.IsConsoleLogEnabled() {
+		r.ObjectMeta.Annotations[ConsoleLogAnnotation] = "true"
+	}
 	r.Spec.Domain.CPU = &kvv1.CPU{
 		Model: "host-passthrough",
 	}
@@ -261,7 +271,7 @@ type=ethernet
 
 [ethernet]
 mac-address=%v
-[ipv4]
+%v[ipv4]
 method=manual
 # Format: IP_ADDRESS/CIDR,GATEWAY
 %v
@@ -328,34 +338,14 @@ method=manual
 		spokes := lab.SpokeMap[vmname][linkName]
 		// for _, spokes := range lab.SpokeMap[vmname] {
 		for _, spokeName := range spokes {
-			lanName := Getk8lanName(lab.Lab.Name, lab.SpokeLinkMap[spokeName])
-			nadName := k8slan.GetDefNADName(lanName, spokeName, false)
-			r.Spec.Networks = append(r.Spec.Networks,
-				kvv1.Network{
-					Name: spokeName,
-					NetworkSource: kvv1.NetworkSource{
-						Multus: &kvv1.MultusNetwork{
-							NetworkName: nadName,
-						},
-					},
-				})
-			spokeIf := kvv1.Interface{
-				Name: spokeName,
-				Binding: &kvv1.PluginBinding{
-					Name: "macvtap",
-				},
-			}
-			if lab.SpokeConnectorMap[spokeName].Mac != nil {
-				spokeIf.MacAddress = *lab.SpokeConnectorMap[spokeName].Mac
-			}
-			r.Spec.Domain.Devices.Interfaces = append(r.Spec.Domain.Devices.Interfaces, spokeIf)
+			addVMISpokeIntf(lab, r, spokeName)
 		}
 	}
 	//assign link address
 	i := 0
 	if links, ok := lab.ConnectorMap[vmname]; ok {
 		for _, linkname := range links {
-			_, c := lab.getLinkandConnector(vmname, linkname)
+			link, c := lab.getLinkandConnector(vmname, linkname)
 			if c != nil {
 				i++
 				if len(c.Addrs) > 0 {
@@ -383,7 +373,11 @@ method=manual
 								v6addstr += fmt.Sprintf("route%d=%v,%v\n", i+1, route.To.String(), route.Via.String())
 							}
 						}
-						connectionFile := encodeDataURL(fmt.Sprintf(nm_file_template, i, *c.Mac, v4addrstr, v6addstr))
+						mtustr := ""
+						if link.MTU != nil {
+							mtustr = fmt.Sprintf("mtu=%d\n", *link.MTU)
+						}
+						connectionFile := encodeDataURL(fmt.Sprintf(nm_file_template, i, *c.Mac, mtustr, v4addrstr, v6addstr))
 						ignitionData.Storage.Files = append(ignitionData.Storage.Files,
 							ignitiontypes.File{
 								Node: ignitiontypes.Node{
@@ -397,7 +391,7 @@ method=manual
 							},
 						)
 					case InitMethod_CLOUDINIT:
-						cloudinitNetowkrCfg.AddConnector(fmt.Sprintf("nic%d", i), c)
+						cloudinitNetowkrCfg.AddConnector(fmt.Sprintf("nic%d", i), c, link.MTU)
 						// if r.Spec.Volumes[initVolIndex].CloudInitNoCloud.NetworkData == "" {
 						// 	r.Spec.Volumes[initVolIndex].CloudInitNoCloud.NetworkData = cloudinitNetworkDataTemplateBase
 						// }
@@ -424,9 +418,20 @@ method=manual
 		buf, _ := json.MarshalIndent(ignitionData, "", "  ")
 		r.Spec.Volumes[initVolIndex].CloudInitConfigDrive.UserData = string(buf)
 	}
+	lab.setLocalAffinity(vmname, &r.ObjectMeta, &r.Spec.Affinity)
 	return r
 }
 
+// addVMISpokeIntf adds the network and interface of the spoke to the VMI
+func addVMISpokeIntf(lab *ParsedLab, r *kvv1.VirtualMachineInstance, spokeName string) {
+	spokeNet, spokeIf := lab.getVMISpokeNetwork(spokeName)
+	r.Spec.Networks = append(r.Spec.Networks, spokeNet)
+	if lab.SpokeConnectorMap[spokeName].Mac != nil {
+		spokeIf.MacAddress = *lab.SpokeConnectorMap[spokeName].Mac
+	}
+	r.Spec.Domain.Devices.Interfaces = append(r.Spec.Domain.Devices.Interfaces, spokeIf)
+}
+
 // this functon generate hash for passwd, used for linux passwd hash provsion
 func genPasswdHash(passwd string) (string, error) {
 	c := sha512_crypt.New()
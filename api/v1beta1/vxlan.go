@@ -0,0 +1,206 @@
+package v1beta1
+
+import (
+	"fmt"
+	"net/netip"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type VxLANMode string
+
+const (
+	VxLANModeMulticast VxLANMode = "multicast"
+	VxLANModeUnicast   VxLANMode = "unicast"
+)
+
+const (
+	DefaultVxLANAgentImage = "nicolaka/netshoot:v0.13"
+	// VxLANAgentName is the name of the DaemonSet programming VxLAN flood lists in unicast mode
+	VxLANAgentName = "knl-vxlan-agent"
+	// VxLANAgentContainer is the container name of the agent pod
+	VxLANAgentContainer = "agent"
+	// VxLANOverhead is the maximum VxLAN encapsulation overhead, IPv6 underlay with VLAN tagged inner frame,
+	// k8slan sizes LAN interfaces as MTU of the VxLAN device minus this
+	VxLANOverhead = 74
+	// DefaultUnderlayMTU is the default MTU of VxLAN devices of workers
+	DefaultUnderlayMTU = 1500
+	hostNetNSDir       = "/var/run/netns"
+	allZeroMAC         = "00:00:00:00:00:00"
+)
+
+// IsVxLANUnicast returns true if VxLAN tunnels use head-end replication instead of multicast
+func (spec KNLConfigSpec) IsVxLANUnicast() bool {
+	return spec.VxLANMode != nil && *spec.VxLANMode == VxLANModeUnicast
+}
+
+// IsVxLANUnderlayIPv4 returns true if the VxLAN underlay is IPv4, which is the family of the VxLAN group address
+func (spec KNLConfigSpec) IsVxLANUnderlayIPv4() bool {
+	if spec.VXLANGrpAddr == nil {
+		return false
+	}
+	addr, err := netip.ParseAddr(*spec.VXLANGrpAddr)
+	return err == nil && addr.Is4()
+}
+
+// GetVxDev returns the VxLAN device of the worker
+func (spec KNLConfigSpec) GetVxDev(worker string) string {
+	if dev, ok := spec.VxDevMap[worker]; ok {
+		return dev
+	}
+	if spec.VXLANDefaultDev == nil {
+		return ""
+	}
+	return *spec.VXLANDefaultDev
+}
+
+// MaxLinkMTU returns the largest MTU of a link the VxLAN underlay could carry
+func (spec KNLConfigSpec) MaxLinkMTU() int32 {
+	underlay := int32(DefaultUnderlayMTU)
+	if spec.UnderlayMTU != nil {
+		underlay = *spec.UnderlayMTU
+	}
+	return underlay - VxLANOverhead
+}
+
+// validateVxLAN checks VxLAN group address is consistent with the VxLAN mode
+func (spec KNLConfigSpec) validateVxLAN() error {
+	if spec.VXLANGrpAddr == nil {
+		return fmt.Errorf("VxLAN group addr not specified")
+	}
+	grp, err := netip.ParseAddr(*spec.VXLANGrpAddr)
+	if err != nil {
+		return fmt.Errorf("%v is not valid VxLAN Group IP addr", *spec.VXLANGrpAddr)
+	}
+	if !grp.IsMulticast() {
+		return fmt.Errorf("VxLAN Group IP addr %v is not a multicast address", *spec.VXLANGrpAddr)
+	}
+	if spec.UnderlayMTU != nil && *spec.UnderlayMTU < MinLinkMTU+VxLANOverhead {
+		return fmt.Errorf("underlay MTU %d is too small to carry VxLAN, minimal is %d", *spec.UnderlayMTU, MinLinkMTU+VxLANOverhead)
+	}
+	mode := VxLANModeMulticast
+	if spec.VxLANMode != nil {
+		mode = *spec.VxLANMode
+	}
+	switch mode {
+	case VxLANModeMulticast:
+		if grp.IsInterfaceLocalMulticast() {
+			return fmt.Errorf("VxLAN Group IP addr %v doesn't reach other workers in multicast mode", *spec.VXLANGrpAddr)
+		}
+	case VxLANModeUnicast:
+		//the group is still needed by the VxLAN interface, it must not reach other workers to avoid duplicated BUM traffic
+		if grp.Is6() && !grp.IsInterfaceLocalMulticast() {
+			return fmt.Errorf("VxLAN Group IP addr %v must be an interface-local multicast address (ff01::/16) in unicast mode", *spec.VXLANGrpAddr)
+		}
+		if spec.VxLANAgentImage == nil {
+			return fmt.Errorf("VxLAN agent image not specified")
+		}
+	default:
+		return fmt.Errorf("unsupported VxLAN mode %v", mode)
+	}
+	return nil
+}
+
+// NewVxLANAgentDaemonSet returns the DaemonSet running an idle pod on every worker in host network,
+// the operator execs into it to discover worker addresses and program flood lists of VxLAN interfaces
+func NewVxLANAgentDaemonSet(image string) *appsv1.DaemonSet {
+	labels := map[string]string{
+		K8SLABELAPPKey: VxLANAgentName,
+	}
+	return &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      VxLANAgentName,
+			Namespace: MYNAMESPACE,
+			Labels:    labels,
+		},
+		Spec: appsv1.DaemonSetSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: labels},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec: corev1.PodSpec{
+					HostNetwork: true,
+					Tolerations: []corev1.Toleration{{Operator: corev1.TolerationOpExists}},
+					Containers: []corev1.Container{
+						{
+							Name:    VxLANAgentContainer,
+							Image:   image,
+							Command: []string{"sh", "-c", strings.Join(podIdleCmds, "\n")},
+							SecurityContext: &corev1.SecurityContext{
+								Privileged: ReturnPointerVal(true),
+							},
+							VolumeMounts: []corev1.VolumeMount{
+								{
+									Name:             "netns",
+									MountPath:        hostNetNSDir,
+									MountPropagation: ReturnPointerVal(corev1.MountPropagationHostToContainer),
+								},
+							},
+						},
+					},
+					Volumes: []corev1.Volume{
+						{
+							Name: "netns",
+							VolumeSource: corev1.VolumeSource{
+								HostPath: &corev1.HostPathVolumeSource{
+									Path: hostNetNSDir,
+									Type: ReturnPointerVal(corev1.HostPathDirectoryOrCreate),
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// GetVxDevAddrCmd returns the command printing global addresses of the VxLAN device
+func GetVxDevAddrCmd(dev string, ipv4 bool) []string {
+	family := "-6"
+	if ipv4 {
+		family = "-4"
+	}
+	return []string{"ip", "-o", family, "addr", "show", "dev", dev, "scope", "global"}
+}
+
+// ParseVxDevAddr returns the first address in output of GetVxDevAddrCmd
+func ParseVxDevAddr(out string) (netip.Addr, error) {
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		for i, f := range fields {
+			if (f == "inet" || f == "inet6") && i+1 < len(fields) {
+				prefix, err := netip.ParsePrefix(fields[i+1])
+				if err != nil {
+					return netip.Addr{}, fmt.Errorf("failed to parse address %v, %w", fields[i+1], err)
+				}
+				return prefix.Addr(), nil
+			}
+		}
+	}
+	return netip.Addr{}, fmt.Errorf("no global address found")
+}
+
+// GenFloodScript returns a shell script setting the flood list of VxLAN interface vxName in netns ns to dsts,
+// by head-end replication entries of all-zero MAC; entries of other unicast addresses are removed,
+// the multicast group entry is kept; nothing is done if the interface doesn't exist on the worker
+func GenFloodScript(ns, vxName string, dsts []netip.Addr) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "ip netns exec %v ip link show %v >/dev/null 2>&1 || exit 0\n", ns, vxName)
+	dstList := make([]string, len(dsts))
+	for i, dst := range dsts {
+		dstList[i] = dst.String()
+	}
+	fmt.Fprintf(&b, "want=\"%v\"\n", strings.Join(dstList, " "))
+	fmt.Fprintf(&b, "for dst in $(ip netns exec %v bridge fdb show dev %v | awk '$1==\"%v\" && $2==\"dst\" {print $3}'); do\n", ns, vxName, allZeroMAC)
+	b.WriteString("  case \" $want \" in *\" $dst \"*) continue;; esac\n")
+	b.WriteString("  case $dst in ff*|22[4-9].*|23[0-9].*) continue;; esac\n")
+	fmt.Fprintf(&b, "  ip netns exec %v bridge fdb del %v dev %v dst $dst\n", ns, allZeroMAC, vxName)
+	b.WriteString("done\n")
+	b.WriteString("for dst in $want; do\n")
+	fmt.Fprintf(&b, "  ip netns exec %v bridge fdb append %v dev %v dst $dst 2>/dev/null || true\n", ns, allZeroMAC, vxName)
+	b.WriteString("done\n")
+	return b.String()
+}
@@ -0,0 +1,136 @@
+package v1beta1
+
+import (
+	"net/netip"
+	"strings"
+	"testing"
+)
+
+func TestValidateVxLAN(t *testing.T) {
+	testList := []struct {
+		mode  VxLANMode
+		grp   string
+		valid bool
+	}{
+		{VxLANModeMulticast, "ff18::100", true},
+		{VxLANModeMulticast, "239.1.1.1", true},
+		{VxLANModeMulticast, "ff01::100", false},
+		{VxLANModeMulticast, "2001:db8::1", false},
+		{VxLANModeUnicast, "ff01::100", true},
+		{VxLANModeUnicast, "ff18::100", false},
+		{VxLANModeUnicast, "239.1.1.1", true},
+		{"broadcast", "ff18::100", false},
+	}
+	for i, c := range testList {
+		conf := DefKNLConfig()
+		conf.VxLANMode = ReturnPointerVal(c.mode)
+		conf.VXLANGrpAddr = ReturnPointerVal(c.grp)
+		err := conf.validateVxLAN()
+		if (err == nil) != c.valid {
+			t.Fatalf("case %d: expect valid %v, got error %v", i, c.valid, err)
+		}
+	}
+	conf := DefKNLConfig()
+	conf.VXLANGrpAddr = ReturnPointerVal("239.1.1.1")
+	if !conf.IsVxLANUnderlayIPv4() || conf.IsVxLANUnicast() {
+		t.Fatalf("expect IPv4 multicast underlay")
+	}
+}
+
+func TestVxFlood(t *testing.T) {
+	out := "3: eth1    inet6 2001:db8::11/64 scope global \\       valid_lft forever preferred_lft forever\n"
+	addr, err := ParseVxDevAddr(out)
+	if err != nil || addr != netip.MustParseAddr("2001:db8::11") {
+		t.Fatalf("unexpected address %v, %v", addr, err)
+	}
+	addr, err = ParseVxDevAddr("2: eth0    inet 192.168.1.10/24 brd 192.168.1.255 scope global eth0\n")
+	if err != nil || addr != netip.MustParseAddr("192.168.1.10") {
+		t.Fatalf("unexpected address %v, %v", addr, err)
+	}
+	if _, err = ParseVxDevAddr(""); err == nil {
+		t.Fatalf("expect error for no address")
+	}
+	script := GenFloodScript("lab1-link1", "vx100", []netip.Addr{netip.MustParseAddr("192.168.1.11"), netip.MustParseAddr("192.168.1.12")})
+	for _, s := range []string{
+		`want="192.168.1.11 192.168.1.12"`,
+		"ip netns exec lab1-link1 bridge fdb append 00:00:00:00:00:00 dev vx100 dst $dst",
+		"ip netns exec lab1-link1 bridge fdb del 00:00:00:00:00:00 dev vx100 dst $dst",
+	} {
+		if !strings.Contains(script, s) {
+			t.Fatalf("script doesn't contain %v:\n%v", s, script)
+		}
+	}
+}
+
+func TestLinkMTU(t *testing.T) {
+	conf := DefKNLConfig()
+	conf.UnderlayMTU = ReturnPointerVal(int32(9000))
+	spec := &LabSpec{
+		LinkList: map[string]*Link{
+			"l1": newTestLink(false, "a", "b"),
+			"l2": newTestLink(true, "a", "b"),
+		},
+	}
+	spec.LinkList["l2"].MTU = ReturnPointerVal(int32(1500))
+	if err := LoadDef(spec, KNLConfigSpec{DefaultNode: &OneOfSystem{}, UnderlayMTU: conf.UnderlayMTU}); err != nil {
+		t.Fatal(err)
+	}
+	if *spec.LinkList["l1"].MTU != 9000-VxLANOverhead || *spec.LinkList["l2"].MTU != 1500 {
+		t.Fatalf("unexpected default MTU %d %d", *spec.LinkList["l1"].MTU, *spec.LinkList["l2"].MTU)
+	}
+	if err := spec.ValidateMTU(conf); err != nil {
+		t.Fatal(err)
+	}
+	spec.LinkList["l1"].MTU = ReturnPointerVal(int32(9000))
+	if err := spec.ValidateMTU(conf); err == nil {
+		t.Fatalf("expect error for MTU exceeding underlay")
+	}
+	//local link is not limited by the underlay
+	spec.LinkList["l2"].MTU = ReturnPointerVal(int32(9000))
+	if err := spec.LinkList["l2"].Validate(); err != nil {
+		t.Fatal(err)
+	}
+	spec.LinkList["l2"].MTU = ReturnPointerVal(int32(9216))
+	if err := spec.LinkList["l2"].Validate(); err == nil {
+		t.Fatalf("expect error for local link MTU exceeding %d", MaxLocalLinkMTU)
+	}
+	conf.UnderlayMTU = ReturnPointerVal(int32(100))
+	if err := conf.validateVxLAN(); err == nil {
+		t.Fatalf("expect error for too small underlay MTU")
+	}
+}
+
+func TestLinkMTUWarnings(t *testing.T) {
+	conf := DefKNLConfig()
+	spec := &LabSpec{
+		NodeList: map[string]*OneOfSystem{
+			"srl1":   {SRL: &SRLinux{}},
+			"pod1":   {Pod: &GeneralPod{}},
+			"dummy1": {Dummy: &Dummy{}},
+			"vm1":    {VM: &GeneralVM{}},
+		},
+		LinkList: map[string]*Link{
+			"l1": newTestLink(false, "srl1", "dummy1"),
+			"l2": newTestLink(false, "pod1", "vm1"),
+			"l3": newTestLink(true, "srl1", "pod1"),
+			"l4": newTestLink(false, "dummy1", "vm1"),
+		},
+	}
+	for _, link := range spec.LinkList {
+		link.MTU = ReturnPointerVal(int32(1400))
+	}
+	spec.LinkList["l4"].Connectors[1].Addrs = []string{"192.168.1.1/24"}
+	//l3 is local, l4 nodes apply the MTU
+	w := spec.MTUWarnings(conf)
+	if len(w) != 2 || !strings.Contains(w[0], "link l1") || !strings.Contains(w[0], "srl1") ||
+		!strings.Contains(w[1], "link l2") || !strings.Contains(w[1], "pod1,vm1") {
+		t.Fatalf("unexpected warnings %v", w)
+	}
+	//MTU the VxLAN underlay gives is not warned
+	for _, link := range spec.LinkList {
+		link.MTU = ReturnPointerVal(conf.MaxLinkMTU())
+	}
+	if w = spec.MTUWarnings(conf); len(w) != 0 {
+		t.Fatalf("unexpected warnings %v", w)
+	}
+}
@@ -0,0 +1,284 @@
+package v1beta1
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"syscall"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func init() {
+	NewSysRegistry[XRdNode] = func() System { return new(XRd) }
+}
+
+const (
+	XRdNode               NodeType = "xrd"
+	DefaultXRdMem         string   = "2Gi"
+	DefaultXRdStorageSize string   = "2Gi"
+	XRdStorageDir         string   = "/xr-storage"
+	XRdFirstBootCfgKey    string   = "first-boot.cfg"
+	xrdFirstBootCfgPath   string   = "/etc/xrd/first-boot.cfg"
+	xrdMgmtInterfaces     string   = "linux:eth0,xr_name=Mg0/RP0/CPU0/0,chksum,snoop_v4,snoop_v4_default_route"
+	XRdVariantCP          string   = "control-plane"
+	XRdVariantVRouter     string   = "vrouter"
+	DefaultXRdHugePages   string   = "3Gi"
+	xrdHugePagesDir       string   = "/dev/hugepages"
+)
+
+var (
+	xrdPortRegex = regexp.MustCompile(`^Gi0/0/0/([0-9]+)$`)
+	pciAddrRegex = regexp.MustCompile(`^([0-9a-fA-F]{4}:)?[0-9a-fA-F]{2}:[0-9a-fA-F]{2}\.[0-7]$`)
+)
+
+// XRd specifies a Cisco XRd container router, either control-plane or vrouter;
+// vrouter uses PCI devices of the worker as data interfaces instead of lab links
+type XRd struct {
+	//XRd control-plane or vrouter container image
+	Image *string `json:"image,omitempty"`
+	//control-plane or vrouter, default is control-plane
+	// +optional
+	// +nullable
+	Variant *string `json:"variant,omitempty"`
+	//worker the vrouter runs on, it has the PCI devices
+	// +optional
+	// +nullable
+	Worker *string `json:"worker,omitempty"`
+	//PCI addresses of the vrouter data interfaces, they are passed to XR_INTERFACES in order;
+	//the devices must be bound to vfio-pci on the worker
+	// +optional
+	PCIAddrs []string `json:"pciAddrs,omitempty"`
+	//1Gi hugepages of the vrouter in k8s resource unit
+	// +optional
+	// +nullable
+	HugePages *resource.Quantity `json:"hugePages,omitempty"`
+	//a k8s configmap in the lab namespace with key "first-boot.cfg", it is applied via XR_FIRST_BOOT_CONFIG on first boot
+	// +optional
+	// +nullable
+	FirstBootConfig *string `json:"firstBootConfig,omitempty"`
+	//size of the pvc mounted on /xr-storage
+	// +optional
+	// +nullable
+	StorageSize *resource.Quantity `json:"storageSize,omitempty"`
+	//requested memory in k8s resource unit
+	// +optional
+	// +nullable
+	ReqMemory *resource.Quantity `json:"memory,omitempty"`
+	//requested cpu in k8s resource unit
+	// +optional
+	// +nullable
+	ReqCPU *resource.Quantity `json:"cpu,omitempty"`
+}
+
+func (xrd *XRd) SetToAppDefVal() {
+	xrd.Variant = ReturnPointerVal(XRdVariantCP)
+	xrd.StorageSize = ReturnPointerVal(resource.MustParse(DefaultXRdStorageSize))
+	xrd.ReqMemory = ReturnPointerVal(resource.MustParse(DefaultXRdMem))
+}
+
+func (xrd *XRd) FillDefaultVal(nodeName string) {
+	if xrd.isVRouter() && xrd.HugePages == nil {
+		xrd.HugePages = ReturnPointerVal(resource.MustParse(DefaultXRdHugePages))
+	}
+}
+
+// isVRouter returns true if it is the vrouter variant
+func (xrd *XRd) isVRouter() bool {
+	return xrd.Variant != nil && *xrd.Variant == XRdVariantVRouter
+}
+
+func (xrd *XRd) Validate(lab *LabSpec, nodeName string) error {
+	if xrd.Image == nil {
+		return fmt.Errorf("image not specified")
+	}
+	if xrd.StorageSize == nil {
+		return fmt.Errorf("storageSize not specified")
+	}
+	if xrd.Variant != nil && *xrd.Variant != XRdVariantCP && *xrd.Variant != XRdVariantVRouter {
+		return fmt.Errorf("unknown variant %v, expect %v or %v", *xrd.Variant, XRdVariantCP, XRdVariantVRouter)
+	}
+	if !xrd.isVRouter() {
+		if xrd.Worker != nil || len(xrd.PCIAddrs) > 0 || xrd.HugePages != nil {
+			return fmt.Errorf("worker, pciAddrs and hugePages are only for %v", XRdVariantVRouter)
+		}
+		return xrd.validatePorts(lab, nodeName)
+	}
+	if isStrNotSpecfied(xrd.Worker) {
+		return fmt.Errorf("worker not specified")
+	}
+	if len(xrd.PCIAddrs) == 0 {
+		return fmt.Errorf("pciAddrs not specified")
+	}
+	used := make(map[string]bool)
+	for _, addr := range xrd.PCIAddrs {
+		if !pciAddrRegex.MatchString(addr) {
+			return fmt.Errorf("invalid PCI address %v, expect [DDDD:]BB:DD.F", addr)
+		}
+		if used[addr] {
+			return fmt.Errorf("duplicate PCI address %v", addr)
+		}
+		used[addr] = true
+	}
+	if xrd.HugePages == nil {
+		return fmt.Errorf("hugePages not specified")
+	}
+	for _, linkName := range GetSortedKeySlice(lab.LinkList) {
+		for _, c := range lab.LinkList[linkName].Connectors {
+			if c.IsNode(nodeName) {
+				return fmt.Errorf("link %v is not supported, data interfaces of %v are PCI devices", linkName, XRdVariantVRouter)
+			}
+		}
+	}
+	return nil
+}
+
+func (xrd *XRd) validatePorts(lab *LabSpec, nodeName string) error {
+	return validatePodPortIds(lab, nodeName, func(portId string) error {
+		if !xrdPortRegex.MatchString(portId) {
+			return fmt.Errorf("expect Gi0/0/0/N")
+		}
+		return nil
+	})
+}
+
+// xrdLinuxIntfName returns the linux interface name of XR interface, e.g. Gi0-0-0-1 for Gi0/0/0/1
+func xrdLinuxIntfName(xrName string) string {
+	return strings.ReplaceAll(xrName, "/", "-")
+}
+
+// getXRInterfaces returns value of XR_INTERFACES env for the linux interfaces
+func getXRInterfaces(intfList []string) string {
+	var r []string
+	for _, intf := range intfList {
+		r = append(r, fmt.Sprintf("linux:%v,xr_name=%v", intf, strings.ReplaceAll(intf, "-", "/")))
+	}
+	return strings.Join(r, ";")
+}
+
+// getXRPCIInterfaces returns value of XR_INTERFACES env of vrouter for the PCI devices
+func getXRPCIInterfaces(pciAddrs []string) string {
+	var r []string
+	for _, addr := range pciAddrs {
+		r = append(r, "pci:"+addr)
+	}
+	return strings.Join(r, ";")
+}
+
+func (xrd *XRd) Ensure(ctx context.Context, nodeName string, clnt client.Client, forceRemoval bool) error {
+	lab, err := getParsedLab(ctx)
+	if err != nil {
+		return err
+	}
+	//namespaced sysctls XR requires are set by privileged init container
+	pod, intfList, err := lab.newCfgPod(ctx, clnt, nodeName, &cfgPodSpec{
+		NodeType:       XRdNode,
+		Image:          *xrd.Image,
+		PVCSuffix:      "storage",
+		PVCSize:        *xrd.StorageSize,
+		MountPath:      XRdStorageDir,
+		InitCmds:       []string{"sysctl -w net.ipv4.ip_forward=1 net.ipv6.conf.all.forwarding=1 net.ipv6.conf.all.disable_ipv6=0 net.ipv4.conf.all.rp_filter=0"},
+		InitPrivileged: true,
+		//linux interface is named after XR interface, e.g. Gi0-0-0-0 for Gi0/0/0/0
+		AutoIntfName: func(i int) string { return xrdLinuxIntfName("Gi0/0/0/" + strconv.Itoa(i-1)) },
+		PortIntfName: xrdLinuxIntfName,
+		ReqCPU:       xrd.ReqCPU,
+		ReqMemory:    xrd.ReqMemory,
+	})
+	if err != nil {
+		return err
+	}
+	//ping_group_range is a safe sysctl
+	pod.Spec.SecurityContext = &corev1.PodSecurityContext{
+		Sysctls: []corev1.Sysctl{
+			{Name: "net.ipv4.ping_group_range", Value: "0 2147483647"},
+		},
+	}
+	pod.Spec.Containers[0].TTY = true
+	pod.Spec.Containers[0].Stdin = true
+	xrIntfs := getXRInterfaces(intfList)
+	if xrd.isVRouter() {
+		xrIntfs = getXRPCIInterfaces(xrd.PCIAddrs)
+		xrd.setVRouter(pod)
+	}
+	pod.Spec.Containers[0].Env = []corev1.EnvVar{
+		{Name: "XR_MGMT_INTERFACES", Value: xrdMgmtInterfaces},
+		{Name: "XR_INTERFACES", Value: xrIntfs},
+	}
+	if xrd.FirstBootConfig != nil {
+		pod.Spec.Containers[0].Env = append(pod.Spec.Containers[0].Env,
+			corev1.EnvVar{Name: "XR_FIRST_BOOT_CONFIG", Value: xrdFirstBootCfgPath})
+		addCfgMapVolume(pod, &pod.Spec.Containers[0], "cfg", *xrd.FirstBootConfig, xrdFirstBootCfgPath, XRdFirstBootCfgKey)
+	}
+	err = createIfNotExistsOrRemove(ctx, clnt, lab, pod, true, false)
+	if err != nil {
+		return fmt.Errorf("failed to create XRd pod %v in lab %v, %w", nodeName, lab.Lab.Name, err)
+	}
+	return nil
+}
+
+// setVRouter pins the vrouter pod to its worker and sets hugepages for the DPDK data plane
+func (xrd *XRd) setVRouter(pod *corev1.Pod) {
+	pod.Spec.NodeName = *xrd.Worker
+	//hugepages request must equal to the limit
+	hugePagesRes := corev1.ResourceName(corev1.ResourceHugePagesPrefix + "1Gi")
+	pod.Spec.Containers[0].Resources.Requests[hugePagesRes] = *xrd.HugePages
+	pod.Spec.Containers[0].Resources.Limits[hugePagesRes] = *xrd.HugePages
+	pod.Spec.Containers[0].VolumeMounts = append(pod.Spec.Containers[0].VolumeMounts, corev1.VolumeMount{
+		Name:      "hugepages",
+		MountPath: xrdHugePagesDir,
+	})
+	pod.Spec.Volumes = append(pod.Spec.Volumes, corev1.Volume{
+		Name: "hugepages",
+		VolumeSource: corev1.VolumeSource{
+			EmptyDir: &corev1.EmptyDirVolumeSource{
+				Medium: corev1.StorageMediumHugePagesPrefix + "1Gi",
+			},
+		},
+	})
+}
+
+// Shell runs XR CLI in the pod
+// GetCfgSnapshotSource returns paths under /xr-storage to collect, they must be specified,
+// since the whole folder is the XR disk and too big to restore
+func (xrd *XRd) GetCfgSnapshotSource(labName, nodeName string, paths []string) (*CfgSnapshotSource, error) {
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("paths under %v must be specified", XRdStorageDir)
+	}
+	plist, err := getCfgSnapshotPaths(XRdStorageDir, XRdStorageDir, paths, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &CfgSnapshotSource{
+		PodName:   GetPodName(labName, nodeName),
+		Container: "main",
+		RootDir:   XRdStorageDir,
+		Paths:     plist,
+	}, nil
+}
+
+func (xrd *XRd) Shell(ctx context.Context, clnt client.Client, ns, lab, chassis, username string) {
+	envList := []string{fmt.Sprintf("HOME=%v", os.Getenv("HOME"))}
+	fmt.Printf("connecting to %v\n", GetPodName(lab, chassis))
+	syscall.Exec("/bin/sh",
+		[]string{"sh", "-c",
+			fmt.Sprintf("kubectl -n %v exec -it %v -- /pkg/bin/xr_cli.sh",
+				ns, GetPodName(lab, chassis))},
+		envList)
+}
+
+// Console attaches to the XR console, which is the pod's tty
+func (xrd *XRd) Console(ctx context.Context, clnt client.Client, ns, lab, chassis string) {
+	envList := []string{fmt.Sprintf("HOME=%v", os.Getenv("HOME"))}
+	fmt.Printf("connecting to %v\n", GetPodName(lab, chassis))
+	syscall.Exec("/bin/sh",
+		[]string{"sh", "-c",
+			fmt.Sprintf("kubectl -n %v attach -it %v",
+				ns, GetPodName(lab, chassis))},
+		envList)
+}
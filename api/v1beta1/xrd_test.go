@@ -0,0 +1,124 @@
+package v1beta1
+
+import (
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestXRdControlPlane(t *testing.T) {
+	xrd := &XRd{}
+	xrd.SetToAppDefVal()
+	xrd.Image = ReturnPointerVal("xrd-control-plane:24.2.1")
+	xrd.FirstBootConfig = ReturnPointerVal("r1-cfg")
+	lab := newTestCfgPodLab(map[string]*OneOfSystem{"r1": {XRd: xrd}, "peer": {XRd: &XRd{}}}, "", "Gi0/0/0/0")
+	xrd.FillDefaultVal("r1")
+	if xrd.HugePages != nil {
+		t.Fatalf("unexpected hugepages for %v", XRdVariantCP)
+	}
+	for _, c := range []struct {
+		modify func()
+		errStr string
+	}{
+		{func() { lab.Spec.LinkList["link1"].Connectors[0].PortId = ReturnPointerVal("Gi0-0-0-1") }, "Gi0/0/0/N"},
+		{func() { xrd.Variant = ReturnPointerVal("xrv9k") }, "unknown variant"},
+		{func() { xrd.PCIAddrs = []string{"00:09.0"} }, "only for vrouter"},
+	} {
+		saved := xrd.DeepCopy()
+		c.modify()
+		if err := xrd.Validate(&lab.Spec, "r1"); err == nil || !strings.Contains(err.Error(), c.errStr) {
+			t.Fatalf("expect error containing %v, got %v", c.errStr, err)
+		}
+		*xrd = *saved
+		lab.Spec.LinkList["link1"].Connectors[0].PortId = nil
+	}
+	pod, clnt := ensureTestNode(t, lab, "r1")
+	checkCfgPod(t, pod, clnt, "lab1-r1-storage", XRdStorageDir)
+	env := map[string]string{}
+	for _, e := range pod.Spec.Containers[0].Env {
+		env[e.Name] = e.Value
+	}
+	//link2 is Gi0/0/0/0, link1 takes the next
+	if env["XR_INTERFACES"] != "linux:Gi0-0-0-1,xr_name=Gi0/0/0/1;linux:Gi0-0-0-0,xr_name=Gi0/0/0/0" {
+		t.Fatalf("unexpected XR_INTERFACES %v", env["XR_INTERFACES"])
+	}
+	if env["XR_MGMT_INTERFACES"] != xrdMgmtInterfaces || env["XR_FIRST_BOOT_CONFIG"] != xrdFirstBootCfgPath {
+		t.Fatalf("unexpected env %v", env)
+	}
+	init := pod.Spec.InitContainers[0]
+	if !*init.SecurityContext.Privileged || !strings.Contains(init.Command[2], "net.ipv4.conf.all.rp_filter=0") {
+		t.Fatalf("unexpected init container %+v", init)
+	}
+	if pod.Spec.SecurityContext.Sysctls[0].Name != "net.ipv4.ping_group_range" || !pod.Spec.Containers[0].TTY {
+		t.Fatalf("unexpected pod %+v", pod.Spec)
+	}
+	mounts := pod.Spec.Containers[0].VolumeMounts
+	if m := mounts[len(mounts)-1]; m.MountPath != xrdFirstBootCfgPath || m.SubPath != XRdFirstBootCfgKey {
+		t.Fatalf("first boot config is not mounted, %+v", mounts)
+	}
+	if pod.Spec.NodeName != "" {
+		t.Fatalf("unexpected worker %v", pod.Spec.NodeName)
+	}
+}
+
+func TestXRdVRouter(t *testing.T) {
+	xrd := &XRd{}
+	xrd.SetToAppDefVal()
+	xrd.Image = ReturnPointerVal("xrd-vrouter:24.2.1")
+	xrd.Variant = ReturnPointerVal(XRdVariantVRouter)
+	xrd.Worker = ReturnPointerVal("w1")
+	xrd.PCIAddrs = []string{"00:09.0", "0000:00:0a.0"}
+	xrd.FillDefaultVal("r1")
+	if xrd.HugePages == nil || xrd.HugePages.String() != DefaultXRdHugePages {
+		t.Fatalf("unexpected default hugepages %v", xrd.HugePages)
+	}
+	lab := newTestCfgPodLab(map[string]*OneOfSystem{"r1": {XRd: xrd}})
+	for _, c := range []struct {
+		modify func()
+		errStr string
+	}{
+		{func() { xrd.Worker = nil }, "worker not specified"},
+		{func() { xrd.PCIAddrs = nil }, "pciAddrs not specified"},
+		{func() { xrd.PCIAddrs = []string{"eth1"} }, "invalid PCI address"},
+		{func() { xrd.PCIAddrs = []string{"00:09.0", "00:09.0"} }, "duplicate PCI address"},
+		{func() {
+			lab.Spec.LinkList["link1"] = &Link{Connectors: []Connector{{NodeName: ReturnPointerVal("r1")}, {NodeName: ReturnPointerVal("peer")}}}
+		}, "link link1 is not supported"},
+	} {
+		saved := xrd.DeepCopy()
+		c.modify()
+		if err := xrd.Validate(&lab.Spec, "r1"); err == nil || !strings.Contains(err.Error(), c.errStr) {
+			t.Fatalf("expect error containing %v, got %v", c.errStr, err)
+		}
+		*xrd = *saved
+		lab.Spec.LinkList = map[string]*Link{}
+	}
+	xrd.HugePages = ReturnPointerVal(resource.MustParse("4Gi"))
+	pod, clnt := ensureTestNode(t, lab, "r1")
+	checkCfgPod(t, pod, clnt, "lab1-r1-storage", XRdStorageDir)
+	if pod.Spec.NodeName != "w1" {
+		t.Fatalf("vrouter is not pinned to its worker, %v", pod.Spec.NodeName)
+	}
+	for _, e := range pod.Spec.Containers[0].Env {
+		if e.Name == "XR_INTERFACES" && e.Value != "pci:00:09.0;pci:0000:00:0a.0" {
+			t.Fatalf("unexpected XR_INTERFACES %v", e.Value)
+		}
+	}
+	if _, ok := pod.Annotations[MultusAnnoKey]; ok {
+		t.Fatalf("unexpected multus annotation %v", pod.Annotations)
+	}
+	res := pod.Spec.Containers[0].Resources
+	hugePagesRes := corev1.ResourceName(corev1.ResourceHugePagesPrefix + "1Gi")
+	if q := res.Requests[hugePagesRes]; q.String() != "4Gi" {
+		t.Fatalf("unexpected hugepages request %v", res.Requests)
+	}
+	if q := res.Limits[hugePagesRes]; q.String() != "4Gi" {
+		t.Fatalf("unexpected hugepages limit %v", res.Limits)
+	}
+	vol := pod.Spec.Volumes[len(pod.Spec.Volumes)-1]
+	if vol.EmptyDir == nil || vol.EmptyDir.Medium != corev1.StorageMediumHugePagesPrefix+"1Gi" {
+		t.Fatalf("hugepages volume is not added, %+v", pod.Spec.Volumes)
+	}
+}
@@ -26,6 +26,151 @@ import (
 	corev1 "kubevirt.io/api/core/v1"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CEOS) DeepCopyInto(out *CEOS) {
+	*out = *in
+	if in.Image != nil {
+		in, out := &in.Image, &out.Image
+		*out = new(string)
+		**out = **in
+	}
+	if in.StartupConfig != nil {
+		in, out := &in.StartupConfig, &out.StartupConfig
+		*out = new(string)
+		**out = **in
+	}
+	if in.FlashSize != nil {
+		in, out := &in.FlashSize, &out.FlashSize
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.Env != nil {
+		in, out := &in.Env, &out.Env
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ReqMemory != nil {
+		in, out := &in.ReqMemory, &out.ReqMemory
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.ReqCPU != nil {
+		in, out := &in.ReqCPU, &out.ReqCPU
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CEOS.
+func (in *CEOS) DeepCopy() *CEOS {
+	if in == nil {
+		return nil
+	}
+	out := new(CEOS)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CRPD) DeepCopyInto(out *CRPD) {
+	*out = *in
+	if in.Image != nil {
+		in, out := &in.Image, &out.Image
+		*out = new(string)
+		**out = **in
+	}
+	if in.StartupConfig != nil {
+		in, out := &in.StartupConfig, &out.StartupConfig
+		*out = new(string)
+		**out = **in
+	}
+	if in.License != nil {
+		in, out := &in.License, &out.License
+		*out = new(string)
+		**out = **in
+	}
+	if in.ReqMemory != nil {
+		in, out := &in.ReqMemory, &out.ReqMemory
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.ReqCPU != nil {
+		in, out := &in.ReqCPU, &out.ReqCPU
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CRPD.
+func (in *CRPD) DeepCopy() *CRPD {
+	if in == nil {
+		return nil
+	}
+	out := new(CRPD)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterNodeProfile) DeepCopyInto(out *ClusterNodeProfile) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterNodeProfile.
+func (in *ClusterNodeProfile) DeepCopy() *ClusterNodeProfile {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterNodeProfile)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterNodeProfile) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterNodeProfileList) DeepCopyInto(out *ClusterNodeProfileList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ClusterNodeProfile, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterNodeProfileList.
+func (in *ClusterNodeProfileList) DeepCopy() *ClusterNodeProfileList {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterNodeProfileList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterNodeProfileList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Connector) DeepCopyInto(out *Connector) {
 	*out = *in
@@ -34,6 +179,21 @@ func (in *Connector) DeepCopyInto(out *Connector) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.Kind != nil {
+		in, out := &in.Kind, &out.Kind
+		*out = new(ConnectorKind)
+		**out = **in
+	}
+	if in.PeerLab != nil {
+		in, out := &in.PeerLab, &out.PeerLab
+		*out = new(PeerLab)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.External != nil {
+		in, out := &in.External, &out.External
+		*out = new(ExternalConnector)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.PortId != nil {
 		in, out := &in.PortId, &out.PortId
 		*out = new(string)
@@ -66,6 +226,146 @@ func (in *Connector) DeepCopy() *Connector {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConsoleLogConfig) DeepCopyInto(out *ConsoleLogConfig) {
+	*out = *in
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.MaxSize != nil {
+		in, out := &in.MaxSize, &out.MaxSize
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.MaxBackups != nil {
+		in, out := &in.MaxBackups, &out.MaxBackups
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConsoleLogConfig.
+func (in *ConsoleLogConfig) DeepCopy() *ConsoleLogConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ConsoleLogConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CrossLabLink) DeepCopyInto(out *CrossLabLink) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CrossLabLink.
+func (in *CrossLabLink) DeepCopy() *CrossLabLink {
+	if in == nil {
+		return nil
+	}
+	out := new(CrossLabLink)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Dummy) DeepCopyInto(out *Dummy) {
+	*out = *in
+	if in.Image != nil {
+		in, out := &in.Image, &out.Image
+		*out = new(string)
+		**out = **in
+	}
+	if in.ReplayPortId != nil {
+		in, out := &in.ReplayPortId, &out.ReplayPortId
+		*out = new(bool)
+		**out = **in
+	}
+	if in.ReqMemory != nil {
+		in, out := &in.ReqMemory, &out.ReqMemory
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.ReqCPU != nil {
+		in, out := &in.ReqCPU, &out.ReqCPU
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Dummy.
+func (in *Dummy) DeepCopy() *Dummy {
+	if in == nil {
+		return nil
+	}
+	out := new(Dummy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExternalConnector) DeepCopyInto(out *ExternalConnector) {
+	*out = *in
+	if in.VLAN != nil {
+		in, out := &in.VLAN, &out.VLAN
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Mode != nil {
+		in, out := &in.Mode, &out.Mode
+		*out = new(ExternalMode)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExternalConnector.
+func (in *ExternalConnector) DeepCopy() *ExternalConnector {
+	if in == nil {
+		return nil
+	}
+	out := new(ExternalConnector)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FRR) DeepCopyInto(out *FRR) {
+	*out = *in
+	if in.Image != nil {
+		in, out := &in.Image, &out.Image
+		*out = new(string)
+		**out = **in
+	}
+	if in.Config != nil {
+		in, out := &in.Config, &out.Config
+		*out = new(string)
+		**out = **in
+	}
+	if in.ReqMemory != nil {
+		in, out := &in.ReqMemory, &out.ReqMemory
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.ReqCPU != nil {
+		in, out := &in.ReqCPU, &out.ReqCPU
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FRR.
+func (in *FRR) DeepCopy() *FRR {
+	if in == nil {
+		return nil
+	}
+	out := new(FRR)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GeneralPod) DeepCopyInto(out *GeneralPod) {
 	*out = *in
@@ -181,7 +481,37 @@ func (in *GeneralVM) DeepCopy() *GeneralVM {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *KNLConfig) DeepCopyInto(out *KNLConfig) {
+func (in *GeneratorRole) DeepCopyInto(out *GeneratorRole) {
+	*out = *in
+	if in.Start != nil {
+		in, out := &in.Start, &out.Start
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Template != nil {
+		in, out := &in.Template, &out.Template
+		*out = new(OneOfSystem)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PortPattern != nil {
+		in, out := &in.PortPattern, &out.PortPattern
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GeneratorRole.
+func (in *GeneratorRole) DeepCopy() *GeneratorRole {
+	if in == nil {
+		return nil
+	}
+	out := new(GeneratorRole)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GoldenImage) DeepCopyInto(out *GoldenImage) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
@@ -189,18 +519,18 @@ func (in *KNLConfig) DeepCopyInto(out *KNLConfig) {
 	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KNLConfig.
-func (in *KNLConfig) DeepCopy() *KNLConfig {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GoldenImage.
+func (in *GoldenImage) DeepCopy() *GoldenImage {
 	if in == nil {
 		return nil
 	}
-	out := new(KNLConfig)
+	out := new(GoldenImage)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *KNLConfig) DeepCopyObject() runtime.Object {
+func (in *GoldenImage) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -208,25 +538,164 @@ func (in *KNLConfig) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *KNLConfigList) DeepCopyInto(out *KNLConfigList) {
+func (in *GoldenImageList) DeepCopyInto(out *GoldenImageList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]KNLConfig, len(*in))
+		*out = make([]GoldenImage, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KNLConfigList.
-func (in *KNLConfigList) DeepCopy() *KNLConfigList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GoldenImageList.
+func (in *GoldenImageList) DeepCopy() *GoldenImageList {
 	if in == nil {
 		return nil
 	}
-	out := new(KNLConfigList)
+	out := new(GoldenImageList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *GoldenImageList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GoldenImageSpec) DeepCopyInto(out *GoldenImageSpec) {
+	*out = *in
+	out.Size = in.Size.DeepCopy()
+	if in.Retain != nil {
+		in, out := &in.Retain, &out.Retain
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Config != nil {
+		in, out := &in.Config, &out.Config
+		*out = new(string)
+		**out = **in
+	}
+	if in.StorageClass != nil {
+		in, out := &in.StorageClass, &out.StorageClass
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GoldenImageSpec.
+func (in *GoldenImageSpec) DeepCopy() *GoldenImageSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GoldenImageSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GoldenImageStatus) DeepCopyInto(out *GoldenImageStatus) {
+	*out = *in
+	if in.Users != nil {
+		in, out := &in.Users, &out.Users
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.LastUsed != nil {
+		in, out := &in.LastUsed, &out.LastUsed
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GoldenImageStatus.
+func (in *GoldenImageStatus) DeepCopy() *GoldenImageStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(GoldenImageStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImageCacheConfig) DeepCopyInto(out *ImageCacheConfig) {
+	*out = *in
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.GCAfter != nil {
+		in, out := &in.GCAfter, &out.GCAfter
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageCacheConfig.
+func (in *ImageCacheConfig) DeepCopy() *ImageCacheConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ImageCacheConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KNLConfig) DeepCopyInto(out *KNLConfig) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KNLConfig.
+func (in *KNLConfig) DeepCopy() *KNLConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(KNLConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KNLConfig) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KNLConfigList) DeepCopyInto(out *KNLConfigList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]KNLConfig, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KNLConfigList.
+func (in *KNLConfigList) DeepCopy() *KNLConfigList {
+	if in == nil {
+		return nil
+	}
+	out := new(KNLConfigList)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -252,6 +721,37 @@ func (in *KNLConfigSpec) DeepCopyInto(out *KNLConfigSpec) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.VxLANMode != nil {
+		in, out := &in.VxLANMode, &out.VxLANMode
+		*out = new(VxLANMode)
+		**out = **in
+	}
+	if in.VxLANAgentImage != nil {
+		in, out := &in.VxLANAgentImage, &out.VxLANAgentImage
+		*out = new(string)
+		**out = **in
+	}
+	if in.UnderlayMTU != nil {
+		in, out := &in.UnderlayMTU, &out.UnderlayMTU
+		*out = new(int32)
+		**out = **in
+	}
+	if in.ExternalIntfs != nil {
+		in, out := &in.ExternalIntfs, &out.ExternalIntfs
+		*out = make(map[string][]string, len(*in))
+		for key, val := range *in {
+			var outVal []string
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				inVal := (*in)[key]
+				in, out := &inVal, &outVal
+				*out = make([]string, len(*in))
+				copy(*out, *in)
+			}
+			(*out)[key] = outVal
+		}
+	}
 	if in.VXLANDefaultDev != nil {
 		in, out := &in.VXLANDefaultDev, &out.VXLANDefaultDev
 		*out = new(string)
@@ -269,6 +769,11 @@ func (in *KNLConfigSpec) DeepCopyInto(out *KNLConfigSpec) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.VolumeSnapshotClass != nil {
+		in, out := &in.VolumeSnapshotClass, &out.VolumeSnapshotClass
+		*out = new(string)
+		**out = **in
+	}
 	if in.SRCPMLoaderImage != nil {
 		in, out := &in.SRCPMLoaderImage, &out.SRCPMLoaderImage
 		*out = new(string)
@@ -289,6 +794,16 @@ func (in *KNLConfigSpec) DeepCopyInto(out *KNLConfigSpec) {
 		*out = new(OneOfSystem)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.ConsoleLog != nil {
+		in, out := &in.ConsoleLog, &out.ConsoleLog
+		*out = new(ConsoleLogConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ImageCache != nil {
+		in, out := &in.ImageCache, &out.ImageCache
+		*out = new(ImageCacheConfig)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KNLConfigSpec.
@@ -309,6 +824,11 @@ func (in *KNLConfigStatus) DeepCopyInto(out *KNLConfigStatus) {
 		*out = new(int64)
 		**out = **in
 	}
+	if in.Labs != nil {
+		in, out := &in.Labs, &out.Labs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KNLConfigStatus.
@@ -381,219 +901,1228 @@ func (in *LabList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *LabSpec) DeepCopyInto(out *LabSpec) {
+func (in *LabSnapshot) DeepCopyInto(out *LabSnapshot) {
 	*out = *in
-	if in.NodeList != nil {
-		in, out := &in.NodeList, &out.NodeList
-		*out = make(map[string]*OneOfSystem, len(*in))
-		for key, val := range *in {
-			var outVal *OneOfSystem
-			if val == nil {
-				(*out)[key] = nil
-			} else {
-				inVal := (*in)[key]
-				in, out := &inVal, &outVal
-				*out = new(OneOfSystem)
-				(*in).DeepCopyInto(*out)
-			}
-			(*out)[key] = outVal
-		}
-	}
-	if in.LinkList != nil {
-		in, out := &in.LinkList, &out.LinkList
-		*out = make(map[string]*Link, len(*in))
-		for key, val := range *in {
-			var outVal *Link
-			if val == nil {
-				(*out)[key] = nil
-			} else {
-				inVal := (*in)[key]
-				in, out := &inVal, &outVal
-				*out = new(Link)
-				(*in).DeepCopyInto(*out)
-			}
-			(*out)[key] = outVal
-		}
-	}
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LabSpec.
-func (in *LabSpec) DeepCopy() *LabSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LabSnapshot.
+func (in *LabSnapshot) DeepCopy() *LabSnapshot {
 	if in == nil {
 		return nil
 	}
-	out := new(LabSpec)
+	out := new(LabSnapshot)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *LabSnapshot) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *LabStatus) DeepCopyInto(out *LabStatus) {
+func (in *LabSnapshotList) DeepCopyInto(out *LabSnapshotList) {
 	*out = *in
-	if in.Conditions != nil {
-		in, out := &in.Conditions, &out.Conditions
-		*out = make([]v1.Condition, len(*in))
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]LabSnapshot, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LabStatus.
-func (in *LabStatus) DeepCopy() *LabStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LabSnapshotList.
+func (in *LabSnapshotList) DeepCopy() *LabSnapshotList {
 	if in == nil {
 		return nil
 	}
-	out := new(LabStatus)
+	out := new(LabSnapshotList)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *LabSnapshotList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Link) DeepCopyInto(out *Link) {
+func (in *LabSnapshotSpec) DeepCopyInto(out *LabSnapshotSpec) {
 	*out = *in
-	if in.Connectors != nil {
-		in, out := &in.Connectors, &out.Connectors
-		*out = make([]Connector, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
+	if in.Nodes != nil {
+		in, out := &in.Nodes, &out.Nodes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Paths != nil {
+		in, out := &in.Paths, &out.Paths
+		*out = make(map[string][]string, len(*in))
+		for key, val := range *in {
+			var outVal []string
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				inVal := (*in)[key]
+				in, out := &inVal, &outVal
+				*out = make([]string, len(*in))
+				copy(*out, *in)
+			}
+			(*out)[key] = outVal
 		}
 	}
+	if in.Disk != nil {
+		in, out := &in.Disk, &out.Disk
+		*out = new(bool)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Link.
-func (in *Link) DeepCopy() *Link {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LabSnapshotSpec.
+func (in *LabSnapshotSpec) DeepCopy() *LabSnapshotSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(Link)
+	out := new(LabSnapshotSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *MAGC) DeepCopyInto(out *MAGC) {
+func (in *LabSnapshotStatus) DeepCopyInto(out *LabSnapshotStatus) {
 	*out = *in
-	if in.Chassis != nil {
-		in, out := &in.Chassis, &out.Chassis
-		*out = new(SRChassis)
-		(*in).DeepCopyInto(*out)
+	if in.Nodes != nil {
+		in, out := &in.Nodes, &out.Nodes
+		*out = make(map[string]NodeSnapshotStatus, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
 	}
-	if in.Image != nil {
-		in, out := &in.Image, &out.Image
-		*out = new(string)
-		**out = **in
+	if in.Volumes != nil {
+		in, out := &in.Volumes, &out.Volumes
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
 	}
-	if in.DiskSize != nil {
-		in, out := &in.DiskSize, &out.DiskSize
-		x := (*in).DeepCopy()
-		*out = &x
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
 	}
-	if in.License != nil {
-		in, out := &in.License, &out.License
-		*out = new(string)
-		**out = **in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LabSnapshotStatus.
+func (in *LabSnapshotStatus) DeepCopy() *LabSnapshotStatus {
+	if in == nil {
+		return nil
 	}
-	if in.UUID != nil {
-		in, out := &in.UUID, &out.UUID
-		*out = new(string)
-		**out = **in
+	out := new(LabSnapshotStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LabSpec) DeepCopyInto(out *LabSpec) {
+	*out = *in
+	if in.NodeList != nil {
+		in, out := &in.NodeList, &out.NodeList
+		*out = make(map[string]*OneOfSystem, len(*in))
+		for key, val := range *in {
+			var outVal *OneOfSystem
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				inVal := (*in)[key]
+				in, out := &inVal, &outVal
+				*out = new(OneOfSystem)
+				(*in).DeepCopyInto(*out)
+			}
+			(*out)[key] = outVal
+		}
+	}
+	if in.LinkList != nil {
+		in, out := &in.LinkList, &out.LinkList
+		*out = make(map[string]*Link, len(*in))
+		for key, val := range *in {
+			var outVal *Link
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				inVal := (*in)[key]
+				in, out := &inVal, &outVal
+				*out = new(Link)
+				(*in).DeepCopyInto(*out)
+			}
+			(*out)[key] = outVal
+		}
+	}
+	if in.RestoreFrom != nil {
+		in, out := &in.RestoreFrom, &out.RestoreFrom
+		*out = new(string)
+		**out = **in
+	}
+	if in.NodeGroups != nil {
+		in, out := &in.NodeGroups, &out.NodeGroups
+		*out = make(map[string]*NodeGroup, len(*in))
+		for key, val := range *in {
+			var outVal *NodeGroup
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				inVal := (*in)[key]
+				in, out := &inVal, &outVal
+				*out = new(NodeGroup)
+				(*in).DeepCopyInto(*out)
+			}
+			(*out)[key] = outVal
+		}
+	}
+	if in.Generator != nil {
+		in, out := &in.Generator, &out.Generator
+		*out = new(TopologyGenerator)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Template != nil {
+		in, out := &in.Template, &out.Template
+		*out = new(LabTemplateRef)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Config != nil {
+		in, out := &in.Config, &out.Config
+		*out = new(string)
+		**out = **in
+	}
+	if in.Defaults != nil {
+		in, out := &in.Defaults, &out.Defaults
+		*out = new(OneOfSystem)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PVCStorageClass != nil {
+		in, out := &in.PVCStorageClass, &out.PVCStorageClass
+		*out = new(string)
+		**out = **in
+	}
+	if in.SRCPMLoaderImage != nil {
+		in, out := &in.SRCPMLoaderImage, &out.SRCPMLoaderImage
+		*out = new(string)
+		**out = **in
+	}
+	if in.SRIOMLoaderImage != nil {
+		in, out := &in.SRIOMLoaderImage, &out.SRIOMLoaderImage
+		*out = new(string)
+		**out = **in
+	}
+	if in.SideCarHookImg != nil {
+		in, out := &in.SideCarHookImg, &out.SideCarHookImg
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LabSpec.
+func (in *LabSpec) DeepCopy() *LabSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(LabSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LabStatus) DeepCopyInto(out *LabStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ConsoleLogs != nil {
+		in, out := &in.ConsoleLogs, &out.ConsoleLogs
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ConfigGeneration != nil {
+		in, out := &in.ConfigGeneration, &out.ConfigGeneration
+		*out = new(int64)
+		**out = **in
+	}
+	if in.AppliedConfig != nil {
+		in, out := &in.AppliedConfig, &out.AppliedConfig
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.CoLocatedLinks != nil {
+		in, out := &in.CoLocatedLinks, &out.CoLocatedLinks
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.CrossLabLinks != nil {
+		in, out := &in.CrossLabLinks, &out.CrossLabLinks
+		*out = make([]CrossLabLink, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LabStatus.
+func (in *LabStatus) DeepCopy() *LabStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(LabStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LabTemplate) DeepCopyInto(out *LabTemplate) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LabTemplate.
+func (in *LabTemplate) DeepCopy() *LabTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(LabTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *LabTemplate) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LabTemplateList) DeepCopyInto(out *LabTemplateList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]LabTemplate, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LabTemplateList.
+func (in *LabTemplateList) DeepCopy() *LabTemplateList {
+	if in == nil {
+		return nil
+	}
+	out := new(LabTemplateList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *LabTemplateList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LabTemplateParameter) DeepCopyInto(out *LabTemplateParameter) {
+	*out = *in
+	if in.Default != nil {
+		in, out := &in.Default, &out.Default
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LabTemplateParameter.
+func (in *LabTemplateParameter) DeepCopy() *LabTemplateParameter {
+	if in == nil {
+		return nil
+	}
+	out := new(LabTemplateParameter)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LabTemplateRef) DeepCopyInto(out *LabTemplateRef) {
+	*out = *in
+	if in.Values != nil {
+		in, out := &in.Values, &out.Values
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LabTemplateRef.
+func (in *LabTemplateRef) DeepCopy() *LabTemplateRef {
+	if in == nil {
+		return nil
+	}
+	out := new(LabTemplateRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LabTemplateSpec) DeepCopyInto(out *LabTemplateSpec) {
+	*out = *in
+	if in.Parameters != nil {
+		in, out := &in.Parameters, &out.Parameters
+		*out = make([]LabTemplateParameter, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LabTemplateSpec.
+func (in *LabTemplateSpec) DeepCopy() *LabTemplateSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(LabTemplateSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Link) DeepCopyInto(out *Link) {
+	*out = *in
+	if in.Connectors != nil {
+		in, out := &in.Connectors, &out.Connectors
+		*out = make([]Connector, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Local != nil {
+		in, out := &in.Local, &out.Local
+		*out = new(bool)
+		**out = **in
+	}
+	if in.MTU != nil {
+		in, out := &in.MTU, &out.MTU
+		*out = new(int32)
+		**out = **in
+	}
+	if in.VLANs != nil {
+		in, out := &in.VLANs, &out.VLANs
+		*out = make([]TrunkVLAN, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Link.
+func (in *Link) DeepCopy() *Link {
+	if in == nil {
+		return nil
+	}
+	out := new(Link)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MAGC) DeepCopyInto(out *MAGC) {
+	*out = *in
+	if in.Chassis != nil {
+		in, out := &in.Chassis, &out.Chassis
+		*out = new(SRChassis)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Image != nil {
+		in, out := &in.Image, &out.Image
+		*out = new(string)
+		**out = **in
+	}
+	if in.DiskSize != nil {
+		in, out := &in.DiskSize, &out.DiskSize
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.License != nil {
+		in, out := &in.License, &out.License
+		*out = new(string)
+		**out = **in
+	}
+	if in.UUID != nil {
+		in, out := &in.UUID, &out.UUID
+		*out = new(string)
+		**out = **in
+	}
+	if in.Dedicate != nil {
+		in, out := &in.Dedicate, &out.Dedicate
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MAGC.
+func (in *MAGC) DeepCopy() *MAGC {
+	if in == nil {
+		return nil
+	}
+	out := new(MAGC)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeGroup) DeepCopyInto(out *NodeGroup) {
+	*out = *in
+	if in.Start != nil {
+		in, out := &in.Start, &out.Start
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Template != nil {
+		in, out := &in.Template, &out.Template
+		*out = new(OneOfSystem)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Links != nil {
+		in, out := &in.Links, &out.Links
+		*out = make([]NodeGroupLink, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeGroup.
+func (in *NodeGroup) DeepCopy() *NodeGroup {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeGroup)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeGroupLink) DeepCopyInto(out *NodeGroupLink) {
+	*out = *in
+	if in.Shared != nil {
+		in, out := &in.Shared, &out.Shared
+		*out = new(bool)
+		**out = **in
+	}
+	if in.PortId != nil {
+		in, out := &in.PortId, &out.PortId
+		*out = new(string)
+		**out = **in
+	}
+	if in.AddrPools != nil {
+		in, out := &in.AddrPools, &out.AddrPools
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Routes != nil {
+		in, out := &in.Routes, &out.Routes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Peers != nil {
+		in, out := &in.Peers, &out.Peers
+		*out = make([]Connector, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeGroupLink.
+func (in *NodeGroupLink) DeepCopy() *NodeGroupLink {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeGroupLink)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeProfile) DeepCopyInto(out *NodeProfile) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeProfile.
+func (in *NodeProfile) DeepCopy() *NodeProfile {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeProfile)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NodeProfile) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeProfileList) DeepCopyInto(out *NodeProfileList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]NodeProfile, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeProfileList.
+func (in *NodeProfileList) DeepCopy() *NodeProfileList {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeProfileList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NodeProfileList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeSnapshotStatus) DeepCopyInto(out *NodeSnapshotStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeSnapshotStatus.
+func (in *NodeSnapshotStatus) DeepCopy() *NodeSnapshotStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeSnapshotStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OneOfSystem) DeepCopyInto(out *OneOfSystem) {
+	*out = *in
+	if in.VSIM != nil {
+		in, out := &in.VSIM, &out.VSIM
+		*out = new(VSIM)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.VSRI != nil {
+		in, out := &in.VSRI, &out.VSRI
+		*out = new(VSRI)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MAGC != nil {
+		in, out := &in.MAGC, &out.MAGC
+		*out = new(MAGC)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.VM != nil {
+		in, out := &in.VM, &out.VM
+		*out = new(GeneralVM)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SRL != nil {
+		in, out := &in.SRL, &out.SRL
+		*out = new(SRLinux)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Pod != nil {
+		in, out := &in.Pod, &out.Pod
+		*out = new(GeneralPod)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SRSIM != nil {
+		in, out := &in.SRSIM, &out.SRSIM
+		*out = new(SRSim)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.FRR != nil {
+		in, out := &in.FRR, &out.FRR
+		*out = new(FRR)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CEOS != nil {
+		in, out := &in.CEOS, &out.CEOS
+		*out = new(CEOS)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CRPD != nil {
+		in, out := &in.CRPD, &out.CRPD
+		*out = new(CRPD)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.VJunos != nil {
+		in, out := &in.VJunos, &out.VJunos
+		*out = new(VJunos)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.XRd != nil {
+		in, out := &in.XRd, &out.XRd
+		*out = new(XRd)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SONiC != nil {
+		in, out := &in.SONiC, &out.SONiC
+		*out = new(SONiC)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Dummy != nil {
+		in, out := &in.Dummy, &out.Dummy
+		*out = new(Dummy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TrafficGen != nil {
+		in, out := &in.TrafficGen, &out.TrafficGen
+		*out = new(TrafficGen)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Profile != nil {
+		in, out := &in.Profile, &out.Profile
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OneOfSystem.
+func (in *OneOfSystem) DeepCopy() *OneOfSystem {
+	if in == nil {
+		return nil
+	}
+	out := new(OneOfSystem)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PeerLab) DeepCopyInto(out *PeerLab) {
+	*out = *in
+	if in.Namespace != nil {
+		in, out := &in.Namespace, &out.Namespace
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PeerLab.
+func (in *PeerLab) DeepCopy() *PeerLab {
+	if in == nil {
+		return nil
+	}
+	out := new(PeerLab)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SONiC) DeepCopyInto(out *SONiC) {
+	*out = *in
+	if in.Image != nil {
+		in, out := &in.Image, &out.Image
+		*out = new(string)
+		**out = **in
+	}
+	if in.StartupConfig != nil {
+		in, out := &in.StartupConfig, &out.StartupConfig
+		*out = new(string)
+		**out = **in
+	}
+	if in.ReqMemory != nil {
+		in, out := &in.ReqMemory, &out.ReqMemory
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.ReqCPU != nil {
+		in, out := &in.ReqCPU, &out.ReqCPU
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SONiC.
+func (in *SONiC) DeepCopy() *SONiC {
+	if in == nil {
+		return nil
+	}
+	out := new(SONiC)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SRCard) DeepCopyInto(out *SRCard) {
+	*out = *in
+	if in.Model != nil {
+		in, out := &in.Model, &out.Model
+		*out = new(string)
+		**out = **in
+	}
+	if in.SysInfo != nil {
+		in, out := &in.SysInfo, &out.SysInfo
+		*out = new(string)
+		**out = **in
+	}
+	if in.MDAs != nil {
+		in, out := &in.MDAs, &out.MDAs
+		*out = new([]string)
+		if **in != nil {
+			in, out := *in, *out
+			*out = make([]string, len(*in))
+			copy(*out, *in)
+		}
+	}
+	if in.XIOM != nil {
+		in, out := &in.XIOM, &out.XIOM
+		*out = make(map[string]XIOM, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	if in.ReqMemory != nil {
+		in, out := &in.ReqMemory, &out.ReqMemory
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.ReqCPU != nil {
+		in, out := &in.ReqCPU, &out.ReqCPU
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.ListenPorts != nil {
+		in, out := &in.ListenPorts, &out.ListenPorts
+		*out = new([]corev1.Port)
+		if **in != nil {
+			in, out := *in, *out
+			*out = make([]corev1.Port, len(*in))
+			copy(*out, *in)
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SRCard.
+func (in *SRCard) DeepCopy() *SRCard {
+	if in == nil {
+		return nil
+	}
+	out := new(SRCard)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SRChassis) DeepCopyInto(out *SRChassis) {
+	*out = *in
+	if in.Type != nil {
+		in, out := &in.Type, &out.Type
+		*out = new(NodeType)
+		**out = **in
+	}
+	if in.Model != nil {
+		in, out := &in.Model, &out.Model
+		*out = new(string)
+		**out = **in
+	}
+	if in.Cards != nil {
+		in, out := &in.Cards, &out.Cards
+		*out = make(map[string]*SRCard, len(*in))
+		for key, val := range *in {
+			var outVal *SRCard
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				inVal := (*in)[key]
+				in, out := &inVal, &outVal
+				*out = new(SRCard)
+				(*in).DeepCopyInto(*out)
+			}
+			(*out)[key] = outVal
+		}
+	}
+	if in.SFM != nil {
+		in, out := &in.SFM, &out.SFM
+		*out = new(string)
+		**out = **in
+	}
+	if in.ChassisMAC != nil {
+		in, out := &in.ChassisMAC, &out.ChassisMAC
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SRChassis.
+func (in *SRChassis) DeepCopy() *SRChassis {
+	if in == nil {
+		return nil
+	}
+	out := new(SRChassis)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SRLChassis) DeepCopyInto(out *SRLChassis) {
+	*out = *in
+	out.ChassisConfiguration = in.ChassisConfiguration
+	if in.SlotConfig != nil {
+		in, out := &in.SlotConfig, &out.SlotConfig
+		*out = make(map[int]slotConfig, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
 	}
-	if in.Dedicate != nil {
-		in, out := &in.Dedicate, &out.Dedicate
-		*out = new(bool)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SRLChassis.
+func (in *SRLChassis) DeepCopy() *SRLChassis {
+	if in == nil {
+		return nil
+	}
+	out := new(SRLChassis)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SRLinux) DeepCopyInto(out *SRLinux) {
+	*out = *in
+	if in.Image != nil {
+		in, out := &in.Image, &out.Image
+		*out = new(string)
+		**out = **in
+	}
+	if in.Chassis != nil {
+		in, out := &in.Chassis, &out.Chassis
+		*out = new(string)
+		**out = **in
+	}
+	if in.LicSecret != nil {
+		in, out := &in.LicSecret, &out.LicSecret
+		*out = new(string)
 		**out = **in
 	}
+	if in.ReqMemory != nil {
+		in, out := &in.ReqMemory, &out.ReqMemory
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.ReqCPU != nil {
+		in, out := &in.ReqCPU, &out.ReqCPU
+		x := (*in).DeepCopy()
+		*out = &x
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MAGC.
-func (in *MAGC) DeepCopy() *MAGC {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SRLinux.
+func (in *SRLinux) DeepCopy() *SRLinux {
 	if in == nil {
 		return nil
 	}
-	out := new(MAGC)
+	out := new(SRLinux)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *OneOfSystem) DeepCopyInto(out *OneOfSystem) {
+func (in *SROSImage) DeepCopyInto(out *SROSImage) {
 	*out = *in
-	if in.VSIM != nil {
-		in, out := &in.VSIM, &out.VSIM
-		*out = new(VSIM)
-		(*in).DeepCopyInto(*out)
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SROSImage.
+func (in *SROSImage) DeepCopy() *SROSImage {
+	if in == nil {
+		return nil
 	}
-	if in.VSRI != nil {
-		in, out := &in.VSRI, &out.VSRI
-		*out = new(VSRI)
-		(*in).DeepCopyInto(*out)
+	out := new(SROSImage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SROSImage) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
 	}
-	if in.MAGC != nil {
-		in, out := &in.MAGC, &out.MAGC
-		*out = new(MAGC)
-		(*in).DeepCopyInto(*out)
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SROSImageList) DeepCopyInto(out *SROSImageList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]SROSImage, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
-	if in.VM != nil {
-		in, out := &in.VM, &out.VM
-		*out = new(GeneralVM)
-		(*in).DeepCopyInto(*out)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SROSImageList.
+func (in *SROSImageList) DeepCopy() *SROSImageList {
+	if in == nil {
+		return nil
 	}
-	if in.SRL != nil {
-		in, out := &in.SRL, &out.SRL
-		*out = new(SRLinux)
-		(*in).DeepCopyInto(*out)
+	out := new(SROSImageList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SROSImageList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
 	}
-	if in.Pod != nil {
-		in, out := &in.Pod, &out.Pod
-		*out = new(GeneralPod)
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SROSImagePVCSource) DeepCopyInto(out *SROSImagePVCSource) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SROSImagePVCSource.
+func (in *SROSImagePVCSource) DeepCopy() *SROSImagePVCSource {
+	if in == nil {
+		return nil
+	}
+	out := new(SROSImagePVCSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SROSImageSpec) DeepCopyInto(out *SROSImageSpec) {
+	*out = *in
+	if in.URL != nil {
+		in, out := &in.URL, &out.URL
+		*out = new(string)
+		**out = **in
+	}
+	if in.PVC != nil {
+		in, out := &in.PVC, &out.PVC
+		*out = new(SROSImagePVCSource)
+		**out = **in
+	}
+	if in.Checksums != nil {
+		in, out := &in.Checksums, &out.Checksums
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Config != nil {
+		in, out := &in.Config, &out.Config
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SROSImageSpec.
+func (in *SROSImageSpec) DeepCopy() *SROSImageSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SROSImageSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SROSImageStatus) DeepCopyInto(out *SROSImageStatus) {
+	*out = *in
+	if in.Files != nil {
+		in, out := &in.Files, &out.Files
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SROSImageStatus.
+func (in *SROSImageStatus) DeepCopy() *SROSImageStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SROSImageStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SRSim) DeepCopyInto(out *SRSim) {
+	*out = *in
+	if in.Image != nil {
+		in, out := &in.Image, &out.Image
+		*out = new(string)
+		**out = **in
+	}
+	if in.Chassis != nil {
+		in, out := &in.Chassis, &out.Chassis
+		*out = new(SRChassis)
 		(*in).DeepCopyInto(*out)
 	}
-	if in.SRSIM != nil {
-		in, out := &in.SRSIM, &out.SRSIM
-		*out = new(SRSim)
+	if in.LicSecret != nil {
+		in, out := &in.LicSecret, &out.LicSecret
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SRSim.
+func (in *SRSim) DeepCopy() *SRSim {
+	if in == nil {
+		return nil
+	}
+	out := new(SRSim)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SRVM) DeepCopyInto(out *SRVM) {
+	*out = *in
+	if in.Chassis != nil {
+		in, out := &in.Chassis, &out.Chassis
+		*out = new(SRChassis)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Image != nil {
+		in, out := &in.Image, &out.Image
+		*out = new(string)
+		**out = **in
+	}
+	if in.DiskSize != nil {
+		in, out := &in.DiskSize, &out.DiskSize
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.License != nil {
+		in, out := &in.License, &out.License
+		*out = new(string)
+		**out = **in
+	}
+	if in.UUID != nil {
+		in, out := &in.UUID, &out.UUID
+		*out = new(string)
+		**out = **in
+	}
+	if in.Dedicate != nil {
+		in, out := &in.Dedicate, &out.Dedicate
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SRVM.
+func (in *SRVM) DeepCopy() *SRVM {
+	if in == nil {
+		return nil
+	}
+	out := new(SRVM)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TopologyGenerator) DeepCopyInto(out *TopologyGenerator) {
+	*out = *in
+	if in.Roles != nil {
+		in, out := &in.Roles, &out.Roles
+		*out = make(map[string]*GeneratorRole, len(*in))
+		for key, val := range *in {
+			var outVal *GeneratorRole
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				inVal := (*in)[key]
+				in, out := &inVal, &outVal
+				*out = new(GeneratorRole)
+				(*in).DeepCopyInto(*out)
+			}
+			(*out)[key] = outVal
+		}
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OneOfSystem.
-func (in *OneOfSystem) DeepCopy() *OneOfSystem {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TopologyGenerator.
+func (in *TopologyGenerator) DeepCopy() *TopologyGenerator {
 	if in == nil {
 		return nil
 	}
-	out := new(OneOfSystem)
+	out := new(TopologyGenerator)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SRCard) DeepCopyInto(out *SRCard) {
+func (in *TrafficGen) DeepCopyInto(out *TrafficGen) {
 	*out = *in
-	if in.Model != nil {
-		in, out := &in.Model, &out.Model
-		*out = new(string)
+	if in.Engine != nil {
+		in, out := &in.Engine, &out.Engine
+		*out = new(TrafficGenEngine)
 		**out = **in
 	}
-	if in.SysInfo != nil {
-		in, out := &in.SysInfo, &out.SysInfo
+	if in.Image != nil {
+		in, out := &in.Image, &out.Image
 		*out = new(string)
 		**out = **in
 	}
-	if in.MDAs != nil {
-		in, out := &in.MDAs, &out.MDAs
-		*out = new([]string)
-		if **in != nil {
-			in, out := *in, *out
-			*out = make([]string, len(*in))
-			copy(*out, *in)
-		}
-	}
-	if in.XIOM != nil {
-		in, out := &in.XIOM, &out.XIOM
-		*out = make(map[string]XIOM, len(*in))
-		for key, val := range *in {
-			(*out)[key] = *val.DeepCopy()
-		}
+	if in.Profile != nil {
+		in, out := &in.Profile, &out.Profile
+		*out = new(string)
+		**out = **in
 	}
 	if in.ReqMemory != nil {
 		in, out := &in.ReqMemory, &out.ReqMemory
@@ -605,212 +2134,201 @@ func (in *SRCard) DeepCopyInto(out *SRCard) {
 		x := (*in).DeepCopy()
 		*out = &x
 	}
-	if in.ListenPorts != nil {
-		in, out := &in.ListenPorts, &out.ListenPorts
-		*out = new([]corev1.Port)
-		if **in != nil {
-			in, out := *in, *out
-			*out = make([]corev1.Port, len(*in))
-			copy(*out, *in)
-		}
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SRCard.
-func (in *SRCard) DeepCopy() *SRCard {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TrafficGen.
+func (in *TrafficGen) DeepCopy() *TrafficGen {
 	if in == nil {
 		return nil
 	}
-	out := new(SRCard)
+	out := new(TrafficGen)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SRChassis) DeepCopyInto(out *SRChassis) {
+func (in *TrafficRun) DeepCopyInto(out *TrafficRun) {
 	*out = *in
-	if in.Type != nil {
-		in, out := &in.Type, &out.Type
-		*out = new(NodeType)
-		**out = **in
-	}
-	if in.Model != nil {
-		in, out := &in.Model, &out.Model
-		*out = new(string)
-		**out = **in
-	}
-	if in.Cards != nil {
-		in, out := &in.Cards, &out.Cards
-		*out = make(map[string]*SRCard, len(*in))
-		for key, val := range *in {
-			var outVal *SRCard
-			if val == nil {
-				(*out)[key] = nil
-			} else {
-				inVal := (*in)[key]
-				in, out := &inVal, &outVal
-				*out = new(SRCard)
-				(*in).DeepCopyInto(*out)
-			}
-			(*out)[key] = outVal
-		}
-	}
-	if in.SFM != nil {
-		in, out := &in.SFM, &out.SFM
-		*out = new(string)
-		**out = **in
-	}
-	if in.ChassisMAC != nil {
-		in, out := &in.ChassisMAC, &out.ChassisMAC
-		*out = new(string)
-		**out = **in
-	}
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SRChassis.
-func (in *SRChassis) DeepCopy() *SRChassis {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TrafficRun.
+func (in *TrafficRun) DeepCopy() *TrafficRun {
 	if in == nil {
 		return nil
 	}
-	out := new(SRChassis)
+	out := new(TrafficRun)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *TrafficRun) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SRLChassis) DeepCopyInto(out *SRLChassis) {
+func (in *TrafficRunList) DeepCopyInto(out *TrafficRunList) {
 	*out = *in
-	out.ChassisConfiguration = in.ChassisConfiguration
-	if in.SlotConfig != nil {
-		in, out := &in.SlotConfig, &out.SlotConfig
-		*out = make(map[int]slotConfig, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]TrafficRun, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SRLChassis.
-func (in *SRLChassis) DeepCopy() *SRLChassis {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TrafficRunList.
+func (in *TrafficRunList) DeepCopy() *TrafficRunList {
 	if in == nil {
 		return nil
 	}
-	out := new(SRLChassis)
+	out := new(TrafficRunList)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *TrafficRunList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SRLinux) DeepCopyInto(out *SRLinux) {
+func (in *TrafficRunSpec) DeepCopyInto(out *TrafficRunSpec) {
 	*out = *in
-	if in.Image != nil {
-		in, out := &in.Image, &out.Image
-		*out = new(string)
-		**out = **in
-	}
-	if in.Chassis != nil {
-		in, out := &in.Chassis, &out.Chassis
-		*out = new(string)
+	if in.Duration != nil {
+		in, out := &in.Duration, &out.Duration
+		*out = new(int32)
 		**out = **in
 	}
-	if in.LicSecret != nil {
-		in, out := &in.LicSecret, &out.LicSecret
-		*out = new(string)
+	if in.Stop != nil {
+		in, out := &in.Stop, &out.Stop
+		*out = new(bool)
 		**out = **in
 	}
-	if in.ReqMemory != nil {
-		in, out := &in.ReqMemory, &out.ReqMemory
-		x := (*in).DeepCopy()
-		*out = &x
-	}
-	if in.ReqCPU != nil {
-		in, out := &in.ReqCPU, &out.ReqCPU
-		x := (*in).DeepCopy()
-		*out = &x
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SRLinux.
-func (in *SRLinux) DeepCopy() *SRLinux {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TrafficRunSpec.
+func (in *TrafficRunSpec) DeepCopy() *TrafficRunSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(SRLinux)
+	out := new(TrafficRunSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SRSim) DeepCopyInto(out *SRSim) {
+func (in *TrafficRunStatus) DeepCopyInto(out *TrafficRunStatus) {
 	*out = *in
-	if in.Image != nil {
-		in, out := &in.Image, &out.Image
-		*out = new(string)
-		**out = **in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
 	}
-	if in.Chassis != nil {
-		in, out := &in.Chassis, &out.Chassis
-		*out = new(SRChassis)
-		(*in).DeepCopyInto(*out)
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
 	}
-	if in.LicSecret != nil {
-		in, out := &in.LicSecret, &out.LicSecret
-		*out = new(string)
-		**out = **in
+	if in.Streams != nil {
+		in, out := &in.Streams, &out.Streams
+		*out = make([]TrafficStreamStats, len(*in))
+		copy(*out, *in)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SRSim.
-func (in *SRSim) DeepCopy() *SRSim {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TrafficRunStatus.
+func (in *TrafficRunStatus) DeepCopy() *TrafficRunStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(SRSim)
+	out := new(TrafficRunStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SRVM) DeepCopyInto(out *SRVM) {
+func (in *TrafficStreamStats) DeepCopyInto(out *TrafficStreamStats) {
 	*out = *in
-	if in.Chassis != nil {
-		in, out := &in.Chassis, &out.Chassis
-		*out = new(SRChassis)
-		(*in).DeepCopyInto(*out)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TrafficStreamStats.
+func (in *TrafficStreamStats) DeepCopy() *TrafficStreamStats {
+	if in == nil {
+		return nil
+	}
+	out := new(TrafficStreamStats)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TrunkVLAN) DeepCopyInto(out *TrunkVLAN) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TrunkVLAN.
+func (in *TrunkVLAN) DeepCopy() *TrunkVLAN {
+	if in == nil {
+		return nil
 	}
+	out := new(TrunkVLAN)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VJunos) DeepCopyInto(out *VJunos) {
+	*out = *in
 	if in.Image != nil {
 		in, out := &in.Image, &out.Image
 		*out = new(string)
 		**out = **in
 	}
+	if in.Variant != nil {
+		in, out := &in.Variant, &out.Variant
+		*out = new(string)
+		**out = **in
+	}
+	if in.ReqMemory != nil {
+		in, out := &in.ReqMemory, &out.ReqMemory
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.ReqCPU != nil {
+		in, out := &in.ReqCPU, &out.ReqCPU
+		x := (*in).DeepCopy()
+		*out = &x
+	}
 	if in.DiskSize != nil {
 		in, out := &in.DiskSize, &out.DiskSize
 		x := (*in).DeepCopy()
 		*out = &x
 	}
-	if in.License != nil {
-		in, out := &in.License, &out.License
-		*out = new(string)
-		**out = **in
-	}
-	if in.UUID != nil {
-		in, out := &in.UUID, &out.UUID
+	if in.StartupConfig != nil {
+		in, out := &in.StartupConfig, &out.StartupConfig
 		*out = new(string)
 		**out = **in
 	}
-	if in.Dedicate != nil {
-		in, out := &in.Dedicate, &out.Dedicate
-		*out = new(bool)
-		**out = **in
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SRVM.
-func (in *SRVM) DeepCopy() *SRVM {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VJunos.
+func (in *VJunos) DeepCopy() *VJunos {
 	if in == nil {
 		return nil
 	}
-	out := new(SRVM)
+	out := new(VJunos)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -929,3 +2447,63 @@ func (in *XIOM) DeepCopy() *XIOM {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *XRd) DeepCopyInto(out *XRd) {
+	*out = *in
+	if in.Image != nil {
+		in, out := &in.Image, &out.Image
+		*out = new(string)
+		**out = **in
+	}
+	if in.Variant != nil {
+		in, out := &in.Variant, &out.Variant
+		*out = new(string)
+		**out = **in
+	}
+	if in.Worker != nil {
+		in, out := &in.Worker, &out.Worker
+		*out = new(string)
+		**out = **in
+	}
+	if in.PCIAddrs != nil {
+		in, out := &in.PCIAddrs, &out.PCIAddrs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.HugePages != nil {
+		in, out := &in.HugePages, &out.HugePages
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.FirstBootConfig != nil {
+		in, out := &in.FirstBootConfig, &out.FirstBootConfig
+		*out = new(string)
+		**out = **in
+	}
+	if in.StorageSize != nil {
+		in, out := &in.StorageSize, &out.StorageSize
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.ReqMemory != nil {
+		in, out := &in.ReqMemory, &out.ReqMemory
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.ReqCPU != nil {
+		in, out := &in.ReqCPU, &out.ReqCPU
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new XRd.
+func (in *XRd) DeepCopy() *XRd {
+	if in == nil {
+		return nil
+	}
+	out := new(XRd)
+	in.DeepCopyInto(out)
+	return out
+}
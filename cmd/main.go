@@ -202,8 +202,10 @@ func main() {
 	}
 
 	if err := (&controller.LabReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:     mgr.GetClient(),
+		Scheme:     mgr.GetScheme(),
+		ConsoleRec: controller.NewConsoleRecorder(mgr.GetClient()),
+		Config:     mgr.GetConfig(),
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Lab")
 		os.Exit(1)
@@ -215,6 +217,38 @@ func main() {
 		setupLog.Error(err, "unable to create controller", "controller", "KNLConfig")
 		os.Exit(1)
 	}
+	if err := (&controller.LabSnapshotReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+		Config: mgr.GetConfig(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "LabSnapshot")
+		os.Exit(1)
+	}
+	if err := (&controller.GoldenImageReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "GoldenImage")
+		os.Exit(1)
+	}
+	if err := (&controller.SROSImageReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+		Config: mgr.GetConfig(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "SROSImage")
+		os.Exit(1)
+	}
+	if err := (&controller.TrafficRunReconciler{
+		Client:    mgr.GetClient(),
+		Scheme:    mgr.GetScheme(),
+		Config:    mgr.GetConfig(),
+		APIReader: mgr.GetAPIReader(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "TrafficRun")
+		os.Exit(1)
+	}
 	// nolint:goconst
 	if os.Getenv("ENABLE_WEBHOOKS") != "false" {
 		if err := webhookv1beta1.SetupLabWebhookWithManager(mgr); err != nil {
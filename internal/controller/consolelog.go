@@ -0,0 +1,247 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/types"
+	knlv1beta1 "kubenetlab.net/knl/api/v1beta1"
+	kvv1 "kubevirt.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	consoleReconnectInterval = 5 * time.Second
+)
+
+// ConsoleRecorder records console of VM based nodes into rotating log files on the file server,
+// the recording is done via the console mux in kvirt sidecar, so users could still access the console
+type ConsoleRecorder struct {
+	client.Client
+	lock    *sync.Mutex
+	running map[types.NamespacedName]*recording //key is the VMI
+}
+
+type recording struct {
+	labName string
+	cancelf context.CancelFunc
+}
+
+func NewConsoleRecorder(clnt client.Client) *ConsoleRecorder {
+	return &ConsoleRecorder{
+		Client:  clnt,
+		lock:    new(sync.Mutex),
+		running: make(map[types.NamespacedName]*recording),
+	}
+}
+
+// Sync starts recording for all VMIs of the lab that have console logging enabled,
+// and stops recording for VMIs of the lab that no longer exist;
+// return a map, key is VMI name, value is the log file path on file server
+func (rec *ConsoleRecorder) Sync(ctx context.Context, lab *knlv1beta1.Lab) (map[string]string, error) {
+	vmis := new(kvv1.VirtualMachineInstanceList)
+	err := rec.List(ctx, vmis, client.InNamespace(lab.Namespace), client.MatchingLabels{knlv1beta1.K8SLABELSETUPKEY: lab.Name})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list VMIs of lab %v, %w", lab.Name, err)
+	}
+	rlist := make(map[string]string)
+	current := make(map[types.NamespacedName]bool)
+	rec.lock.Lock()
+	defer rec.lock.Unlock()
+	for _, vmi := range vmis.Items {
+		if vmi.Annotations[knlv1beta1.ConsoleLogAnnotation] != "true" {
+			continue
+		}
+		key := types.NamespacedName{Namespace: vmi.Namespace, Name: vmi.Name}
+		logPath := filepath.Join(knlv1beta1.GetConsoleLogFolder(lab.Namespace, lab.Name), vmi.Name+".log")
+		rlist[vmi.Name] = logPath
+		current[key] = true
+		if _, ok := rec.running[key]; ok {
+			continue
+		}
+		rctx, cancelf := context.WithCancel(context.Background())
+		rec.running[key] = &recording{labName: lab.Name, cancelf: cancelf}
+		go rec.record(rctx, key, logPath, lab.GetConfig())
+	}
+	//stop the recording of removed VMIs
+	for key, r := range rec.running {
+		if key.Namespace != lab.Namespace || r.labName != lab.Name {
+			continue
+		}
+		if _, ok := current[key]; ok {
+			continue
+		}
+		r.cancelf()
+		delete(rec.running, key)
+	}
+	return rlist, nil
+}
+
+// StopLab stops recording of all VMIs of the lab, the log files are kept on the file server
+func (rec *ConsoleRecorder) StopLab(lab *knlv1beta1.Lab) {
+	rec.lock.Lock()
+	defer rec.lock.Unlock()
+	for key, r := range rec.running {
+		if key.Namespace == lab.Namespace && r.labName == lab.Name {
+			r.cancelf()
+			delete(rec.running, key)
+		}
+	}
+}
+
+func (rec *ConsoleRecorder) getVMIPodIP(ctx context.Context, key types.NamespacedName) (string, error) {
+	podList := new(corev1.PodList)
+	err := rec.List(ctx, podList, client.InNamespace(key.Namespace), client.MatchingLabels{
+		"vm.kubevirt.io/name": key.Name,
+	})
+	if err != nil {
+		return "", err
+	}
+	for _, pod := range podList.Items {
+		if pod.Status.Phase == corev1.PodRunning && pod.Status.PodIP != "" {
+			return pod.Status.PodIP, nil
+		}
+	}
+	return "", fmt.Errorf("no running pod found for vmi %v", key.Name)
+}
+
+// record keeps connecting to the VMI's console and write the output into the log file until ctx is cancelled,
+// it reconnects if VMI is restarted; gconf is the KNLConfig of the lab
+func (rec *ConsoleRecorder) record(ctx context.Context, key types.NamespacedName, logPath string, gconf knlv1beta1.KNLConfigSpec) {
+	logger := log.FromContext(ctx).WithValues("vmi", key.String())
+	defMaxSize := resource.MustParse(knlv1beta1.DefConsoleLogMaxSize)
+	writer := &rotatingFile{
+		path:       logPath,
+		maxSize:    defMaxSize.Value(),
+		maxBackups: knlv1beta1.DefConsoleLogMaxBackups,
+	}
+	if gconf.ConsoleLog != nil {
+		if gconf.ConsoleLog.MaxSize != nil {
+			writer.maxSize = gconf.ConsoleLog.MaxSize.Value()
+		}
+		if gconf.ConsoleLog.MaxBackups != nil {
+			writer.maxBackups = int(*gconf.ConsoleLog.MaxBackups)
+		}
+	}
+	defer writer.Close()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+		podIP, err := rec.getVMIPodIP(ctx, key)
+		if err == nil {
+			err = rec.recordOnce(ctx, podIP, writer)
+		}
+		if err != nil {
+			logger.V(1).Info("console recording interrupted", "reason", err.Error())
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(consoleReconnectInterval):
+		}
+	}
+}
+
+func (rec *ConsoleRecorder) recordOnce(ctx context.Context, podIP string, writer *rotatingFile) error {
+	dialer := net.Dialer{Timeout: consoleReconnectInterval}
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(podIP, strconv.Itoa(knlv1beta1.SRVMConsoleTCPPort)))
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	//close the connection when recording is stopped
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+	_, err = fmt.Fprintf(writer, "\n===== KNL console recording connected to %v at %v =====\n", podIP, time.Now().Format(time.RFC3339))
+	if err != nil {
+		return err
+	}
+	filter := new(knlv1beta1.TelnetFilter)
+	buf := make([]byte, 4096)
+	for {
+		n, err := conn.Read(buf)
+		if err != nil {
+			return err
+		}
+		if _, err = writer.Write(filter.Filter(buf[:n])); err != nil {
+			return err
+		}
+	}
+}
+
+// rotatingFile is a io.Writer writes to path, when the size exceeds maxSize,
+// the file is renamed to path.1, path.1 to path.2 and so on, up to maxBackups
+type rotatingFile struct {
+	path       string
+	maxSize    int64
+	maxBackups int
+	f          *os.File
+	size       int64
+}
+
+func (rf *rotatingFile) open() error {
+	err := os.MkdirAll(filepath.Dir(rf.path), 0755)
+	if err != nil {
+		return err
+	}
+	rf.f, err = os.OpenFile(rf.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := rf.f.Stat()
+	if err != nil {
+		return err
+	}
+	rf.size = info.Size()
+	return nil
+}
+
+func (rf *rotatingFile) rotate() error {
+	rf.Close()
+	for i := rf.maxBackups - 1; i >= 1; i-- {
+		os.Rename(fmt.Sprintf("%v.%d", rf.path, i), fmt.Sprintf("%v.%d", rf.path, i+1))
+	}
+	if rf.maxBackups > 0 {
+		os.Rename(rf.path, rf.path+".1")
+	} else {
+		os.Remove(rf.path)
+	}
+	return rf.open()
+}
+
+func (rf *rotatingFile) Write(p []byte) (int, error) {
+	if rf.f == nil {
+		if err := rf.open(); err != nil {
+			return 0, err
+		}
+	}
+	if rf.size+int64(len(p)) > rf.maxSize && rf.size > 0 {
+		if err := rf.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := rf.f.Write(p)
+	rf.size += int64(n)
+	return n, err
+}
+
+func (rf *rotatingFile) Close() error {
+	if rf.f == nil {
+		return nil
+	}
+	err := rf.f.Close()
+	rf.f = nil
+	return err
+}
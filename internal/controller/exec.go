@@ -0,0 +1,72 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/remotecommand"
+)
+
+// execInPod runs cmd in the container of the pod, stdout of cmd is passed to consume;
+// rest of the output is drained after consume returns
+func execInPod(ctx context.Context, cfg *rest.Config, kubeClient kubernetes.Interface,
+	ns, podName, container string, cmd []string, consume func(io.Reader) error) error {
+	req := kubeClient.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace(ns).
+		Name(podName).
+		SubResource("exec").
+		VersionedParams(&corev1.PodExecOptions{
+			Container: container,
+			Command:   cmd,
+			Stdout:    true,
+			Stderr:    true,
+		}, scheme.ParameterCodec)
+	executor, err := remotecommand.NewSPDYExecutor(cfg, "POST", req.URL())
+	if err != nil {
+		return fmt.Errorf("failed to exec into pod %v, %w", podName, err)
+	}
+	pr, pw := io.Pipe()
+	stderr := new(bytes.Buffer)
+	errc := make(chan error, 1)
+	go func() {
+		err := executor.StreamWithContext(ctx, remotecommand.StreamOptions{
+			Stdout: pw,
+			Stderr: stderr,
+		})
+		pw.CloseWithError(err)
+		errc <- err
+	}()
+	err = consume(pr)
+	if err == nil {
+		//drain the rest of output
+		_, err = io.Copy(io.Discard, pr)
+	}
+	pr.Close()
+	if execErr := <-errc; execErr != nil {
+		return fmt.Errorf("failed to run %v in pod %v, %w, %v", cmd, podName, execErr, stderr.String())
+	}
+	return err
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	knlv1beta1 "kubenetlab.net/knl/api/v1beta1"
+	cdiv1 "kubevirt.io/containerized-data-importer-api/pkg/apis/core/v1beta1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	goldenImageCheckInterval = 10 * time.Minute
+)
+
+// GoldenImageReconciler reconciles a GoldenImage object
+type GoldenImageReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+// +kubebuilder:rbac:groups=knl.kubenetlab.net,resources=goldenimages,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=knl.kubenetlab.net,resources=goldenimages/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=knl.kubenetlab.net,resources=goldenimages/finalizers,verbs=update
+// +kubebuilder:rbac:groups=cdi.kubevirt.io,resources=datavolumes/source,verbs=create
+
+// Reconcile imports the golden image, tracks the DataVolumes using it,
+// and removes the golden image when it has not been used for gcAfter specified in KNLConfig
+func (r *GoldenImageReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+	img := new(knlv1beta1.GoldenImage)
+	if err := r.Get(ctx, req.NamespacedName, img); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if !img.ObjectMeta.DeletionTimestamp.IsZero() {
+		//golden DV is removed via owner reference
+		return ctrl.Result{}, nil
+	}
+	//ensure golden DV
+	dv := knlv1beta1.NewGoldenDV(img)
+	err := r.Get(ctx, types.NamespacedName{Namespace: dv.Namespace, Name: dv.Name}, dv)
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return ctrl.Result{}, err
+		}
+		if err = ctrl.SetControllerReference(img, dv, r.Scheme); err != nil {
+			return ctrl.Result{}, knlv1beta1.MakeErr(err)
+		}
+		if err = r.Create(ctx, dv); err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to create golden DataVolume %v, %w", dv.Name, err)
+		}
+	}
+	newStatus := img.Status.DeepCopy()
+	newStatus.PVC = fmt.Sprintf("%v/%v", dv.Namespace, dv.Name)
+	switch dv.Status.Phase {
+	case cdiv1.Succeeded:
+		newStatus.Phase = knlv1beta1.GoldenImagePhaseReady
+	case cdiv1.Failed:
+		newStatus.Phase = knlv1beta1.GoldenImagePhaseFailed
+	default:
+		newStatus.Phase = knlv1beta1.GoldenImagePhaseImporting
+	}
+	//reference counting
+	dvList := new(cdiv1.DataVolumeList)
+	err = r.List(ctx, dvList, client.MatchingLabels{knlv1beta1.GoldenImageLabelKey: img.Name})
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to list DataVolumes using golden image %v, %w", img.Name, err)
+	}
+	userMap := make(map[string]bool)
+	for _, udv := range dvList.Items {
+		userMap[fmt.Sprintf("%v/%v", udv.Namespace, udv.Labels[knlv1beta1.K8SLABELSETUPKEY])] = true
+	}
+	newStatus.RefCount = len(dvList.Items)
+	newStatus.Users = nil
+	if len(userMap) > 0 {
+		newStatus.Users = knlv1beta1.GetSortedKeySlice(userMap)
+	}
+	now := metav1.Now()
+	if newStatus.RefCount > 0 || newStatus.LastUsed == nil {
+		newStatus.LastUsed = &now
+	}
+	//garbage collection
+	if newStatus.RefCount == 0 && (img.Spec.Retain == nil || !*img.Spec.Retain) && newStatus.Phase != knlv1beta1.GoldenImagePhaseImporting {
+		gcAfter := img.GetConfig().GetImageCacheGCAfter()
+		if unused := now.Sub(newStatus.LastUsed.Time); unused >= gcAfter {
+			logger.Info("removing unused golden image", "image", img.Name, "unused", unused.String())
+			return ctrl.Result{}, client.IgnoreNotFound(r.Delete(ctx, img))
+		}
+	}
+	if !reflect.DeepEqual(newStatus, &img.Status) {
+		img.Status = *newStatus
+		if err = r.Status().Update(ctx, img); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	return ctrl.Result{RequeueAfter: goldenImageCheckInterval}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *GoldenImageReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&knlv1beta1.GoldenImage{}).
+		Owns(&cdiv1.DataVolume{}).
+		Named("goldenimage").
+		Complete(r)
+}
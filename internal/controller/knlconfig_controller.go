@@ -19,11 +19,17 @@ package controller
 import (
 	"context"
 	"fmt"
+	"reflect"
+	"sort"
 
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	"kubenetlab.net/knl/api/v1beta1"
 	knlv1beta1 "kubenetlab.net/knl/api/v1beta1"
@@ -38,6 +44,7 @@ type KNLConfigReconciler struct {
 // +kubebuilder:rbac:groups=knl.kubenetlab.net,resources=knlconfigs,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=knl.kubenetlab.net,resources=knlconfigs/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=knl.kubenetlab.net,resources=knlconfigs/finalizers,verbs=update
+// +kubebuilder:rbac:groups=knl.kubenetlab.net,resources=labs,verbs=get;list;watch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -49,42 +56,73 @@ type KNLConfigReconciler struct {
 // For more details, check Reconcile and its Result here:
 // - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.22.1/pkg/reconcile
 
-const TARGET_KNLCONFIG_NAME = "knlcfg"
-
 func (r *KNLConfigReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	log := logf.FromContext(ctx)
 	var knlcfg v1beta1.KNLConfig
-	if req.NamespacedName.Name != TARGET_KNLCONFIG_NAME || req.NamespacedName.Namespace != knlv1beta1.MYNAMESPACE {
-		log.Info(fmt.Sprintf("%v is not target KNLConfig, ignored, my namespace is %v", req.NamespacedName.String(), knlv1beta1.MYNAMESPACE))
+	if req.NamespacedName.Namespace != knlv1beta1.MYNAMESPACE {
+		log.Info(fmt.Sprintf("%v is not in my namespace %v, ignored", req.NamespacedName.String(), knlv1beta1.MYNAMESPACE))
 		return ctrl.Result{}, nil
 	}
 	if err := r.Get(ctx, req.NamespacedName, &knlcfg); err != nil {
+		if apierrors.IsNotFound(err) {
+			knlv1beta1.GCONF.Remove(req.Name)
+			return ctrl.Result{}, nil
+		}
 		log.Error(err, "unable to fetch KNLConfig")
-		// we'll ignore not-found errors, since they can't be fixed by an immediate
-		// requeue (we'll need to wait for a new notification), and we can get them
-		// on deleted requests.
-		return ctrl.Result{}, client.IgnoreNotFound(err)
+		return ctrl.Result{}, err
 	}
 	if knlcfg.Status.ObservedGeneration == nil {
 		knlcfg.Status.ObservedGeneration = new(int64)
 		*knlcfg.Status.ObservedGeneration = -1
 	}
-
+	newStatus := knlcfg.Status.DeepCopy()
 	newSpec := knlcfg.Spec
-	changed := knlv1beta1.GCONF.Set(&newSpec, knlcfg.Generation)
+	changed := knlv1beta1.GCONF.Set(knlcfg.Name, &newSpec, knlcfg.Generation)
 	if changed {
-		*knlcfg.Status.ObservedGeneration = knlv1beta1.GCONF.GetGen()
-		r.Status().Update(ctx, &knlcfg)
-		log.Info(fmt.Sprintf("%v is updated to Generation %d, %+v", req.NamespacedName, knlcfg.Generation, knlv1beta1.GCONF.Get()))
+		*newStatus.ObservedGeneration = knlv1beta1.GCONF.GetGen(knlcfg.Name)
+		log.Info(fmt.Sprintf("%v is updated to Generation %d, %+v", req.NamespacedName, knlcfg.Generation, newSpec))
+	}
+	//labs using this config
+	labList := new(knlv1beta1.LabList)
+	if err := r.List(ctx, labList); err != nil {
+		return ctrl.Result{}, err
+	}
+	newStatus.Labs = nil
+	for _, lab := range labList.Items {
+		if lab.ConfigName() == knlcfg.Name {
+			newStatus.Labs = append(newStatus.Labs, fmt.Sprintf("%v/%v", lab.Namespace, lab.Name))
+		}
+	}
+	sort.Strings(newStatus.Labs)
+	newStatus.LabCount = len(newStatus.Labs)
+	if !reflect.DeepEqual(newStatus, &knlcfg.Status) {
+		knlcfg.Status = *newStatus
+		if err := r.Status().Update(ctx, &knlcfg); err != nil {
+			return ctrl.Result{}, err
+		}
 	}
-
 	return ctrl.Result{}, nil
 }
 
+// allConfigs returns requests of all KNLConfigs, so that their lab list is refreshed upon lab change
+func (r *KNLConfigReconciler) allConfigs(ctx context.Context, obj client.Object) []reconcile.Request {
+	cfgList := new(knlv1beta1.KNLConfigList)
+	if err := r.List(ctx, cfgList, client.InNamespace(knlv1beta1.MYNAMESPACE)); err != nil {
+		logf.FromContext(ctx).Error(err, "failed to list KNLConfig")
+		return nil
+	}
+	var reqs []reconcile.Request
+	for _, cfg := range cfgList.Items {
+		reqs = append(reqs, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: cfg.Namespace, Name: cfg.Name}})
+	}
+	return reqs
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *KNLConfigReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&knlv1beta1.KNLConfig{}).
+		Watches(&knlv1beta1.Lab{}, handler.EnqueueRequestsFromMapFunc(r.allConfigs)).
 		Named("knlconfig").
 		Complete(r)
 }
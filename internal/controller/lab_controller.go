@@ -19,14 +19,21 @@ package controller
 import (
 	"context"
 	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
 
 	k8slan "github.com/hujun-open/k8slan/api/v1beta1"
 	ncv1 "github.com/k8snetworkplumbingwg/network-attachment-definition-client/pkg/apis/k8s.cni.cncf.io/v1"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
 	"kubenetlab.net/knl/api/v1beta1"
 	knlv1beta1 "kubenetlab.net/knl/api/v1beta1"
 	kvv1 "kubevirt.io/api/core/v1"
@@ -34,18 +41,29 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
 // LabReconciler reconciles a Lab object
 type LabReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
+	//ConsoleRec records console of VM based nodes, console is not recorded if nil
+	ConsoleRec *ConsoleRecorder
+	//Config is used to exec into VxLAN agent pods in unicast mode
+	Config     *rest.Config
+	kubeClient kubernetes.Interface
 }
 
 // +kubebuilder:rbac:groups=knl.kubenetlab.net,resources=labs,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=knl.kubenetlab.net,resources=labs/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=knl.kubenetlab.net,resources=labs/finalizers,verbs=update
+// +kubebuilder:rbac:groups=knl.kubenetlab.net,resources=labtemplates,verbs=get;list;watch
+// +kubebuilder:rbac:groups=knl.kubenetlab.net,resources=nodeprofiles;clusternodeprofiles,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=nodes,verbs=get;list;watch
 
 //+kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch;create;update;patch;delete;deletecollection
 //+kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch,namespace=knl-system
@@ -55,6 +73,8 @@ type LabReconciler struct {
 //+kubebuilder:rbac:groups="",resources=persistentvolumeclaims,verbs=get;list;watch;create;update;patch;delete;deletecollection
 //+kubebuilder:rbac:groups=cdi.kubevirt.io,resources=datavolumes,verbs=get;list;watch;create;update;patch;delete;deletecollection
 // +kubebuilder:rbac:groups=lan.k8slan.io,resources=lans,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=apps,resources=daemonsets,verbs=get;list;watch;create
+// +kubebuilder:rbac:groups="",resources=pods/exec,verbs=create
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -98,6 +118,9 @@ func (r *LabReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.R
 				return ctrl.Result{}, err
 			}
 
+			if r.ConsoleRec != nil {
+				r.ConsoleRec.StopLab(lab)
+			}
 			// remove our finalizer from the list and update it.
 			controllerutil.RemoveFinalizer(lab, knlv1beta1.FinalizerName)
 			if err := r.Update(ctx, lab); err != nil {
@@ -109,6 +132,17 @@ func (r *LabReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.R
 		return ctrl.Result{}, nil
 	}
 	//reconcile logic here
+	//report template change
+	if err := r.checkTemplate(ctx, lab); err != nil {
+		return ctrl.Result{}, err
+	}
+	if _, ok := knlv1beta1.GCONF.Lookup(lab.ConfigName()); !ok {
+		return ctrl.Result{}, fmt.Errorf("KNLConfig %v of lab %v not found", lab.ConfigName(), req.NamespacedName)
+	}
+	//report config change
+	if err := r.checkConfig(ctx, lab); err != nil {
+		return ctrl.Result{}, err
+	}
 	//create k8sLAN CRs
 	var err error
 	err = plab.EnsureLinks(ctx, r.Client)
@@ -127,6 +161,48 @@ func (r *LabReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.R
 			return ctrl.Result{}, nil
 		}
 	}
+	//attach host interfaces of external connectors
+	if err = plab.EnsureExternals(ensureCTX, r.Client); err != nil {
+		logger.Error(err, "failed to ensure external connectors")
+		return ctrl.Result{}, nil
+	}
+	//bridge VLANs of trunk links to the links they carry
+	if err = plab.EnsureTrunks(ensureCTX, r.Client); err != nil {
+		logger.Error(err, "failed to ensure trunk bridges")
+		return ctrl.Result{}, nil
+	}
+	//console recording
+	if r.ConsoleRec != nil {
+		consoleLogs, err := r.ConsoleRec.Sync(ctx, lab)
+		if err != nil {
+			logger.Error(err, "failed to sync console recording")
+			return ctrl.Result{}, nil
+		}
+		if len(consoleLogs) == 0 {
+			consoleLogs = nil
+		}
+		if !reflect.DeepEqual(consoleLogs, lab.Status.ConsoleLogs) {
+			lab.Status.ConsoleLogs = consoleLogs
+			if err := r.Status().Update(ctx, lab); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+	}
+	//report links could be local after scheduling
+	if err := r.checkCoLocation(ctx, lab); err != nil {
+		logger.Error(err, "failed to check co-located links")
+	}
+	//report lab-to-lab links, including ones declared by peer labs
+	if err := r.checkCrossLabLinks(ctx, lab); err != nil {
+		logger.Error(err, "failed to check lab-to-lab links")
+	}
+	//VxLAN interfaces are created when pods are scheduled, so flood lists are programmed periodically
+	if lab.GetConfig().IsVxLANUnicast() {
+		if err := r.syncFloodLists(ctx, lab); err != nil {
+			logger.Error(err, "failed to program VxLAN flood lists")
+		}
+		return ctrl.Result{RequeueAfter: vxFloodSyncInterval}, nil
+	}
 	return ctrl.Result{}, nil
 }
 
@@ -162,6 +238,162 @@ func registrResource[T any, PT myObj[T]](ctx context.Context, mgr ctrl.Manager)
 		extractKey[PT])
 }
 
+// checkTemplate sets condition TemplateOutdated if the lab refers to a LabTemplate
+func (r *LabReconciler) checkTemplate(ctx context.Context, lab *v1beta1.Lab) error {
+	var cond metav1.Condition
+	if lab.Spec.Template == nil {
+		if meta.FindStatusCondition(lab.Status.Conditions, knlv1beta1.ConditionTemplateOutdated) == nil {
+			return nil
+		}
+		meta.RemoveStatusCondition(&lab.Status.Conditions, knlv1beta1.ConditionTemplateOutdated)
+		return r.Status().Update(ctx, lab)
+	}
+	tmpl := new(knlv1beta1.LabTemplate)
+	err := r.Get(ctx, types.NamespacedName{Namespace: lab.Namespace, Name: lab.Spec.Template.Name}, tmpl)
+	renderedGen := lab.Annotations[knlv1beta1.TemplateGenAnnotation]
+	switch {
+	case apierrors.IsNotFound(err):
+		cond = metav1.Condition{Type: knlv1beta1.ConditionTemplateOutdated, Status: metav1.ConditionTrue,
+			Reason: "TemplateNotFound", Message: fmt.Sprintf("template %v is not found", lab.Spec.Template.Name)}
+	case err != nil:
+		return err
+	case renderedGen != strconv.FormatInt(tmpl.Generation, 10):
+		cond = metav1.Condition{Type: knlv1beta1.ConditionTemplateOutdated, Status: metav1.ConditionTrue,
+			Reason: "TemplateChanged", Message: fmt.Sprintf("template %v is at generation %d, the lab is rendered from generation %v",
+				tmpl.Name, tmpl.Generation, renderedGen)}
+	default:
+		cond = metav1.Condition{Type: knlv1beta1.ConditionTemplateOutdated, Status: metav1.ConditionFalse,
+			Reason: "UpToDate", Message: fmt.Sprintf("rendered from generation %v of template %v", renderedGen, tmpl.Name)}
+	}
+	cond.ObservedGeneration = lab.Generation
+	if meta.SetStatusCondition(&lab.Status.Conditions, cond) {
+		return r.Status().Update(ctx, lab)
+	}
+	return nil
+}
+
+// checkConfig records generation of the KNLConfig used by the lab,
+// and sets condition ConfigDrift if fields built into existing objects of the lab are changed, the objects are not recreated
+func (r *LabReconciler) checkConfig(ctx context.Context, lab *v1beta1.Lab) error {
+	newStatus := lab.Status.DeepCopy()
+	cfgName := lab.ConfigName()
+	newStatus.ConfigGeneration = knlv1beta1.ReturnPointerVal(knlv1beta1.GCONF.GetGen(cfgName))
+	curVals := lab.GetConfig().DisruptiveValues()
+	if newStatus.AppliedConfig == nil {
+		newStatus.AppliedConfig = curVals
+	}
+	var changed []string
+	for _, field := range knlv1beta1.GetSortedKeySlice(curVals) {
+		if newStatus.AppliedConfig[field] != curVals[field] {
+			changed = append(changed, field)
+		}
+	}
+	cond := metav1.Condition{Type: knlv1beta1.ConditionConfigDrift, Status: metav1.ConditionFalse,
+		Reason: "InSync", Message: fmt.Sprintf("lab is in sync with KNLConfig %v", cfgName)}
+	if len(changed) > 0 {
+		cond = metav1.Condition{Type: knlv1beta1.ConditionConfigDrift, Status: metav1.ConditionTrue,
+			Reason: "ConfigChanged", Message: fmt.Sprintf("%v of KNLConfig %v changed after the lab is created, recreate the lab to apply",
+				strings.Join(changed, ", "), cfgName)}
+	}
+	cond.ObservedGeneration = lab.Generation
+	meta.SetStatusCondition(&newStatus.Conditions, cond)
+	if reflect.DeepEqual(newStatus, &lab.Status) {
+		return nil
+	}
+	lab.Status = *newStatus
+	return r.Status().Update(ctx, lab)
+}
+
+// checkCoLocation reports links whose nodes are all scheduled on the same worker in lab status
+func (r *LabReconciler) checkCoLocation(ctx context.Context, lab *v1beta1.Lab) error {
+	podList := new(corev1.PodList)
+	if err := r.List(ctx, podList, client.InNamespace(lab.Namespace),
+		client.MatchingLabels{knlv1beta1.K8SLABELSETUPKEY: lab.Name}); err != nil {
+		return fmt.Errorf("failed to list pods of lab %v, %w", lab.Name, err)
+	}
+	//key is node name, a node could have multiple pods, e.g. distributed SRVM
+	nodeWorkers := make(map[string]map[string]bool)
+	for _, pod := range podList.Items {
+		nodeName, ok := pod.Labels[knlv1beta1.ChassisNameAnnotation]
+		if !ok || pod.Spec.NodeName == "" {
+			continue
+		}
+		if nodeWorkers[nodeName] == nil {
+			nodeWorkers[nodeName] = make(map[string]bool)
+		}
+		nodeWorkers[nodeName][pod.Spec.NodeName] = true
+	}
+	links := lab.Spec.GetCoLocatedLinks(nodeWorkers)
+	if slices.Equal(links, lab.Status.CoLocatedLinks) {
+		return nil
+	}
+	lab.Status.CoLocatedLinks = links
+	return r.Status().Update(ctx, lab)
+}
+
+// checkCrossLabLinks reports lab-to-lab links of the lab in lab status
+func (r *LabReconciler) checkCrossLabLinks(ctx context.Context, lab *v1beta1.Lab) error {
+	lanList := new(k8slan.LANList)
+	if err := r.List(ctx, lanList, client.InNamespace(knlv1beta1.MYNAMESPACE),
+		client.MatchingLabels{knlv1beta1.CrossLabLANLabelKey: "true"}); err != nil {
+		return fmt.Errorf("failed to list shared LANs, %w", err)
+	}
+	links := lab.GetCrossLabLinks(lanList.Items)
+	if reflect.DeepEqual(links, lab.Status.CrossLabLinks) {
+		return nil
+	}
+	lab.Status.CrossLabLinks = links
+	return r.Status().Update(ctx, lab)
+}
+
+// labsOfSharedLAN returns both labs of a shared LAN of lab-to-lab link, so they see the peer joining or leaving
+func (r *LabReconciler) labsOfSharedLAN(ctx context.Context, obj client.Object) []reconcile.Request {
+	lan, ok := obj.(*k8slan.LAN)
+	if !ok || lan.Labels[knlv1beta1.CrossLabLANLabelKey] != "true" {
+		return nil
+	}
+	reqs := []reconcile.Request{}
+	for _, key := range knlv1beta1.GetCrossLabLANLabs(lan) {
+		reqs = append(reqs, reconcile.Request{NamespacedName: key})
+	}
+	return reqs
+}
+
+// labsOfConfig returns requests of labs using the KNLConfig obj
+func (r *LabReconciler) labsOfConfig(ctx context.Context, obj client.Object) []reconcile.Request {
+	if obj.GetNamespace() != knlv1beta1.MYNAMESPACE {
+		return nil
+	}
+	labList := new(knlv1beta1.LabList)
+	if err := r.List(ctx, labList); err != nil {
+		log.FromContext(ctx).Error(err, "failed to list labs", "config", obj.GetName())
+		return nil
+	}
+	var reqs []reconcile.Request
+	for _, lab := range labList.Items {
+		if lab.ConfigName() == obj.GetName() {
+			reqs = append(reqs, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: lab.Namespace, Name: lab.Name}})
+		}
+	}
+	return reqs
+}
+
+// labsOfTemplate returns requests of labs referring to the LabTemplate obj
+func (r *LabReconciler) labsOfTemplate(ctx context.Context, obj client.Object) []reconcile.Request {
+	labList := new(knlv1beta1.LabList)
+	if err := r.List(ctx, labList, client.InNamespace(obj.GetNamespace())); err != nil {
+		log.FromContext(ctx).Error(err, "failed to list labs", "template", obj.GetName())
+		return nil
+	}
+	var reqs []reconcile.Request
+	for _, lab := range labList.Items {
+		if lab.Spec.Template != nil && lab.Spec.Template.Name == obj.GetName() {
+			reqs = append(reqs, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: lab.Namespace, Name: lab.Name}})
+		}
+	}
+	return reqs
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *LabReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	if err := registrResource[corev1.Pod](context.Background(), mgr); err != nil {
@@ -176,6 +408,13 @@ func (r *LabReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	if err := registrResource[k8slan.LAN](context.Background(), mgr); err != nil {
 		return knlv1beta1.MakeErr(err)
 	}
+	if r.Config != nil {
+		var err error
+		r.kubeClient, err = kubernetes.NewForConfig(r.Config)
+		if err != nil {
+			return knlv1beta1.MakeErr(err)
+		}
+	}
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&knlv1beta1.Lab{}).
 		Owns(&kvv1.VirtualMachineInstance{}).
@@ -183,6 +422,11 @@ func (r *LabReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		Owns(&k8slan.LAN{}).
 		Owns(&cdiv1.DataVolume{}).
 		Owns(&ncv1.NetworkAttachmentDefinition{}).
+		Watches(&knlv1beta1.LabTemplate{}, handler.EnqueueRequestsFromMapFunc(r.labsOfTemplate)).
+		// KNLConfig status is updated after GCONF, so labs see the new config upon that event
+		Watches(&knlv1beta1.KNLConfig{}, handler.EnqueueRequestsFromMapFunc(r.labsOfConfig)).
+		// shared LAN of lab-to-lab links is owned by neither lab
+		Watches(&k8slan.LAN{}, handler.EnqueueRequestsFromMapFunc(r.labsOfSharedLAN)).
 		Named("lab").
 		Complete(r)
 }
@@ -192,14 +436,39 @@ func (r *LabReconciler) deleteExternalResources(ctx context.Context, lab *v1beta
 	logger.Info("deleting external resource", "lab", lab.Name)
 	//removing finalizer on LAN CR
 	for link := range lab.Spec.LinkList {
+		if lab.Spec.LinkList[link].IsLocal() {
+			//local link has no LAN
+			continue
+		}
 		lan := new(k8slan.LAN)
-		lanName := knlv1beta1.Getk8lanName(lab.Name, link)
-		err := r.Client.Get(ctx, types.NamespacedName{Namespace: lab.Namespace, Name: lanName}, lan)
+		lanKey := lab.GetLANKey(link)
+		lanName := lanKey.Name
+		err := r.Client.Get(ctx, lanKey, lan)
 		if err != nil {
 			if !apierrors.IsNotFound(err) {
 				return fmt.Errorf("failed to check finalizer on LAN %v, %w", lanName, err)
 			}
 			//already gone
+			continue
+		}
+		if lab.Spec.LinkList[link].IsCrossLab() {
+			//leave the shared LAN, it is removed when the peer lab left as well
+			spokeName := lab.GetCrossLabSpoke(link, lan)
+			lan.Spec.SpokeList = slices.DeleteFunc(lan.Spec.SpokeList, func(s string) bool { return s == spokeName })
+			if len(lan.Spec.SpokeList) > 0 {
+				if err := r.Update(ctx, lan); err != nil {
+					return fmt.Errorf("failed to leave shared LAN %v, %w", lanName, err)
+				}
+				continue
+			}
+			controllerutil.RemoveFinalizer(lan, knlv1beta1.FinalizerName)
+			if err := r.Update(ctx, lan); err != nil {
+				return fmt.Errorf("failed to remove finalizer on LAN %v, %w", lanName, err)
+			}
+			if err := r.Delete(ctx, lan); client.IgnoreNotFound(err) != nil {
+				return fmt.Errorf("failed to remove shared LAN %v, %w", lanName, err)
+			}
+			continue
 		}
 		//remove the finalizer
 		controllerutil.RemoveFinalizer(lan, knlv1beta1.FinalizerName)
@@ -0,0 +1,394 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	knlv1beta1 "kubenetlab.net/knl/api/v1beta1"
+	cdiv1 "kubevirt.io/containerized-data-importer-api/pkg/apis/core/v1beta1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	volumeSnapshotCheckInterval = 5 * time.Second
+)
+
+// LabSnapshotReconciler reconciles a LabSnapshot object
+type LabSnapshotReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+	//Config is used to exec into node pods to collect configuration
+	Config     *rest.Config
+	kubeClient kubernetes.Interface
+}
+
+// +kubebuilder:rbac:groups=knl.kubenetlab.net,resources=labsnapshots,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=knl.kubenetlab.net,resources=labsnapshots/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=knl.kubenetlab.net,resources=labsnapshots/finalizers,verbs=update
+// +kubebuilder:rbac:groups="",resources=pods/exec,verbs=create
+// +kubebuilder:rbac:groups=snapshot.storage.k8s.io,resources=volumesnapshots,verbs=get;list;watch;create;delete
+
+// Reconcile takes the snapshot once, the archive is removed from the file server when the LabSnapshot is deleted
+func (r *LabSnapshotReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+	snap := new(knlv1beta1.LabSnapshot)
+	if err := r.Get(ctx, req.NamespacedName, snap); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if !snap.ObjectMeta.DeletionTimestamp.IsZero() {
+		if controllerutil.ContainsFinalizer(snap, knlv1beta1.FinalizerName) {
+			if snap.Status.Archive != "" {
+				if err := os.Remove(snap.Status.Archive); err != nil && !errors.Is(err, os.ErrNotExist) {
+					return ctrl.Result{}, fmt.Errorf("failed to remove archive %v, %w", snap.Status.Archive, err)
+				}
+			}
+			controllerutil.RemoveFinalizer(snap, knlv1beta1.FinalizerName)
+			if err := r.Update(ctx, snap); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+		return ctrl.Result{}, nil
+	}
+	if !controllerutil.ContainsFinalizer(snap, knlv1beta1.FinalizerName) {
+		controllerutil.AddFinalizer(snap, knlv1beta1.FinalizerName)
+		if err := r.Update(ctx, snap); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	if snap.Status.Phase == knlv1beta1.SnapshotPhaseCompleted || snap.Status.Phase == knlv1beta1.SnapshotPhaseFailed {
+		return ctrl.Result{}, nil
+	}
+	lab := new(knlv1beta1.Lab)
+	err := r.Get(ctx, types.NamespacedName{Namespace: snap.Namespace, Name: snap.Spec.Lab}, lab)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			snap.Status.Phase = knlv1beta1.SnapshotPhaseFailed
+			snap.Status.Message = fmt.Sprintf("lab %v not found", snap.Spec.Lab)
+			return ctrl.Result{}, r.Status().Update(ctx, snap)
+		}
+		return ctrl.Result{}, err
+	}
+	//version is kept if the snapshot was interrupted
+	version := snap.Status.Version
+	if version == 0 {
+		version, err = r.getNextVersion(ctx, snap)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	if err = snap.Spec.Validate(lab); err != nil {
+		snap.Status.Phase = knlv1beta1.SnapshotPhaseFailed
+		snap.Status.Message = err.Error()
+		return ctrl.Result{}, r.Status().Update(ctx, snap)
+	}
+	disk := snap.Spec.Disk != nil && *snap.Spec.Disk
+	if snap.Status.Phase != knlv1beta1.SnapshotPhaseArchived {
+		snap.Status.Phase = knlv1beta1.SnapshotPhaseInProgress
+		snap.Status.Version = version
+		snap.Status.Archive = filepath.Join(knlv1beta1.GetSnapshotFolder(lab.Namespace, lab.Name),
+			fmt.Sprintf("v%d-%v.tar.gz", version, snap.Name))
+		if err = r.Status().Update(ctx, snap); err != nil {
+			return ctrl.Result{}, err
+		}
+		logger.Info("taking snapshot", "lab", lab.Name, "version", version)
+		nodes, err := r.writeArchive(ctx, lab, snap)
+		setArchiveResult(snap, nodes, err)
+		if snap.Status.Phase == knlv1beta1.SnapshotPhaseFailed {
+			return ctrl.Result{}, r.Status().Update(ctx, snap)
+		}
+	}
+	if disk {
+		vols, ready, err := r.ensureVolumeSnapshots(ctx, lab, snap)
+		snap.Status.Volumes = vols
+		if err != nil {
+			snap.Status.Phase = knlv1beta1.SnapshotPhaseFailed
+			snap.Status.Message = err.Error()
+			return ctrl.Result{}, r.Status().Update(ctx, snap)
+		}
+		if !ready {
+			if err = r.Status().Update(ctx, snap); err != nil {
+				return ctrl.Result{}, err
+			}
+			return ctrl.Result{RequeueAfter: volumeSnapshotCheckInterval}, nil
+		}
+	}
+	snap.Status.Phase = knlv1beta1.SnapshotPhaseCompleted
+	now := metav1.Now()
+	snap.Status.CompletionTime = &now
+	return ctrl.Result{}, r.Status().Update(ctx, snap)
+}
+
+// setArchiveResult records result of writing the archive in status of snap,
+// the phase is Failed if the archive failed and disks are not requested, otherwise Archived,
+// so that the archive is not written again while waiting for VolumeSnapshots
+func setArchiveResult(snap *knlv1beta1.LabSnapshot, nodes map[string]knlv1beta1.NodeSnapshotStatus, err error) {
+	snap.Status.Nodes = nodes
+	if err != nil {
+		snap.Status.Archive = ""
+		snap.Status.Message = err.Error()
+		if snap.Spec.Disk == nil || !*snap.Spec.Disk {
+			snap.Status.Phase = knlv1beta1.SnapshotPhaseFailed
+			return
+		}
+	} else {
+		failed := 0
+		for _, n := range nodes {
+			if n.Error != "" {
+				failed++
+			}
+		}
+		if failed > 0 {
+			snap.Status.Message = fmt.Sprintf("failed to collect %d of %d nodes", failed, len(nodes))
+		}
+	}
+	snap.Status.Phase = knlv1beta1.SnapshotPhaseArchived
+}
+
+// ensureVolumeSnapshots creates a VolumeSnapshot for each disk of the nodes,
+// return the VolumeSnapshot names and true if all of them are ready to use
+func (r *LabSnapshotReconciler) ensureVolumeSnapshots(ctx context.Context, lab *knlv1beta1.Lab, snap *knlv1beta1.LabSnapshot) (map[string]string, bool, error) {
+	gconf := lab.GetConfig()
+	volNames, err := r.getLabVolumes(ctx, lab, snap.Spec.Nodes)
+	if err != nil {
+		return nil, false, err
+	}
+	if len(volNames) == 0 {
+		return nil, false, fmt.Errorf("no disk found in lab %v", lab.Name)
+	}
+	rmap := make(map[string]string)
+	allReady := true
+	for _, volName := range volNames {
+		key := knlv1beta1.GetVolumeSnapshotKey(lab.Name, volName)
+		vs := knlv1beta1.NewVolumeSnapshot(lab.Namespace, lab.Name, strings.ToLower(snap.Name+"-"+key), volName, gconf.VolumeSnapshotClass)
+		rmap[key] = vs.GetName()
+		existing := new(unstructured.Unstructured)
+		existing.SetGroupVersionKind(knlv1beta1.VolumeSnapshotGVK)
+		err = r.Get(ctx, types.NamespacedName{Namespace: vs.GetNamespace(), Name: vs.GetName()}, existing)
+		if err != nil {
+			if !apierrors.IsNotFound(err) {
+				return rmap, false, fmt.Errorf("failed to get VolumeSnapshot %v, %w", vs.GetName(), err)
+			}
+			if err = ctrl.SetControllerReference(snap, vs, r.Scheme); err != nil {
+				return rmap, false, knlv1beta1.MakeErr(err)
+			}
+			if err = r.Create(ctx, vs); err != nil {
+				return rmap, false, fmt.Errorf("failed to create VolumeSnapshot %v, %w", vs.GetName(), err)
+			}
+			allReady = false
+			continue
+		}
+		if errMsg, found, _ := unstructured.NestedString(existing.Object, "status", "error", "message"); found && errMsg != "" {
+			return rmap, false, fmt.Errorf("VolumeSnapshot %v failed, %v", vs.GetName(), errMsg)
+		}
+		if ready, _, _ := unstructured.NestedBool(existing.Object, "status", "readyToUse"); !ready {
+			allReady = false
+		}
+	}
+	return rmap, allReady, nil
+}
+
+// getLabVolumes returns sorted names of PVCs of the lab, including PVCs of DataVolumes;
+// only PVCs of nodes are returned if nodes is not empty
+func (r *LabSnapshotReconciler) getLabVolumes(ctx context.Context, lab *knlv1beta1.Lab, nodes []string) ([]string, error) {
+	//key is the PVC name, value is the node it belongs to
+	volMap := make(map[string]string)
+	pvcList := new(corev1.PersistentVolumeClaimList)
+	err := r.List(ctx, pvcList, client.InNamespace(lab.Namespace), client.MatchingLabels{knlv1beta1.K8SLABELSETUPKEY: lab.Name})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list PVCs of lab %v, %w", lab.Name, err)
+	}
+	for _, pvc := range pvcList.Items {
+		volMap[pvc.Name] = pvc.Labels[knlv1beta1.ChassisNameAnnotation]
+	}
+	dvList := new(cdiv1.DataVolumeList)
+	err = r.List(ctx, dvList, client.InNamespace(lab.Namespace), client.MatchingLabels{knlv1beta1.K8SLABELSETUPKEY: lab.Name})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list DataVolumes of lab %v, %w", lab.Name, err)
+	}
+	for _, dv := range dvList.Items {
+		volMap[dv.Name] = dv.Labels[knlv1beta1.ChassisNameAnnotation]
+	}
+	return selectNodeVolumes(volMap, nodes), nil
+}
+
+// selectNodeVolumes returns sorted names of volumes in volMap belonging to nodes, all volumes if nodes is empty;
+// key of volMap is the volume name, value is the node name from its label
+func selectNodeVolumes(volMap map[string]string, nodes []string) []string {
+	nodeMap := make(map[string]bool)
+	for _, node := range nodes {
+		nodeMap[node] = true
+	}
+	rlist := []string{}
+	for _, name := range knlv1beta1.GetSortedKeySlice(volMap) {
+		if len(nodes) == 0 || nodeMap[volMap[name]] {
+			rlist = append(rlist, name)
+		}
+	}
+	return rlist
+}
+
+// getNextVersion returns the next snapshot version of the lab
+func (r *LabSnapshotReconciler) getNextVersion(ctx context.Context, snap *knlv1beta1.LabSnapshot) (int, error) {
+	snapList := new(knlv1beta1.LabSnapshotList)
+	if err := r.List(ctx, snapList, client.InNamespace(snap.Namespace)); err != nil {
+		return 0, fmt.Errorf("failed to list snapshots, %w", err)
+	}
+	version := 0
+	for _, s := range snapList.Items {
+		if s.Spec.Lab == snap.Spec.Lab && s.Status.Version > version {
+			version = s.Status.Version
+		}
+	}
+	return version + 1, nil
+}
+
+// writeArchive collects configuration of the nodes into snap's archive,
+// a node failed to be collected is recorded in returned node status, only return error if no node is collected
+func (r *LabSnapshotReconciler) writeArchive(ctx context.Context, lab *knlv1beta1.Lab, snap *knlv1beta1.LabSnapshot) (map[string]knlv1beta1.NodeSnapshotStatus, error) {
+	nodeList := snap.Spec.Nodes
+	if len(nodeList) == 0 {
+		nodeList = knlv1beta1.GetSortedKeySlice(lab.Spec.NodeList)
+	}
+	if err := os.MkdirAll(filepath.Dir(snap.Status.Archive), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create snapshot folder, %w", err)
+	}
+	tmpPath := snap.Status.Archive + ".tmp"
+	f, err := os.Create(tmpPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create archive, %w", err)
+	}
+	defer os.Remove(tmpPath)
+	defer f.Close()
+	gzw := gzip.NewWriter(f)
+	tw := tar.NewWriter(gzw)
+	rmap := make(map[string]knlv1beta1.NodeSnapshotStatus)
+	collected := 0
+	for _, nodeName := range nodeList {
+		count, err := r.collectNode(ctx, lab, snap, nodeName, tw)
+		if err != nil {
+			rmap[nodeName] = knlv1beta1.NodeSnapshotStatus{Error: err.Error()}
+			continue
+		}
+		rmap[nodeName] = knlv1beta1.NodeSnapshotStatus{Files: count}
+		collected++
+	}
+	if err = tw.Close(); err != nil {
+		return rmap, fmt.Errorf("failed to write archive, %w", err)
+	}
+	if err = gzw.Close(); err != nil {
+		return rmap, fmt.Errorf("failed to write archive, %w", err)
+	}
+	if err = f.Close(); err != nil {
+		return rmap, fmt.Errorf("failed to write archive, %w", err)
+	}
+	if collected == 0 {
+		return rmap, fmt.Errorf("no node is collected")
+	}
+	if err = os.Rename(tmpPath, snap.Status.Archive); err != nil {
+		return rmap, fmt.Errorf("failed to write archive, %w", err)
+	}
+	return rmap, nil
+}
+
+// collectNode collects configuration of a node into tw, entries are prefixed with node name;
+// the node is collected into a temporary file next to the archive first, so a failed node doesn't corrupt the archive
+func (r *LabSnapshotReconciler) collectNode(ctx context.Context, lab *knlv1beta1.Lab, snap *knlv1beta1.LabSnapshot, nodeName string, tw *tar.Writer) (int, error) {
+	src, err := snap.Spec.GetCfgSnapshotSource(lab, nodeName)
+	if err != nil {
+		return 0, err
+	}
+	nodeFile, err := os.CreateTemp(filepath.Dir(snap.Status.Archive), nodeName+"-*.tar")
+	if err != nil {
+		return 0, fmt.Errorf("failed to create temporary file, %w", err)
+	}
+	defer os.Remove(nodeFile.Name())
+	defer nodeFile.Close()
+	ntw := tar.NewWriter(nodeFile)
+	var count int
+	if src.LocalDir != "" {
+		count, err = knlv1beta1.AddLocalDir(ntw, nodeName, src.LocalDir)
+	} else {
+		count, err = r.execTar(ctx, lab.Namespace, src, nodeName, ntw)
+	}
+	if err != nil {
+		return 0, err
+	}
+	if err = ntw.Close(); err != nil {
+		return 0, err
+	}
+	if _, err = nodeFile.Seek(0, io.SeekStart); err != nil {
+		return 0, err
+	}
+	if _, err = knlv1beta1.AddTarStream(tw, "", nodeFile); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// execTar runs tar in the node pod container and copies the output into tw with prefix
+func (r *LabSnapshotReconciler) execTar(ctx context.Context, ns string, src *knlv1beta1.CfgSnapshotSource, prefix string, tw *tar.Writer) (int, error) {
+	pod := new(corev1.Pod)
+	if err := r.Get(ctx, types.NamespacedName{Namespace: ns, Name: src.PodName}, pod); err != nil {
+		return 0, fmt.Errorf("failed to get pod %v, %w", src.PodName, err)
+	}
+	if pod.Status.Phase != corev1.PodRunning {
+		return 0, fmt.Errorf("pod %v is not running", src.PodName)
+	}
+	cmd := append([]string{"tar", "-C", src.RootDir, "-cf", "-"}, src.Paths...)
+	count := 0
+	err := execInPod(ctx, r.Config, r.kubeClient, ns, src.PodName, src.Container, cmd,
+		func(rd io.Reader) error {
+			var err error
+			count, err = knlv1beta1.AddTarStream(tw, prefix, rd)
+			return err
+		})
+	return count, err
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *LabSnapshotReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	var err error
+	r.kubeClient, err = kubernetes.NewForConfig(r.Config)
+	if err != nil {
+		return knlv1beta1.MakeErr(err)
+	}
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&knlv1beta1.LabSnapshot{}).
+		Named("labsnapshot").
+		Complete(r)
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	knlv1beta1 "kubenetlab.net/knl/api/v1beta1"
+	cdiv1 "kubevirt.io/containerized-data-importer-api/pkg/apis/core/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("LabSnapshot Controller", func() {
+	Context("When getting volumes of a lab", func() {
+		ctx := context.Background()
+		newPVC := func(name, labName, nodeName string) *corev1.PersistentVolumeClaim {
+			return &corev1.PersistentVolumeClaim{
+				ObjectMeta: knlv1beta1.GetObjMeta(name, labName, "ns1", nodeName, knlv1beta1.FRRNode),
+			}
+		}
+		var r *LabSnapshotReconciler
+		lab := &knlv1beta1.Lab{}
+		lab.Name = "lab1"
+		lab.Namespace = "ns1"
+
+		BeforeEach(func() {
+			sch := runtime.NewScheme()
+			Expect(clientgoscheme.AddToScheme(sch)).To(Succeed())
+			Expect(cdiv1.AddToScheme(sch)).To(Succeed())
+			clnt := fake.NewClientBuilder().WithScheme(sch).WithObjects(
+				newPVC("lab1-r1-cfg", "lab1", "r1"),
+				//node name is a prefix of another node's name
+				newPVC("lab1-r1-a-cfg", "lab1", "r1-a"),
+				newPVC("lab1-r2-cfg", "lab1", "r2"),
+				newPVC("lab2-r1-cfg", "lab2", "r1"),
+				knlv1beta1.NewDV("ns1", "lab1", "vm1", knlv1beta1.VM, "lab1-vm1", "docker://vm:1", nil, nil),
+			).Build()
+			r = &LabSnapshotReconciler{Client: clnt, Scheme: sch}
+		})
+
+		DescribeTable("should return volumes of the selected nodes",
+			func(nodes []string, expect []string) {
+				vols, err := r.getLabVolumes(ctx, lab, nodes)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(vols).To(Equal(expect))
+			},
+			Entry("all nodes", nil, []string{"lab1-r1-a-cfg", "lab1-r1-cfg", "lab1-r2-cfg", "lab1-vm1"}),
+			Entry("one node", []string{"r1"}, []string{"lab1-r1-cfg"}),
+			Entry("pod and vm node", []string{"r1-a", "vm1"}, []string{"lab1-r1-a-cfg", "lab1-vm1"}),
+			Entry("unknown node", []string{"r3"}, []string{}),
+		)
+	})
+
+	Context("When setting the archive result", func() {
+		nodes := map[string]knlv1beta1.NodeSnapshotStatus{
+			"r1": {Files: 3},
+			"r2": {Error: "pod not running"},
+		}
+		DescribeTable("should set the phase and message",
+			func(disk bool, nodes map[string]knlv1beta1.NodeSnapshotStatus, err error,
+				phase knlv1beta1.SnapshotPhase, archive, message string) {
+				snap := &knlv1beta1.LabSnapshot{}
+				snap.Spec.Disk = knlv1beta1.ReturnPointerVal(disk)
+				snap.Status.Phase = knlv1beta1.SnapshotPhaseInProgress
+				snap.Status.Archive = "v1.tar.gz"
+				setArchiveResult(snap, nodes, err)
+				Expect(snap.Status.Phase).To(Equal(phase))
+				Expect(snap.Status.Archive).To(Equal(archive))
+				Expect(snap.Status.Message).To(Equal(message))
+				Expect(snap.Status.Nodes).To(Equal(nodes))
+			},
+			Entry("all nodes collected", false, map[string]knlv1beta1.NodeSnapshotStatus{"r1": {Files: 3}}, nil,
+				knlv1beta1.SnapshotPhaseArchived, "v1.tar.gz", ""),
+			Entry("some nodes failed", false, nodes, nil,
+				knlv1beta1.SnapshotPhaseArchived, "v1.tar.gz", "failed to collect 1 of 2 nodes"),
+			Entry("archive failed", false, nil, errors.New("disk full"),
+				knlv1beta1.SnapshotPhaseFailed, "", "disk full"),
+			//disks are still snapshotted if the archive failed
+			Entry("archive failed with disk snapshot", true, nil, errors.New("disk full"),
+				knlv1beta1.SnapshotPhaseArchived, "", "disk full"),
+		)
+	})
+})
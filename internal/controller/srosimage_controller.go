@@ -0,0 +1,273 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	knlv1beta1 "kubenetlab.net/knl/api/v1beta1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	srosImagePodCheckInterval = 5 * time.Second
+	srosImagePVCMountPath     = "/src"
+)
+
+// SROSImageReconciler reconciles a SROSImage object
+type SROSImageReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+	//Config is used to exec into the helper pod to read tarball from PVC
+	Config     *rest.Config
+	kubeClient kubernetes.Interface
+}
+
+// +kubebuilder:rbac:groups=knl.kubenetlab.net,resources=srosimages,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=knl.kubenetlab.net,resources=srosimages/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=knl.kubenetlab.net,resources=srosimages/finalizers,verbs=update
+
+// Reconcile ingests the release tarball into the release folder on the file server each time spec changes
+func (r *SROSImageReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+	img := new(knlv1beta1.SROSImage)
+	if err := r.Get(ctx, req.NamespacedName, img); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if !img.ObjectMeta.DeletionTimestamp.IsZero() {
+		if controllerutil.ContainsFinalizer(img, knlv1beta1.FinalizerName) {
+			if img.Status.Path != "" {
+				if err := os.RemoveAll(img.Status.Path); err != nil {
+					return ctrl.Result{}, fmt.Errorf("failed to remove release folder %v, %w", img.Status.Path, err)
+				}
+			}
+			controllerutil.RemoveFinalizer(img, knlv1beta1.FinalizerName)
+			if err := r.Update(ctx, img); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+		return ctrl.Result{}, nil
+	}
+	if !controllerutil.ContainsFinalizer(img, knlv1beta1.FinalizerName) {
+		controllerutil.AddFinalizer(img, knlv1beta1.FinalizerName)
+		if err := r.Update(ctx, img); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	if img.Status.ObservedGeneration == img.Generation && img.Status.Phase != knlv1beta1.SROSImagePhaseIngesting {
+		return ctrl.Result{}, nil
+	}
+	if err := img.Spec.Validate(); err != nil {
+		return ctrl.Result{}, r.setResult(ctx, img, nil, err)
+	}
+	if img.Status.Phase != knlv1beta1.SROSImagePhaseIngesting {
+		img.Status.Phase = knlv1beta1.SROSImagePhaseIngesting
+		img.Status.Message = ""
+		if err := r.Status().Update(ctx, img); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	var files map[string]string
+	var err error
+	if img.Spec.URL != nil {
+		files, err = r.ingestFromHTTP(ctx, img)
+	} else {
+		var ready bool
+		ready, files, err = r.ingestFromPVC(ctx, img)
+		if err == nil && !ready {
+			return ctrl.Result{RequeueAfter: srosImagePodCheckInterval}, nil
+		}
+	}
+	if err == nil {
+		logger.Info("SR OS release ingested", "release", img.Name)
+	}
+	return ctrl.Result{}, r.setResult(ctx, img, files, err)
+}
+
+// setResult updates status with ingest result
+func (r *SROSImageReconciler) setResult(ctx context.Context, img *knlv1beta1.SROSImage, files map[string]string, ingestErr error) error {
+	img.Status.ObservedGeneration = img.Generation
+	if ingestErr != nil {
+		img.Status.Phase = knlv1beta1.SROSImagePhaseFailed
+		img.Status.Message = ingestErr.Error()
+		img.Status.Files = nil
+	} else {
+		img.Status.Phase = knlv1beta1.SROSImagePhaseAvailable
+		img.Status.Message = ""
+		img.Status.Files = files
+		img.Status.Path = knlv1beta1.GetSROSImageFolder(img.Name)
+	}
+	return r.Status().Update(ctx, img)
+}
+
+// ingest extracts tarball r into a temporary folder, verifies it and then moves it to the release folder
+func (r *SROSImageReconciler) ingest(img *knlv1beta1.SROSImage, rd io.Reader) (map[string]string, error) {
+	tmpDir := knlv1beta1.GetSROSImageFolder(fmt.Sprintf(".%v-ingest", img.Name))
+	if err := os.RemoveAll(tmpDir); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(tmpDir, 0755); err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmpDir)
+	if err := knlv1beta1.ExtractSROSImage(rd, tmpDir); err != nil {
+		return nil, fmt.Errorf("failed to extract release tarball, %w", err)
+	}
+	root, err := knlv1beta1.FindSROSImageRoot(tmpDir)
+	if err != nil {
+		return nil, err
+	}
+	files, err := knlv1beta1.VerifySROSImage(root, img.Spec.Checksums)
+	if err != nil {
+		return nil, err
+	}
+	releaseDir := knlv1beta1.GetSROSImageFolder(img.Name)
+	if err = os.RemoveAll(releaseDir); err != nil {
+		return nil, err
+	}
+	if err = os.Rename(root, releaseDir); err != nil {
+		return nil, fmt.Errorf("failed to move release into %v, %w", releaseDir, err)
+	}
+	if err = os.Chmod(releaseDir, 0755); err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+func (r *SROSImageReconciler) ingestFromHTTP(ctx context.Context, img *knlv1beta1.SROSImage) (map[string]string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, *img.Spec.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %v, %w", *img.Spec.URL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download %v, %v", *img.Spec.URL, resp.Status)
+	}
+	return r.ingest(img, resp.Body)
+}
+
+func getSROSImagePodName(img *knlv1beta1.SROSImage) string {
+	return "srosimage-" + img.Name
+}
+
+// ingestFromPVC reads the tarball via a helper pod mounting the PVC,
+// return false if the helper pod is not running yet
+func (r *SROSImageReconciler) ingestFromPVC(ctx context.Context, img *knlv1beta1.SROSImage) (bool, map[string]string, error) {
+	src := img.Spec.PVC
+	pod := new(corev1.Pod)
+	err := r.Get(ctx, types.NamespacedName{Namespace: src.Namespace, Name: getSROSImagePodName(img)}, pod)
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return false, nil, err
+		}
+		pod = r.newHelperPod(img)
+		if err = ctrl.SetControllerReference(img, pod, r.Scheme); err != nil {
+			return false, nil, knlv1beta1.MakeErr(err)
+		}
+		if err = r.Create(ctx, pod); err != nil {
+			return false, nil, fmt.Errorf("failed to create pod %v, %w", pod.Name, err)
+		}
+		return false, nil, nil
+	}
+	switch pod.Status.Phase {
+	case corev1.PodRunning:
+	case corev1.PodFailed, corev1.PodSucceeded:
+		r.Delete(ctx, pod)
+		return false, nil, fmt.Errorf("helper pod %v exited", pod.Name)
+	default:
+		return false, nil, nil
+	}
+	var files map[string]string
+	cmd := []string{"cat", filepath.Join(srosImagePVCMountPath, filepath.Clean("/"+src.Path))}
+	err = execInPod(ctx, r.Config, r.kubeClient, pod.Namespace, pod.Name, "main", cmd,
+		func(rd io.Reader) error {
+			var err error
+			files, err = r.ingest(img, rd)
+			return err
+		})
+	if delErr := r.Delete(ctx, pod); delErr != nil && !apierrors.IsNotFound(delErr) {
+		err = errors.Join(err, delErr)
+	}
+	return true, files, err
+}
+
+func (r *SROSImageReconciler) newHelperPod(img *knlv1beta1.SROSImage) *corev1.Pod {
+	pod := new(corev1.Pod)
+	pod.Name = getSROSImagePodName(img)
+	pod.Namespace = img.Spec.PVC.Namespace
+	pod.Spec.RestartPolicy = corev1.RestartPolicyNever
+	pod.Spec.Containers = []corev1.Container{
+		{
+			Name:    "main",
+			Image:   *img.GetConfig().SideCarHookImg,
+			Command: []string{"sleep", "infinity"},
+			VolumeMounts: []corev1.VolumeMount{
+				{
+					Name:      "src",
+					MountPath: srosImagePVCMountPath,
+					ReadOnly:  true,
+				},
+			},
+		},
+	}
+	pod.Spec.Volumes = []corev1.Volume{
+		{
+			Name: "src",
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+					ClaimName: img.Spec.PVC.Name,
+					ReadOnly:  true,
+				},
+			},
+		},
+	}
+	return pod
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *SROSImageReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	var err error
+	r.kubeClient, err = kubernetes.NewForConfig(r.Config)
+	if err != nil {
+		return knlv1beta1.MakeErr(err)
+	}
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&knlv1beta1.SROSImage{}).
+		Owns(&corev1.Pod{}).
+		Named("srosimage").
+		Complete(r)
+}
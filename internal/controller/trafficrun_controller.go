@@ -0,0 +1,250 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	knlv1beta1 "kubenetlab.net/knl/api/v1beta1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	trafficRunCheckInterval = 5 * time.Second
+	//a run is failed if result is not available after duration plus the grace period
+	trafficRunGracePeriod = 2 * time.Minute
+)
+
+// TrafficRunReconciler reconciles a TrafficRun object
+type TrafficRunReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+	//Config is used to exec into the trafficgen pod
+	Config *rest.Config
+	//APIReader reads runs directly from the API server, the cache might not have the latest started run yet;
+	//Client is used if it is nil
+	APIReader  client.Reader
+	kubeClient kubernetes.Interface
+}
+
+// +kubebuilder:rbac:groups=knl.kubenetlab.net,resources=trafficruns,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=knl.kubenetlab.net,resources=trafficruns/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=knl.kubenetlab.net,resources=trafficruns/finalizers,verbs=update
+
+// Reconcile starts the run in the trafficgen pod, and polls until its result is available
+func (r *TrafficRunReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+	run := new(knlv1beta1.TrafficRun)
+	if err := r.Get(ctx, req.NamespacedName, run); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if !run.ObjectMeta.DeletionTimestamp.IsZero() {
+		if controllerutil.ContainsFinalizer(run, knlv1beta1.FinalizerName) {
+			if run.Status.Phase == knlv1beta1.TrafficRunPhaseRunning {
+				//best effort, the pod might be gone with the lab
+				if tg, err := r.getTrafficGen(ctx, run); err == nil {
+					r.exec(ctx, run, tg.StopCmd(run.Name))
+				}
+			}
+			controllerutil.RemoveFinalizer(run, knlv1beta1.FinalizerName)
+			if err := r.Update(ctx, run); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+		return ctrl.Result{}, nil
+	}
+	switch run.Status.Phase {
+	case knlv1beta1.TrafficRunPhaseCompleted, knlv1beta1.TrafficRunPhaseStopped, knlv1beta1.TrafficRunPhaseFailed:
+		return ctrl.Result{}, nil
+	}
+	if !controllerutil.ContainsFinalizer(run, knlv1beta1.FinalizerName) {
+		controllerutil.AddFinalizer(run, knlv1beta1.FinalizerName)
+		if err := r.Update(ctx, run); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	tg, err := r.getTrafficGen(ctx, run)
+	if err != nil {
+		return ctrl.Result{}, r.setResult(ctx, run, knlv1beta1.TrafficRunPhaseFailed, nil, err)
+	}
+	if run.Status.Phase == knlv1beta1.TrafficRunPhaseRunning {
+		return r.collect(ctx, run, tg)
+	}
+	//not started yet
+	if run.Spec.Stop != nil && *run.Spec.Stop {
+		return ctrl.Result{}, r.setResult(ctx, run, knlv1beta1.TrafficRunPhaseStopped, nil, nil)
+	}
+	if tg.Profile == nil {
+		return ctrl.Result{}, r.setResult(ctx, run, knlv1beta1.TrafficRunPhaseFailed, nil,
+			fmt.Errorf("node %v doesn't specify a profile", run.Spec.Node))
+	}
+	ready, err := r.isReady(ctx, run)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if !ready {
+		if run.Status.Phase != knlv1beta1.TrafficRunPhasePending {
+			run.Status.Phase = knlv1beta1.TrafficRunPhasePending
+			if err := r.Status().Update(ctx, run); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+		return ctrl.Result{RequeueAfter: trafficRunCheckInterval}, nil
+	}
+	if _, err = r.exec(ctx, run, tg.StartCmd(run.Name, getTrafficRunDuration(run))); err != nil {
+		return ctrl.Result{}, r.setResult(ctx, run, knlv1beta1.TrafficRunPhaseFailed, nil, err)
+	}
+	logger.Info("traffic run started", "lab", run.Spec.Lab, "node", run.Spec.Node)
+	run.Status.Phase = knlv1beta1.TrafficRunPhaseRunning
+	run.Status.StartTime = &metav1.Time{Time: time.Now()}
+	run.Status.Message = ""
+	if err := r.Status().Update(ctx, run); err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{RequeueAfter: time.Duration(getTrafficRunDuration(run)) * time.Second}, nil
+}
+
+func getTrafficRunDuration(run *knlv1beta1.TrafficRun) int32 {
+	if run.Spec.Duration == nil || *run.Spec.Duration <= 0 {
+		return knlv1beta1.DefaultTrafficDuration
+	}
+	return *run.Spec.Duration
+}
+
+// collect stops the run if requested, and sets the result if it is available
+func (r *TrafficRunReconciler) collect(ctx context.Context, run *knlv1beta1.TrafficRun, tg *knlv1beta1.TrafficGen) (ctrl.Result, error) {
+	phase := knlv1beta1.TrafficRunPhaseCompleted
+	if run.Spec.Stop != nil && *run.Spec.Stop {
+		phase = knlv1beta1.TrafficRunPhaseStopped
+		if _, err := r.exec(ctx, run, tg.StopCmd(run.Name)); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	out, err := r.exec(ctx, run, tg.CollectCmd(run.Name))
+	if err != nil {
+		return ctrl.Result{}, r.setResult(ctx, run, knlv1beta1.TrafficRunPhaseFailed, nil, err)
+	}
+	streams, err := tg.ParseResult(out)
+	if err != nil {
+		return ctrl.Result{}, r.setResult(ctx, run, knlv1beta1.TrafficRunPhaseFailed, nil, err)
+	}
+	if streams == nil {
+		deadline := run.Status.StartTime.Add(time.Duration(getTrafficRunDuration(run))*time.Second + trafficRunGracePeriod)
+		if time.Now().After(deadline) {
+			return ctrl.Result{}, r.setResult(ctx, run, knlv1beta1.TrafficRunPhaseFailed, nil, fmt.Errorf("timeout waiting for result"))
+		}
+		return ctrl.Result{RequeueAfter: trafficRunCheckInterval}, nil
+	}
+	return ctrl.Result{}, r.setResult(ctx, run, phase, streams, nil)
+}
+
+// setResult updates status with the final phase of the run
+func (r *TrafficRunReconciler) setResult(ctx context.Context, run *knlv1beta1.TrafficRun, phase knlv1beta1.TrafficRunPhase,
+	streams []knlv1beta1.TrafficStreamStats, runErr error) error {
+	run.Status.Phase = phase
+	run.Status.Streams = streams
+	run.Status.CompletionTime = &metav1.Time{Time: time.Now()}
+	run.Status.Message = ""
+	if runErr != nil {
+		run.Status.Message = runErr.Error()
+	}
+	return r.Status().Update(ctx, run)
+}
+
+// getTrafficGen returns the trafficgen node of the run
+func (r *TrafficRunReconciler) getTrafficGen(ctx context.Context, run *knlv1beta1.TrafficRun) (*knlv1beta1.TrafficGen, error) {
+	lab := new(knlv1beta1.Lab)
+	if err := r.Get(ctx, types.NamespacedName{Namespace: run.Namespace, Name: run.Spec.Lab}, lab); err != nil {
+		return nil, fmt.Errorf("failed to get lab %v, %w", run.Spec.Lab, err)
+	}
+	node, ok := lab.Spec.NodeList[run.Spec.Node]
+	if !ok || node == nil {
+		return nil, fmt.Errorf("node %v not found in lab %v", run.Spec.Node, lab.Name)
+	}
+	if node.TrafficGen == nil {
+		return nil, fmt.Errorf("node %v is not a trafficgen node", run.Spec.Node)
+	}
+	return node.TrafficGen, nil
+}
+
+// isReady returns true if the trafficgen pod is running and no other run of the same node is running;
+// runs are listed via APIReader, runs are reconciled one at a time so a run started by the previous reconcile is seen
+func (r *TrafficRunReconciler) isReady(ctx context.Context, run *knlv1beta1.TrafficRun) (bool, error) {
+	pod := new(corev1.Pod)
+	err := r.Get(ctx, types.NamespacedName{Namespace: run.Namespace, Name: knlv1beta1.GetPodName(run.Spec.Lab, run.Spec.Node)}, pod)
+	if err != nil {
+		return false, client.IgnoreNotFound(err)
+	}
+	if pod.Status.Phase != corev1.PodRunning {
+		return false, nil
+	}
+	var reader client.Reader = r.Client
+	if r.APIReader != nil {
+		reader = r.APIReader
+	}
+	runList := new(knlv1beta1.TrafficRunList)
+	if err = reader.List(ctx, runList, client.InNamespace(run.Namespace)); err != nil {
+		return false, err
+	}
+	for _, other := range runList.Items {
+		if other.Name != run.Name && other.Spec.Lab == run.Spec.Lab && other.Spec.Node == run.Spec.Node &&
+			other.Status.Phase == knlv1beta1.TrafficRunPhaseRunning {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// exec runs cmd in the trafficgen pod and returns its output
+func (r *TrafficRunReconciler) exec(ctx context.Context, run *knlv1beta1.TrafficRun, cmd []string) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	err := execInPod(ctx, r.Config, r.kubeClient, run.Namespace, knlv1beta1.GetPodName(run.Spec.Lab, run.Spec.Node), "main", cmd,
+		func(rd io.Reader) error {
+			_, err := io.Copy(buf, rd)
+			return err
+		})
+	return buf.Bytes(), err
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *TrafficRunReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	var err error
+	r.kubeClient, err = kubernetes.NewForConfig(r.Config)
+	if err != nil {
+		return knlv1beta1.MakeErr(err)
+	}
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&knlv1beta1.TrafficRun{}).
+		Named("trafficrun").
+		//isReady relies on runs being started one at a time
+		WithOptions(controller.Options{MaxConcurrentReconciles: 1}).
+		Complete(r)
+}
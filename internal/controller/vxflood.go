@@ -0,0 +1,98 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/netip"
+	"strings"
+	"time"
+
+	k8slan "github.com/hujun-open/k8slan/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	knlv1beta1 "kubenetlab.net/knl/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// vxFloodSyncInterval is the interval flood lists of a lab are programmed in unicast mode,
+// VxLAN interfaces are created by k8slan when a pod using the LAN is scheduled on a worker, and workers could join later
+const vxFloodSyncInterval = time.Minute
+
+// execAgent runs cmd in the VxLAN agent pod and returns its output
+func (r *LabReconciler) execAgent(ctx context.Context, podName string, cmd []string) (string, error) {
+	buf := new(bytes.Buffer)
+	err := execInPod(ctx, r.Config, r.kubeClient, knlv1beta1.MYNAMESPACE, podName, knlv1beta1.VxLANAgentContainer, cmd,
+		func(rd io.Reader) error {
+			_, err := io.Copy(buf, rd)
+			return err
+		})
+	return buf.String(), err
+}
+
+// syncFloodLists programs head-end replication flood list of VxLAN interface of each LAN in the lab on every worker,
+// the list contains address of VxLAN device of all other workers
+func (r *LabReconciler) syncFloodLists(ctx context.Context, lab *knlv1beta1.Lab) error {
+	if r.kubeClient == nil {
+		return fmt.Errorf("no kube client to program VxLAN flood lists")
+	}
+	conf := lab.GetConfig()
+	if err := r.Create(ctx, knlv1beta1.NewVxLANAgentDaemonSet(*conf.VxLANAgentImage)); err != nil && !apierrors.IsAlreadyExists(err) {
+		return fmt.Errorf("failed to create VxLAN agent, %w", err)
+	}
+	podList := new(corev1.PodList)
+	if err := r.List(ctx, podList, client.InNamespace(knlv1beta1.MYNAMESPACE),
+		client.MatchingLabels{knlv1beta1.K8SLABELAPPKey: knlv1beta1.VxLANAgentName}); err != nil {
+		return fmt.Errorf("failed to list VxLAN agent pods, %w", err)
+	}
+	//discover worker addresses, key is the agent pod name;
+	//a worker whose address can't be got is skipped, it is retried in next sync
+	logger := log.FromContext(ctx)
+	workerAddrs := make(map[string]netip.Addr)
+	for _, pod := range podList.Items {
+		if pod.Status.Phase != corev1.PodRunning || pod.Spec.NodeName == "" {
+			continue
+		}
+		dev := conf.GetVxDev(pod.Spec.NodeName)
+		out, err := r.execAgent(ctx, pod.Name, knlv1beta1.GetVxDevAddrCmd(dev, conf.IsVxLANUnderlayIPv4()))
+		if err == nil {
+			var addr netip.Addr
+			if addr, err = knlv1beta1.ParseVxDevAddr(out); err == nil {
+				workerAddrs[pod.Name] = addr
+				continue
+			}
+		}
+		logger.Error(err, "skipped worker, failed to get address of VxLAN device", "worker", pod.Spec.NodeName, "device", dev)
+	}
+	lans := []*k8slan.LAN{}
+	for _, linkName := range knlv1beta1.GetSortedKeySlice(lab.Spec.LinkList) {
+		if lab.Spec.LinkList[linkName].IsLocal() {
+			continue
+		}
+		lan := new(k8slan.LAN)
+		lanKey := lab.GetLANKey(linkName)
+		if err := r.Get(ctx, lanKey, lan); err != nil {
+			return fmt.Errorf("failed to get LAN %v, %w", lanKey, err)
+		}
+		lans = append(lans, lan)
+	}
+	//one exec per worker for all LANs of the lab
+	for _, podName := range knlv1beta1.GetSortedKeySlice(workerAddrs) {
+		dsts := []netip.Addr{}
+		for _, peer := range knlv1beta1.GetSortedKeySlice(workerAddrs) {
+			if peer != podName {
+				dsts = append(dsts, workerAddrs[peer])
+			}
+		}
+		var script strings.Builder
+		for _, lan := range lans {
+			fmt.Fprintf(&script, "(\n%v)\n", knlv1beta1.GenFloodScript(*lan.Spec.NS, *lan.Spec.VxLANName, dsts))
+		}
+		if _, err := r.execAgent(ctx, podName, []string{"sh", "-c", script.String()}); err != nil {
+			logger.Error(err, "failed to program VxLAN flood lists", "agent", podName)
+		}
+	}
+	return nil
+}
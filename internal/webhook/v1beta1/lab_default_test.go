@@ -0,0 +1,245 @@
+package v1beta1
+
+import (
+	"context"
+	"testing"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	knlv1beta1 "kubenetlab.net/knl/api/v1beta1"
+)
+
+func newAdmissionCtx(op admissionv1.Operation) context.Context {
+	return admission.NewContextWithRequest(context.Background(), admission.Request{
+		AdmissionRequest: admissionv1.AdmissionRequest{Operation: op, Namespace: "default"},
+	})
+}
+
+func TestLabDefaultIdempotent(t *testing.T) {
+	lab := &knlv1beta1.Lab{}
+	lab.Name = "lab1"
+	lab.Namespace = "default"
+	lab.Spec.NodeList = map[string]*knlv1beta1.OneOfSystem{
+		"hub": {Pod: &knlv1beta1.GeneralPod{Image: knlv1beta1.ReturnPointerVal("alpine")}},
+	}
+	lab.Spec.LinkList = map[string]*knlv1beta1.Link{
+		"core": {Connectors: []knlv1beta1.Connector{
+			{NodeName: knlv1beta1.ReturnPointerVal("hub")},
+			{NodeName: knlv1beta1.ReturnPointerVal("edge")},
+		}},
+	}
+	lab.Spec.NodeList["edge"] = &knlv1beta1.OneOfSystem{Pod: &knlv1beta1.GeneralPod{Image: knlv1beta1.ReturnPointerVal("alpine")}}
+	lab.Spec.NodeGroups = map[string]*knlv1beta1.NodeGroup{
+		"subscribers": {
+			NamePattern: "pod-%d",
+			Replicas:    5,
+			Template:    &knlv1beta1.OneOfSystem{Pod: &knlv1beta1.GeneralPod{Image: knlv1beta1.ReturnPointerVal("alpine")}},
+			Links: []knlv1beta1.NodeGroupLink{
+				{
+					Link:   "access",
+					Shared: knlv1beta1.ReturnPointerVal(true),
+					Peers:  []knlv1beta1.Connector{{NodeName: knlv1beta1.ReturnPointerVal("hub")}},
+				},
+			},
+		},
+	}
+	d := &LabCustomDefaulter{}
+	if err := d.Default(newAdmissionCtx(admissionv1.Create), lab); err != nil {
+		t.Fatal(err)
+	}
+	created := lab.DeepCopy()
+	//the controller updates the lab, e.g. to add the finalizer, the spec must stay the same
+	for range 3 {
+		if err := d.Default(newAdmissionCtx(admissionv1.Update), lab); err != nil {
+			t.Fatal(err)
+		}
+		if !equality.Semantic.DeepEqual(created.Spec, lab.Spec) {
+			t.Fatalf("spec changed by defaulting on update")
+		}
+	}
+	if _, err := (&LabCustomValidator{}).ValidateUpdate(context.Background(), created, lab); err != nil {
+		t.Fatal(err)
+	}
+	macs := make(map[string]string)
+	for _, linkName := range knlv1beta1.GetSortedKeySlice(lab.Spec.LinkList) {
+		for _, c := range lab.Spec.LinkList[linkName].Connectors {
+			if c.Mac == nil {
+				t.Fatalf("connector %v of link %v has no mac", *c.NodeName, linkName)
+			}
+			if prev, ok := macs[*c.Mac]; ok {
+				t.Fatalf("mac %v of %v in link %v is same as %v", *c.Mac, *c.NodeName, linkName, prev)
+			}
+			macs[*c.Mac] = *c.NodeName
+		}
+	}
+	if len(macs) != 8 {
+		t.Fatalf("expect 8 connectors, got %d", len(macs))
+	}
+}
+
+func TestLabDefaultProfileOnUpdate(t *testing.T) {
+	sch := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(sch); err != nil {
+		t.Fatal(err)
+	}
+	if err := knlv1beta1.AddToScheme(sch); err != nil {
+		t.Fatal(err)
+	}
+	ns := &corev1.Namespace{}
+	ns.Name = "default"
+	profile := &knlv1beta1.NodeProfile{}
+	profile.Name = "p1"
+	profile.Namespace = "default"
+	profile.Spec.Pod = &knlv1beta1.GeneralPod{Image: knlv1beta1.ReturnPointerVal("alpine")}
+	clnt := fake.NewClientBuilder().WithScheme(sch).WithObjects(ns, profile).Build()
+	lab := &knlv1beta1.Lab{}
+	lab.Name = "lab1"
+	lab.Namespace = "default"
+	lab.Spec.NodeList = map[string]*knlv1beta1.OneOfSystem{"n1": {Profile: knlv1beta1.ReturnPointerVal("p1")}}
+	d := &LabCustomDefaulter{Client: clnt}
+	if err := d.Default(newAdmissionCtx(admissionv1.Create), lab); err != nil {
+		t.Fatal(err)
+	}
+	if pod := lab.Spec.NodeList["n1"].Pod; pod == nil || *pod.Image != "alpine" || pod.Command != nil {
+		t.Fatalf("profile is not applied, %+v", lab.Spec.NodeList["n1"])
+	}
+	created := lab.DeepCopy()
+	//profile changed, the lab keeps what was applied on creation
+	profile.Spec.Pod.Command = knlv1beta1.ReturnPointerVal("sleep infinity")
+	if err := clnt.Update(context.Background(), profile); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Default(newAdmissionCtx(admissionv1.Update), lab); err != nil {
+		t.Fatal(err)
+	}
+	if !equality.Semantic.DeepEqual(created.Spec, lab.Spec) {
+		t.Fatalf("spec changed by profile update, %+v", lab.Spec.NodeList["n1"].Pod)
+	}
+	//profile removed, e.g. before the finalizer of the lab is removed
+	if err := clnt.Delete(context.Background(), profile); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Default(newAdmissionCtx(admissionv1.Update), lab); err != nil {
+		t.Fatalf("update failed after profile is removed, %v", err)
+	}
+	if !equality.Semantic.DeepEqual(created.Spec, lab.Spec) {
+		t.Fatalf("spec changed after profile is removed")
+	}
+}
+
+func TestLabValidateRestoreFrom(t *testing.T) {
+	sch := runtime.NewScheme()
+	if err := knlv1beta1.AddToScheme(sch); err != nil {
+		t.Fatal(err)
+	}
+	snap := &knlv1beta1.LabSnapshot{}
+	snap.Name = "snap1"
+	snap.Namespace = "default"
+	snap.Status.Phase = knlv1beta1.SnapshotPhaseInProgress
+	clnt := fake.NewClientBuilder().WithScheme(sch).WithObjects(snap).Build()
+	v := &LabCustomValidator{Client: clnt}
+	lab := &knlv1beta1.Lab{}
+	lab.Name = "lab1"
+	lab.Namespace = "default"
+	lab.Spec.RestoreFrom = knlv1beta1.ReturnPointerVal("snap1")
+	ctx := newAdmissionCtx(admissionv1.Create)
+	if err := v.validateRestoreFrom(ctx, lab); err == nil {
+		t.Fatalf("expect error for snapshot not completed")
+	}
+	snap.Status.Phase = knlv1beta1.SnapshotPhaseCompleted
+	if err := clnt.Update(ctx, snap); err != nil {
+		t.Fatal(err)
+	}
+	if err := v.validateRestoreFrom(ctx, lab); err != nil {
+		t.Fatal(err)
+	}
+	lab.Spec.RestoreFrom = knlv1beta1.ReturnPointerVal("snap2")
+	if err := v.validateRestoreFrom(ctx, lab); err == nil {
+		t.Fatalf("expect error for snapshot not found")
+	}
+}
+
+func TestLabDefaultConfigOnUpdate(t *testing.T) {
+	conf := knlv1beta1.DefKNLConfig()
+	conf.UnderlayMTU = nil
+	knlv1beta1.GCONF.Set("nomtu", &conf, 1)
+	t.Cleanup(func() { knlv1beta1.GCONF.Remove("nomtu") })
+	lab := &knlv1beta1.Lab{}
+	lab.Name = "lab1"
+	lab.Namespace = "default"
+	lab.Spec.Config = knlv1beta1.ReturnPointerVal("nomtu")
+	lab.Spec.NodeList = map[string]*knlv1beta1.OneOfSystem{
+		"pod1": {Pod: &knlv1beta1.GeneralPod{Image: knlv1beta1.ReturnPointerVal("alpine")}},
+		"pod2": {Pod: &knlv1beta1.GeneralPod{Image: knlv1beta1.ReturnPointerVal("alpine")}},
+	}
+	lab.Spec.LinkList = map[string]*knlv1beta1.Link{
+		"link1": {Connectors: []knlv1beta1.Connector{
+			{NodeName: knlv1beta1.ReturnPointerVal("pod1")},
+			{NodeName: knlv1beta1.ReturnPointerVal("pod2")},
+		}},
+	}
+	d := &LabCustomDefaulter{}
+	if err := d.Default(newAdmissionCtx(admissionv1.Create), lab); err != nil {
+		t.Fatal(err)
+	}
+	if lab.Spec.LinkList["link1"].MTU != nil {
+		t.Fatalf("link mtu should not be set without underlay mtu")
+	}
+	created := lab.DeepCopy()
+	//underlay mtu is set in KNLConfig after the lab is created
+	updated := knlv1beta1.DefKNLConfig()
+	knlv1beta1.GCONF.Set("nomtu", &updated, 2)
+	if err := d.Default(newAdmissionCtx(admissionv1.Update), lab); err != nil {
+		t.Fatal(err)
+	}
+	if !equality.Semantic.DeepEqual(created.Spec, lab.Spec) {
+		t.Fatalf("spec changed by KNLConfig update, link mtu %v", lab.Spec.LinkList["link1"].MTU)
+	}
+	//the finalizer is removed on deletion
+	now := metav1.Now()
+	lab.DeletionTimestamp = &now
+	if err := d.Default(newAdmissionCtx(admissionv1.Update), lab); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := (&LabCustomValidator{}).ValidateUpdate(context.Background(), created, lab); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLabDefaultGeneratorOnUpdate(t *testing.T) {
+	lab := &knlv1beta1.Lab{}
+	lab.Name = "lab1"
+	lab.Namespace = "default"
+	lab.Spec.Generator = &knlv1beta1.TopologyGenerator{
+		Shape: knlv1beta1.ShapeRing,
+		Roles: map[string]*knlv1beta1.GeneratorRole{
+			"node": {
+				Count:       3,
+				NamePattern: "pod-%d",
+				Template:    &knlv1beta1.OneOfSystem{Pod: &knlv1beta1.GeneralPod{Image: knlv1beta1.ReturnPointerVal("alpine")}},
+			},
+		},
+	}
+	d := &LabCustomDefaulter{}
+	if err := d.Default(newAdmissionCtx(admissionv1.Create), lab); err != nil {
+		t.Fatal(err)
+	}
+	if len(lab.Spec.NodeList) != 3 || len(lab.Spec.LinkList) != 3 {
+		t.Fatalf("expect 3 nodes and 3 links, got %d nodes and %d links", len(lab.Spec.NodeList), len(lab.Spec.LinkList))
+	}
+	//generated nodes and links are in the spec, they are not generated again on update
+	lab.Spec.Generator.Roles["node"].Count = 4
+	if err := d.Default(newAdmissionCtx(admissionv1.Update), lab); err != nil {
+		t.Fatal(err)
+	}
+	if len(lab.Spec.NodeList) != 3 || len(lab.Spec.LinkList) != 3 {
+		t.Fatalf("generator applied on update, got %d nodes and %d links", len(lab.Spec.NodeList), len(lab.Spec.LinkList))
+	}
+}
@@ -20,16 +20,23 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"strconv"
 
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 
 	"kubenetlab.net/knl/api/v1beta1"
 	knlv1beta1 "kubenetlab.net/knl/api/v1beta1"
+	"kubenetlab.net/knl/topogen"
 )
 
 // nolint:unused
@@ -39,8 +46,8 @@ var lablog = logf.Log.WithName("lab-resource")
 // SetupLabWebhookWithManager registers the webhook for Lab in the manager.
 func SetupLabWebhookWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewWebhookManagedBy(mgr).For(&knlv1beta1.Lab{}).
-		WithValidator(&LabCustomValidator{}).
-		WithDefaulter(&LabCustomDefaulter{}).
+		WithValidator(&LabCustomValidator{Client: mgr.GetClient()}).
+		WithDefaulter(&LabCustomDefaulter{Client: mgr.GetClient()}).
 		Complete()
 }
 
@@ -54,28 +61,60 @@ func SetupLabWebhookWithManager(mgr ctrl.Manager) error {
 // NOTE: The +kubebuilder:object:generate=false marker prevents controller-gen from generating DeepCopy methods,
 // as it is used only for temporary operations and does not need to be deeply copied.
 type LabCustomDefaulter struct {
-	// TODO(user): Add more fields as needed for defaulting
+	//Client is used to get the LabTemplate
+	Client client.Client
 }
 
 var _ webhook.CustomDefaulter = &LabCustomDefaulter{}
 
 // Default implements webhook.CustomDefaulter so a webhook will be registered for the Kind Lab.
-func (d *LabCustomDefaulter) Default(_ context.Context, obj runtime.Object) error {
+func (d *LabCustomDefaulter) Default(ctx context.Context, obj runtime.Object) error {
 	lab, ok := obj.(*knlv1beta1.Lab)
 
 	if !ok {
 		return fmt.Errorf("expected an Lab object but got %T", obj)
 	}
 	lablog.Info("Defaulting for Lab", "lab", lab.GetName())
+	//spec can't be changed, e.g. when the finalizer is removed
+	if lab.DeletionTimestamp != nil {
+		return nil
+	}
 	if lab.Spec.NodeList == nil {
 		lab.Spec.NodeList = make(map[string]*knlv1beta1.OneOfSystem)
 	}
+	//render template, parameter values are fixed at creation since the spec can't be changed afterwards
+	if isCreate(ctx) {
+		if err := d.renderTemplate(ctx, lab); err != nil {
+			return err
+		}
+	}
+	//generate topology and expand node groups into nodes and links,
+	//both are done only on creation, generated nodes and links are in the spec afterwards
+	if isCreate(ctx) {
+		if err := topogen.Apply(&lab.Spec); err != nil {
+			return err
+		}
+		if err := lab.Spec.ExpandNodeGroups(); err != nil {
+			return err
+		}
+	}
 	//cross check links and nodes, fill in missing node with empty OneOfSystem
-	//also fill in derived mac if it is not specified
+	//also fill in derived mac if it is not specified, in order of link name and connector
 	var macOffset int
-	for linkName, link := range lab.Spec.LinkList {
+	for _, linkName := range knlv1beta1.GetSortedKeySlice(lab.Spec.LinkList) {
+		link := lab.Spec.LinkList[linkName]
 		for i, c := range link.Connectors {
 			macOffset++
+			if c.IsExternal() {
+				continue
+			}
+			if c.IsCrossLab() {
+				//node of the peer lab
+				if c.PeerLab != nil && c.PeerLab.Namespace == nil {
+					lab.Spec.LinkList[linkName].Connectors[i].PeerLab.Namespace = knlv1beta1.ReturnPointerVal(getLabNamespace(ctx, lab))
+				}
+				continue
+			}
 			if _, ok := lab.Spec.NodeList[*c.NodeName]; !ok {
 				lab.Spec.NodeList[*c.NodeName] = new(knlv1beta1.OneOfSystem)
 			}
@@ -84,6 +123,13 @@ func (d *LabCustomDefaulter) Default(_ context.Context, obj runtime.Object) erro
 			lab.Spec.LinkList[linkName].Connectors[i].Mac = knlv1beta1.SetDefaultGeneric(lab.Spec.LinkList[linkName].Connectors[i].Mac, derivedMAC.String())
 		}
 	}
+	//lab values take precedence over the profile, then KNLConfig defaults via LoadDef;
+	//profiles are applied only on creation, so a later change or removal of the profile doesn't affect the lab
+	if isCreate(ctx) {
+		if err := d.applyProfiles(ctx, lab); err != nil {
+			return err
+		}
+	}
 	//check missing CPM
 	for nodeName := range lab.Spec.NodeList {
 		vmt, vmid, _, err := knlv1beta1.ParseSRVMName(nodeName)
@@ -112,11 +158,22 @@ func (d *LabCustomDefaulter) Default(_ context.Context, obj runtime.Object) erro
 		}
 	}
 
-	gconf := knlv1beta1.GCONF.Get()
-	lablog.Info(fmt.Sprintf("defaulting lab, got GCONF:%+v", gconf))
-	err := knlv1beta1.LoadDef(&lab.Spec, gconf)
-	if err != nil {
-		return err
+	//lab defaults take precedence over KNLConfig defaults;
+	//defaults are loaded only on creation, so a later change of KNLConfig doesn't change the spec
+	if isCreate(ctx) {
+		if lab.Spec.Defaults != nil {
+			if err := knlv1beta1.LoadDef(&lab.Spec, knlv1beta1.KNLConfigSpec{DefaultNode: lab.Spec.Defaults}); err != nil {
+				return err
+			}
+		}
+		if err := d.selectConfig(ctx, lab); err != nil {
+			return err
+		}
+		gconf := lab.GetConfig()
+		lablog.Info(fmt.Sprintf("defaulting lab, got config %v:%+v", lab.ConfigName(), gconf))
+		if err := knlv1beta1.LoadDef(&lab.Spec, gconf); err != nil {
+			return err
+		}
 	}
 	//fill node specific Default
 	// SRVMs := make(map[string]knlv1beta1.System)
@@ -131,7 +188,7 @@ func (d *LabCustomDefaulter) Default(_ context.Context, obj runtime.Object) erro
 	for linkName, link := range lab.Spec.LinkList {
 		for cid, c := range link.Connectors {
 			nt := knlv1beta1.GetNodeTypeViaName(*c.NodeName)
-			if knlv1beta1.IsSRVM(nt) {
+			if !c.IsExternal() && !c.IsCrossLab() && knlv1beta1.IsSRVM(nt) {
 				//fill port id for SRVM
 				if c.PortId != nil {
 					continue
@@ -161,6 +218,102 @@ func (d *LabCustomDefaulter) Default(_ context.Context, obj runtime.Object) erro
 	return nil
 }
 
+// getLabNamespace returns namespace of lab, it might not be set in the object on creation
+func getLabNamespace(ctx context.Context, lab *knlv1beta1.Lab) string {
+	if lab.Namespace != "" {
+		return lab.Namespace
+	}
+	if req, err := admission.RequestFromContext(ctx); err == nil {
+		return req.Namespace
+	}
+	return ""
+}
+
+// isCreate returns true if the admission request in ctx is a creation
+func isCreate(ctx context.Context) bool {
+	req, err := admission.RequestFromContext(ctx)
+	return err == nil && req.Operation == admissionv1.Create
+}
+
+// selectConfig sets config of lab from the label of lab namespace on creation, if the lab doesn't specify one
+func (d *LabCustomDefaulter) selectConfig(ctx context.Context, lab *knlv1beta1.Lab) error {
+	if lab.Spec.Config != nil || d.Client == nil {
+		return nil
+	}
+	if !isCreate(ctx) {
+		return nil
+	}
+	ns := new(corev1.Namespace)
+	if err := d.Client.Get(ctx, types.NamespacedName{Name: getLabNamespace(ctx, lab)}, ns); err != nil {
+		return fmt.Errorf("failed to get namespace of lab, %w", err)
+	}
+	if cfgName, ok := ns.Labels[knlv1beta1.KNLConfigLabelKey]; ok && cfgName != "" {
+		lab.Spec.Config = knlv1beta1.ReturnPointerVal(cfgName)
+	}
+	return nil
+}
+
+// applyProfiles fills unspecified fields of nodes referring to a profile,
+// NodeProfile in the lab namespace is used, otherwise ClusterNodeProfile of the same name
+func (d *LabCustomDefaulter) applyProfiles(ctx context.Context, lab *knlv1beta1.Lab) error {
+	for _, nodeName := range knlv1beta1.GetSortedKeySlice(lab.Spec.NodeList) {
+		node := lab.Spec.NodeList[nodeName]
+		if node == nil || node.Profile == nil {
+			continue
+		}
+		if d.Client == nil {
+			return fmt.Errorf("no client to get profile %v", *node.Profile)
+		}
+		var profile *knlv1beta1.OneOfSystem
+		nsProfile := new(knlv1beta1.NodeProfile)
+		err := d.Client.Get(ctx, types.NamespacedName{Namespace: getLabNamespace(ctx, lab), Name: *node.Profile}, nsProfile)
+		switch {
+		case err == nil:
+			profile = &nsProfile.Spec
+		case apierrors.IsNotFound(err):
+			clusterProfile := new(knlv1beta1.ClusterNodeProfile)
+			if err = d.Client.Get(ctx, types.NamespacedName{Name: *node.Profile}, clusterProfile); err != nil {
+				return fmt.Errorf("failed to get profile %v of node %v, %w", *node.Profile, nodeName, err)
+			}
+			profile = &clusterProfile.Spec
+		default:
+			return fmt.Errorf("failed to get profile %v of node %v, %w", *node.Profile, nodeName, err)
+		}
+		if err = node.ApplyProfile(profile); err != nil {
+			return fmt.Errorf("failed to apply profile %v to node %v, %w", *node.Profile, nodeName, err)
+		}
+	}
+	return nil
+}
+
+// renderTemplate merges the spec rendered from the LabTemplate into lab,
+// the rendered template generation is recorded in an annotation
+func (d *LabCustomDefaulter) renderTemplate(ctx context.Context, lab *knlv1beta1.Lab) error {
+	ref := lab.Spec.Template
+	if ref == nil {
+		return nil
+	}
+	if d.Client == nil {
+		return fmt.Errorf("no client to get template %v", ref.Name)
+	}
+	tmpl := new(knlv1beta1.LabTemplate)
+	if err := d.Client.Get(ctx, types.NamespacedName{Namespace: getLabNamespace(ctx, lab), Name: ref.Name}, tmpl); err != nil {
+		return fmt.Errorf("failed to get template %v, %w", ref.Name, err)
+	}
+	rendered, err := tmpl.Spec.Render(ref.Values)
+	if err != nil {
+		return fmt.Errorf("failed to render template %v, %w", ref.Name, err)
+	}
+	if err = lab.Spec.MergeFrom(rendered); err != nil {
+		return err
+	}
+	if lab.Annotations == nil {
+		lab.Annotations = make(map[string]string)
+	}
+	lab.Annotations[knlv1beta1.TemplateGenAnnotation] = strconv.FormatInt(tmpl.Generation, 10)
+	return nil
+}
+
 // TODO(user): change verbs to "verbs=create;update;delete" if you want to enable deletion validation.
 // NOTE: The 'path' attribute must follow a specific pattern and should not be modified directly here.
 // Modifying the path for an invalid path can cause API server errors; failing to locate the webhook.
@@ -172,22 +325,66 @@ func (d *LabCustomDefaulter) Default(_ context.Context, obj runtime.Object) erro
 // NOTE: The +kubebuilder:object:generate=false marker prevents controller-gen from generating DeepCopy methods,
 // as this struct is used only for temporary operations and does not need to be deeply copied.
 type LabCustomValidator struct {
-	// TODO(user): Add more fields as needed for validation
+	//Client is used to check workers of external connectors
+	Client client.Client
 }
 
 var _ webhook.CustomValidator = &LabCustomValidator{}
 
 // ValidateCreate implements webhook.CustomValidator so a webhook will be registered for the type Lab.
-func (v *LabCustomValidator) ValidateCreate(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+func (v *LabCustomValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
 	lab, ok := obj.(*knlv1beta1.Lab)
 	if !ok {
 		return nil, fmt.Errorf("expected a Lab object but got %T", obj)
 	}
 	lablog.Info("Validation for Lab upon creation", "name", lab.GetName())
 
-	// TODO(user): fill in your validation logic upon object creation.
+	if _, ok := knlv1beta1.GCONF.Lookup(lab.ConfigName()); !ok {
+		return nil, fmt.Errorf("KNLConfig %v not found", lab.ConfigName())
+	}
+	if err := lab.Spec.Validate(); err != nil {
+		return nil, err
+	}
+	if err := v.validateRestoreFrom(ctx, lab); err != nil {
+		return nil, err
+	}
+	if err := lab.Spec.ValidateMTU(lab.GetConfig()); err != nil {
+		return nil, err
+	}
+	if err := lab.Spec.ValidateExternals(lab.GetConfig(), func(worker string) (bool, error) {
+		err := v.Client.Get(ctx, types.NamespacedName{Name: worker}, new(corev1.Node))
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return err == nil, err
+	}); err != nil {
+		return nil, err
+	}
+	return append(lab.Spec.Warnings(), lab.Spec.MTUWarnings(lab.GetConfig())...), nil
+}
 
-	return nil, lab.Spec.Validate()
+// validateRestoreFrom checks the snapshot that lab restores from exists and is completed,
+// it is only checked on creation, nodes treat a snapshot removed afterwards as nothing to restore
+func (v *LabCustomValidator) validateRestoreFrom(ctx context.Context, lab *knlv1beta1.Lab) error {
+	if lab.Spec.RestoreFrom == nil {
+		return nil
+	}
+	if v.Client == nil {
+		return fmt.Errorf("no client to get snapshot %v", *lab.Spec.RestoreFrom)
+	}
+	snap := new(knlv1beta1.LabSnapshot)
+	err := v.Client.Get(ctx, types.NamespacedName{Namespace: getLabNamespace(ctx, lab), Name: *lab.Spec.RestoreFrom}, snap)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return field.NotFound(field.NewPath("spec", "restoreFrom"), *lab.Spec.RestoreFrom)
+		}
+		return fmt.Errorf("failed to get snapshot %v, %w", *lab.Spec.RestoreFrom, err)
+	}
+	if snap.Status.Phase != knlv1beta1.SnapshotPhaseCompleted {
+		return field.Invalid(field.NewPath("spec", "restoreFrom"), *lab.Spec.RestoreFrom,
+			fmt.Sprintf("snapshot is %v, not completed", snap.Status.Phase))
+	}
+	return nil
 }
 
 // ValidateUpdate implements webhook.CustomValidator so a webhook will be registered for the type Lab.
@@ -211,9 +408,10 @@ func (v *LabCustomValidator) ValidateUpdate(_ context.Context, oldObj, newObj ru
 		)
 	}
 
-	// TODO(user): fill in your validation logic upon object update.
-
-	return nil, lab.Spec.Validate()
+	if err := lab.Spec.Validate(); err != nil {
+		return nil, err
+	}
+	return lab.Spec.Warnings(), nil
 }
 
 // ValidateDelete implements webhook.CustomValidator so a webhook will be registered for the type Lab.
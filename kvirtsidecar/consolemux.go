@@ -0,0 +1,132 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"sync"
+	"time"
+
+	"kubenetlab.net/knl/api/v1beta1"
+)
+
+// consoleMux multiplexes the VM's raw console to multiple telnet clients,
+// this allows KNL operator to record the console while user could still login into it
+type consoleMux struct {
+	lock    *sync.RWMutex
+	console net.Conn
+	clients map[net.Conn]struct{}
+}
+
+func newConsoleMux() *consoleMux {
+	return &consoleMux{
+		lock:    new(sync.RWMutex),
+		clients: make(map[net.Conn]struct{}),
+	}
+}
+
+// runConsoleMux listens on SRVMConsoleTCPPort for telnet clients,
+// and connects to the VM's raw console on loopback, reconnect if the console is closed
+func runConsoleMux() error {
+	ln, err := net.Listen("tcp", fmt.Sprintf(":%d", v1beta1.SRVMConsoleTCPPort))
+	if err != nil {
+		return fmt.Errorf("failed to listen on console port, %w", err)
+	}
+	mux := newConsoleMux()
+	go mux.acceptClients(ln)
+	for {
+		conn, err := net.Dial("tcp", fmt.Sprintf("127.0.0.1:%d", v1beta1.SRVMConsoleRawTCPPort))
+		if err != nil {
+			time.Sleep(time.Second)
+			continue
+		}
+		log.Printf("connected to VM console")
+		mux.serveConsole(conn)
+		log.Printf("VM console closed")
+	}
+}
+
+func (mux *consoleMux) acceptClients(ln net.Listener) {
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			log.Printf("failed to accept console client, %v", err)
+			continue
+		}
+		go mux.serveClient(conn)
+	}
+}
+
+// serveConsole copies console output to all clients, return when console is closed
+func (mux *consoleMux) serveConsole(conn net.Conn) {
+	mux.lock.Lock()
+	mux.console = conn
+	mux.lock.Unlock()
+	defer func() {
+		mux.lock.Lock()
+		mux.console = nil
+		mux.lock.Unlock()
+		conn.Close()
+	}()
+	buf := make([]byte, 4096)
+	for {
+		n, err := conn.Read(buf)
+		if err != nil {
+			return
+		}
+		mux.broadcast(v1beta1.TelnetEscape(buf[:n]))
+	}
+}
+
+func (mux *consoleMux) broadcast(buf []byte) {
+	mux.lock.Lock()
+	defer mux.lock.Unlock()
+	for c := range mux.clients {
+		c.SetWriteDeadline(time.Now().Add(5 * time.Second))
+		if _, err := c.Write(buf); err != nil {
+			//slow or gone client is dropped
+			c.Close()
+			delete(mux.clients, c)
+		}
+	}
+}
+
+// serveClient forwards client input to the console
+func (mux *consoleMux) serveClient(conn net.Conn) {
+	//server side echo and suppress go ahead, same as qemu telnet server
+	_, err := conn.Write([]byte{
+		v1beta1.TelnetIAC, v1beta1.TelnetWILL, 1,
+		v1beta1.TelnetIAC, v1beta1.TelnetWILL, 3,
+	})
+	if err != nil {
+		conn.Close()
+		return
+	}
+	mux.lock.Lock()
+	mux.clients[conn] = struct{}{}
+	mux.lock.Unlock()
+	defer func() {
+		mux.lock.Lock()
+		delete(mux.clients, conn)
+		mux.lock.Unlock()
+		conn.Close()
+	}()
+	filter := new(v1beta1.TelnetFilter)
+	buf := make([]byte, 1024)
+	for {
+		n, err := conn.Read(buf)
+		if err != nil {
+			return
+		}
+		data := filter.Filter(buf[:n])
+		if len(data) == 0 {
+			continue
+		}
+		mux.lock.RLock()
+		console := mux.console
+		mux.lock.RUnlock()
+		if console != nil {
+			console.Write(data)
+		}
+	}
+}
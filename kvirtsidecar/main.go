@@ -22,7 +22,33 @@ import (
 
 var ignorePortAliasPrefixList = []string{"vxlandev", "macvtapbr"}
 
-func getTelnetConsole() []libvirtxml.DomainConsole {
+// getTelnetConsole return the console of the VM,
+// if logged is true, the console is a raw TCP socket on loopback, which is multiplexed by the console mux to SRVMConsoleTCPPort
+func getTelnetConsole(logged bool) []libvirtxml.DomainConsole {
+	if logged {
+		return []libvirtxml.DomainConsole{
+			{
+				Alias: &libvirtxml.DomainAlias{
+					Name: "console0",
+				},
+				Protocol: &libvirtxml.DomainChardevProtocol{
+					Type: "raw",
+				},
+				Source: &libvirtxml.DomainChardevSource{
+					TCP: &libvirtxml.DomainChardevSourceTCP{
+						Mode:    "bind",
+						Host:    "127.0.0.1",
+						Service: strconv.Itoa(v1beta1.SRVMConsoleRawTCPPort),
+						TLS:     "no",
+					},
+				},
+				Target: &libvirtxml.DomainConsoleTarget{
+					Type: "virtio",
+					Port: new(uint),
+				},
+			},
+		}
+	}
 	return []libvirtxml.DomainConsole{
 		{
 			Alias: &libvirtxml.DomainAlias{
@@ -47,7 +73,19 @@ func getTelnetConsole() []libvirtxml.DomainConsole {
 	}
 }
 
-func onDefineDomain(vmiJSON, domainXML []byte) (string, error) {
+// setSysInfoEntry sets the value of named entry, the entry is added if not exists
+func setSysInfoEntry(entries []libvirtxml.DomainSysInfoEntry, name, value string) []libvirtxml.DomainSysInfoEntry {
+	for i := range entries {
+		if entries[i].Name == name {
+			entries[i].Value = value
+			return entries
+		}
+	}
+	return append(entries, libvirtxml.DomainSysInfoEntry{Name: name, Value: value})
+}
+
+// onDefineDomain return the modified domain XML, and true if the console mux is needed
+func onDefineDomain(vmiJSON, domainXML []byte) (string, bool, error) {
 	// f, err := os.CreateTemp("", "origdomainxml*")
 	// if err == nil {
 	// 	f.Write(domainXML)
@@ -56,12 +94,12 @@ func onDefineDomain(vmiJSON, domainXML []byte) (string, error) {
 
 	vmiSpec := vmSchema.VirtualMachineInstance{}
 	if err := json.Unmarshal(vmiJSON, &vmiSpec); err != nil {
-		return "", err
+		return "", false, err
 	}
 	newSpec := &libvirtxml.Domain{}
 	err := newSpec.Unmarshal(string(domainXML))
 	if err != nil {
-		return "", fmt.Errorf("failed to unmarsahl using libvirtxml, %w", err)
+		return "", false, fmt.Errorf("failed to unmarsahl using libvirtxml, %w", err)
 	}
 
 	annotations := vmiSpec.GetAnnotations()
@@ -69,12 +107,13 @@ func onDefineDomain(vmiJSON, domainXML []byte) (string, error) {
 	var found bool
 	var vmts string
 	if vmts, found = labels[v1beta1.ChassisTypeAnnotation]; !found {
-		return "", fmt.Errorf("failed to find %v label", v1beta1.ChassisTypeAnnotation)
+		return "", false, fmt.Errorf("failed to find %v label", v1beta1.ChassisTypeAnnotation)
 	}
 	vmt := v1beta1.NodeType(strings.ToLower(strings.TrimSpace(vmts)))
+	consoleLogged := annotations[v1beta1.ConsoleLogAnnotation] == "true"
 
 	if err := json.Unmarshal(vmiJSON, &vmiSpec); err != nil {
-		return "", err
+		return "", false, err
 	}
 	var sftpSvrAddr, sftpUser, sftpPass string
 	var ftpPathMapJsonStr string
@@ -83,28 +122,28 @@ func onDefineDomain(vmiJSON, domainXML []byte) (string, error) {
 
 		if _, found = annotations[v1beta1.VSROSSysinfoAnno]; !found {
 			//if not vsros, return unchanged
-			return string(domainXML), nil
+			return string(domainXML), false, nil
 		}
 		if sftpSvrAddr, found = annotations[v1beta1.SftpSVRAnnontation]; !found {
-			return "", fmt.Errorf("can't find %v in VMI's annontation", v1beta1.SftpSVRAnnontation)
+			return "", false, fmt.Errorf("can't find %v in VMI's annontation", v1beta1.SftpSVRAnnontation)
 		}
 		if sftpUser, found = annotations[v1beta1.SftpUserAnnontation]; !found {
-			return "", fmt.Errorf("can't find %v in VMI's annontation", v1beta1.SftpUserAnnontation)
+			return "", false, fmt.Errorf("can't find %v in VMI's annontation", v1beta1.SftpUserAnnontation)
 		}
 		if sftpPass, found = annotations[v1beta1.SftpPassAnnontation]; !found {
-			return "", fmt.Errorf("can't find %v in VMI's annontation", v1beta1.SftpPassAnnontation)
+			return "", false, fmt.Errorf("can't find %v in VMI's annontation", v1beta1.SftpPassAnnontation)
 		}
 		//sftpSvrAddr must be addr or hostname + port
 		if sftpSvrAddr, found = annotations[v1beta1.SftpSVRAnnontation]; !found {
-			return "", fmt.Errorf("can't find %v in VMI's annontation", v1beta1.SftpSVRAnnontation)
+			return "", false, fmt.Errorf("can't find %v in VMI's annontation", v1beta1.SftpSVRAnnontation)
 		}
 		if ftpPathMapJsonStr, found = annotations[v1beta1.FTPPathMapAnnotation]; !found {
-			return "", fmt.Errorf("can't find %v in VMI's annontation", v1beta1.FTPPathMapAnnotation)
+			return "", false, fmt.Errorf("can't find %v in VMI's annontation", v1beta1.FTPPathMapAnnotation)
 		}
 		ftpPathMap := make(map[string]string)
 		err := json.Unmarshal([]byte(ftpPathMapJsonStr), &ftpPathMap)
 		if err != nil {
-			return "", fmt.Errorf("failed to unmarshal annontation %v value, %w", v1beta1.FTPPathMapAnnotation, err)
+			return "", false, fmt.Errorf("failed to unmarshal annontation %v value, %w", v1beta1.FTPPathMapAnnotation, err)
 		}
 
 		//generate ftp server config
@@ -192,7 +231,7 @@ func onDefineDomain(vmiJSON, domainXML []byte) (string, error) {
 		//clear channel
 		// newSpec.Devices.Channels = []libvirtxml.DomainChannel{}
 		//add telnet console
-		newSpec.Devices.Consoles = getTelnetConsole()
+		newSpec.Devices.Consoles = getTelnetConsole(consoleLogged)
 
 		//clean up disk setting
 		diskFile := newSpec.Devices.Disks[0].Source.File.File
@@ -220,15 +259,38 @@ func onDefineDomain(vmiJSON, domainXML []byte) (string, error) {
 	// 	newSpec.Devices.Videos = []libvirtxml.DomainVideo{}
 	case v1beta1.VM:
 		//add telnet console
-		newSpec.Devices.Consoles = getTelnetConsole()
+		newSpec.Devices.Consoles = getTelnetConsole(consoleLogged)
+	case v1beta1.VJunosNode:
+		//vJunos identifies itself via SMBIOS product and family
+		product, found := annotations[v1beta1.SMBIOSProductAnnotation]
+		if !found {
+			return "", false, fmt.Errorf("can't find %v in VMI's annontation", v1beta1.SMBIOSProductAnnotation)
+		}
+		newSpec.SysInfo[0].SMBIOS.System.Entry = setSysInfoEntry(newSpec.SysInfo[0].SMBIOS.System.Entry, "product", product)
+		newSpec.SysInfo[0].SMBIOS.System.Entry = setSysInfoEntry(newSpec.SysInfo[0].SMBIOS.System.Entry, "family", v1beta1.VJunosSMBIOSFamily)
+		//vJunos requires vmx, which is available via host-passthrough
+		newSpec.CPU = &libvirtxml.DomainCPU{
+			Mode: "host-passthrough",
+		}
+		//Junos console is on the 1st serial port, replace it with the telnet console
+		console := getTelnetConsole(consoleLogged)[0]
+		newSpec.Devices.Serials = []libvirtxml.DomainSerial{
+			{
+				Alias:    &libvirtxml.DomainAlias{Name: "serial0"},
+				Protocol: console.Protocol,
+				Source:   console.Source,
+				Target:   &libvirtxml.DomainSerialTarget{Port: new(uint)},
+			},
+		}
+		newSpec.Devices.Consoles = nil
 
 	}
 	newrr, err := newSpec.Marshal()
 	if err != nil {
-		return "", fmt.Errorf("failed to marsahl using libvirtxml, %w", err)
+		return "", false, fmt.Errorf("failed to marsahl using libvirtxml, %w", err)
 	}
 
-	return newrr, nil
+	return newrr, consoleLogged, nil
 
 }
 
@@ -269,14 +331,23 @@ const (
 
 func main() {
 	var vmiJSON, domainXML string
+	var consoleMux bool
 	pflag.StringVar(&vmiJSON, "vmi", "", "VMI to change in JSON format")
 	pflag.StringVar(&domainXML, "domain", "", "Domain spec in XML format")
+	pflag.BoolVar(&consoleMux, "consolemux", false, "run as console mux daemon")
 	pflag.Parse()
 	logf, err := os.CreateTemp("", "knlhook*")
 	if err == nil {
 		defer logf.Close()
 		log.SetOutput(logf)
 	}
+	if consoleMux {
+		err = runConsoleMux()
+		if err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
 
 	// logger := log.New(os.Stderr, "knlhook", log.Ldate)
 	if vmiJSON == "" || domainXML == "" {
@@ -284,7 +355,7 @@ func main() {
 		os.Exit(1)
 	}
 	log.Print("orig xml", domainXML)
-	rdomainXML, err := onDefineDomain([]byte(vmiJSON), []byte(domainXML))
+	rdomainXML, needConsoleMux, err := onDefineDomain([]byte(vmiJSON), []byte(domainXML))
 	if err != nil {
 		log.Print(err)
 		os.Exit(1)
@@ -299,6 +370,16 @@ func main() {
 	} else {
 		log.Printf("damemon launched")
 	}
+	if needConsoleMux {
+		cmd = exec.Command("/bin/sh", "-c", fmt.Sprintf("%v --consolemux &", os.Args[0]))
+		log.Printf("Running command %v to launch console mux", cmd.String())
+		err = cmd.Run()
+		if err != nil {
+			log.Printf("command failed with %v", err)
+		} else {
+			log.Printf("console mux launched")
+		}
+	}
 
 	//do NOT remove line below this is needed to return result xml
 	fmt.Print(rdomainXML)
@@ -0,0 +1,194 @@
+// Package topogen generates nodes and links of common topology shapes for a lab
+package topogen
+
+import (
+	"fmt"
+
+	"kubenetlab.net/knl/api/v1beta1"
+)
+
+// endpoint is a node of a role
+type endpoint struct {
+	role string
+	idx  int
+}
+
+// GenLink is a generated link between two nodes
+type GenLink struct {
+	Name string
+	A, B string
+	//roles of A and B, used to assign port id
+	roleA, roleB *v1beta1.GeneratorRole
+}
+
+// Topology is the generated nodes and links
+type Topology struct {
+	//key is node name
+	Nodes map[string]*v1beta1.OneOfSystem
+	//in deterministic order
+	Links []GenLink
+}
+
+func fullMesh(role string, count int) [][2]endpoint {
+	r := [][2]endpoint{}
+	for i := 0; i < count; i++ {
+		for j := i + 1; j < count; j++ {
+			r = append(r, [2]endpoint{{role, i}, {role, j}})
+		}
+	}
+	return r
+}
+
+// allPairs connects every node of roleA to every node of roleB
+func allPairs(roleA string, countA int, roleB string, countB int) [][2]endpoint {
+	r := [][2]endpoint{}
+	for i := 0; i < countA; i++ {
+		for j := 0; j < countB; j++ {
+			r = append(r, [2]endpoint{{roleA, i}, {roleB, j}})
+		}
+	}
+	return r
+}
+
+// Generate returns nodes and links of gen
+func Generate(gen *v1beta1.TopologyGenerator) (*Topology, error) {
+	if err := gen.Validate(); err != nil {
+		return nil, err
+	}
+	count := func(role string) int { return int(gen.Roles[role].Count) }
+	var pairs [][2]endpoint
+	switch gen.Shape {
+	case v1beta1.ShapeLeafSpine:
+		pairs = allPairs("leaf", count("leaf"), "spine", count("spine"))
+	case v1beta1.ShapeRing:
+		n := count("node")
+		for i := 0; i < n; i++ {
+			pairs = append(pairs, [2]endpoint{{"node", i}, {"node", (i + 1) % n}})
+		}
+	case v1beta1.ShapeFullMesh:
+		pairs = fullMesh("node", count("node"))
+	case v1beta1.ShapeHubSpoke:
+		pairs = allPairs("spoke", count("spoke"), "hub", count("hub"))
+	case v1beta1.ShapePEP:
+		np := count("p")
+		pairs = fullMesh("p", np)
+		for i := 0; i < count("pe"); i++ {
+			pairs = append(pairs, [2]endpoint{{"pe", i}, {"p", i % np}})
+			if np > 1 {
+				pairs = append(pairs, [2]endpoint{{"pe", i}, {"p", (i + 1) % np}})
+			}
+		}
+	}
+	topo := &Topology{
+		Nodes: make(map[string]*v1beta1.OneOfSystem),
+	}
+	for _, roleName := range v1beta1.ShapeRoles[gen.Shape] {
+		role := gen.Roles[roleName]
+		for i := 0; i < int(role.Count); i++ {
+			if role.Template != nil {
+				topo.Nodes[role.NodeName(i)] = role.Template.DeepCopy()
+			} else {
+				topo.Nodes[role.NodeName(i)] = new(v1beta1.OneOfSystem)
+			}
+		}
+	}
+	for _, p := range pairs {
+		roleA, roleB := gen.Roles[p[0].role], gen.Roles[p[1].role]
+		a, b := roleA.NodeName(p[0].idx), roleB.NodeName(p[1].idx)
+		topo.Links = append(topo.Links, GenLink{
+			Name:  fmt.Sprintf("%v-%v", a, b),
+			A:     a,
+			B:     b,
+			roleA: roleA,
+			roleB: roleB,
+		})
+	}
+	return topo, nil
+}
+
+// Merge merges topo into spec: existing nodes are kept and their unspecified fields are filled from the generated node,
+// existing links are kept; port ids are assigned in link order, skipping ports already used by the node
+func Merge(spec *v1beta1.LabSpec, topo *Topology) error {
+	if spec.NodeList == nil {
+		spec.NodeList = make(map[string]*v1beta1.OneOfSystem)
+	}
+	if spec.LinkList == nil {
+		spec.LinkList = make(map[string]*v1beta1.Link)
+	}
+	for _, nodeName := range v1beta1.GetSortedKeySlice(topo.Nodes) {
+		node, ok := spec.NodeList[nodeName]
+		if !ok || node == nil {
+			spec.NodeList[nodeName] = topo.Nodes[nodeName]
+			continue
+		}
+		sys, sysName := node.GetSystem()
+		gsys, gsysName := topo.Nodes[nodeName].GetSystem()
+		if sys == nil {
+			spec.NodeList[nodeName] = topo.Nodes[nodeName]
+			continue
+		}
+		if gsys == nil || sysName != gsysName {
+			continue
+		}
+		if err := v1beta1.FillNilPointers(sys, gsys); err != nil {
+			return fmt.Errorf("failed to merge generated node %v, %w", nodeName, err)
+		}
+	}
+	//used port ids of each node
+	used := make(map[string]map[string]bool)
+	for _, link := range spec.LinkList {
+		if link == nil {
+			continue
+		}
+		for _, c := range link.Connectors {
+			if c.NodeName == nil || c.PortId == nil {
+				continue
+			}
+			if used[*c.NodeName] == nil {
+				used[*c.NodeName] = make(map[string]bool)
+			}
+			used[*c.NodeName][*c.PortId] = true
+		}
+	}
+	next := make(map[string]int)
+	nextPort := func(nodeName string, role *v1beta1.GeneratorRole) *string {
+		if role.PortPattern == nil {
+			return nil
+		}
+		for {
+			next[nodeName]++
+			portId := role.PortId(next[nodeName])
+			if !used[nodeName][*portId] {
+				if used[nodeName] == nil {
+					used[nodeName] = make(map[string]bool)
+				}
+				used[nodeName][*portId] = true
+				return portId
+			}
+		}
+	}
+	for _, gl := range topo.Links {
+		if _, ok := spec.LinkList[gl.Name]; ok {
+			continue
+		}
+		spec.LinkList[gl.Name] = &v1beta1.Link{
+			Connectors: []v1beta1.Connector{
+				{NodeName: v1beta1.ReturnPointerVal(gl.A), PortId: nextPort(gl.A, gl.roleA)},
+				{NodeName: v1beta1.ReturnPointerVal(gl.B), PortId: nextPort(gl.B, gl.roleB)},
+			},
+		}
+	}
+	return nil
+}
+
+// Apply generates the topology of spec's generator and merges it into spec, it does nothing if generator is not specified
+func Apply(spec *v1beta1.LabSpec) error {
+	if spec.Generator == nil {
+		return nil
+	}
+	topo, err := Generate(spec.Generator)
+	if err != nil {
+		return fmt.Errorf("generator is invalid, %w", err)
+	}
+	return Merge(spec, topo)
+}
@@ -0,0 +1,93 @@
+package topogen
+
+import (
+	"testing"
+
+	"kubenetlab.net/knl/api/v1beta1"
+)
+
+func TestGenerate(t *testing.T) {
+	cases := []struct {
+		shape v1beta1.TopologyShape
+		roles map[string]int32
+		nodes int
+		links int
+	}{
+		{v1beta1.ShapeLeafSpine, map[string]int32{"spine": 2, "leaf": 4}, 6, 8},
+		{v1beta1.ShapeRing, map[string]int32{"node": 5}, 5, 5},
+		{v1beta1.ShapeFullMesh, map[string]int32{"node": 4}, 4, 6},
+		{v1beta1.ShapeHubSpoke, map[string]int32{"hub": 1, "spoke": 3}, 4, 3},
+		{v1beta1.ShapePEP, map[string]int32{"p": 3, "pe": 4}, 7, 11},
+		{v1beta1.ShapePEP, map[string]int32{"p": 1, "pe": 2}, 3, 2},
+	}
+	for _, c := range cases {
+		gen := &v1beta1.TopologyGenerator{Shape: c.shape, Roles: map[string]*v1beta1.GeneratorRole{}}
+		start := int32(1)
+		for role, count := range c.roles {
+			gen.Roles[role] = &v1beta1.GeneratorRole{Count: count, NamePattern: "pod-%d", Start: v1beta1.ReturnPointerVal(start)}
+			start += 100
+		}
+		topo, err := Generate(gen)
+		if err != nil {
+			t.Fatalf("shape %v failed, %v", c.shape, err)
+		}
+		if len(topo.Nodes) != c.nodes || len(topo.Links) != c.links {
+			t.Fatalf("shape %v expect %d nodes and %d links, got %d and %d", c.shape, c.nodes, c.links, len(topo.Nodes), len(topo.Links))
+		}
+	}
+	if _, err := Generate(&v1beta1.TopologyGenerator{Shape: v1beta1.ShapeRing,
+		Roles: map[string]*v1beta1.GeneratorRole{"node": {Count: 2, NamePattern: "pod-%d"}}}); err == nil {
+		t.Fatalf("expect error for ring of 2 nodes")
+	}
+	if _, err := Generate(&v1beta1.TopologyGenerator{Shape: v1beta1.ShapeLeafSpine,
+		Roles: map[string]*v1beta1.GeneratorRole{"spine": {Count: 2, NamePattern: "srl-%d"}, "leaf": {Count: 2, NamePattern: "srl-%d"}}}); err == nil {
+		t.Fatalf("expect error for duplicate node names")
+	}
+}
+
+func TestApply(t *testing.T) {
+	spec := &v1beta1.LabSpec{
+		NodeList: map[string]*v1beta1.OneOfSystem{
+			"frr-1": {FRR: &v1beta1.FRR{Image: v1beta1.ReturnPointerVal("my-frr")}},
+		},
+		LinkList: map[string]*v1beta1.Link{
+			"uplink": {Connectors: []v1beta1.Connector{
+				{NodeName: v1beta1.ReturnPointerVal("frr-1"), PortId: v1beta1.ReturnPointerVal("eth1")},
+				{NodeName: v1beta1.ReturnPointerVal("pod-1")},
+			}},
+		},
+		Generator: &v1beta1.TopologyGenerator{
+			Shape: v1beta1.ShapeLeafSpine,
+			Roles: map[string]*v1beta1.GeneratorRole{
+				"spine": {Count: 2, NamePattern: "frr-%d", PortPattern: v1beta1.ReturnPointerVal("eth%d"),
+					Template: &v1beta1.OneOfSystem{FRR: &v1beta1.FRR{Image: v1beta1.ReturnPointerVal("frr"), Config: v1beta1.ReturnPointerVal("spine-cfg")}}},
+				"leaf": {Count: 2, NamePattern: "frr-%d", Start: v1beta1.ReturnPointerVal(int32(11)), PortPattern: v1beta1.ReturnPointerVal("eth%d")},
+			},
+		},
+	}
+	if err := Apply(spec); err != nil {
+		t.Fatal(err)
+	}
+	if len(spec.NodeList) != 4 || len(spec.LinkList) != 5 {
+		t.Fatalf("unexpected nodes %v and links %v", v1beta1.GetSortedKeySlice(spec.NodeList), v1beta1.GetSortedKeySlice(spec.LinkList))
+	}
+	//specified node takes precedence, unspecified fields come from template
+	if frr := spec.NodeList["frr-1"].FRR; *frr.Image != "my-frr" || *frr.Config != "spine-cfg" {
+		t.Fatalf("unexpected merged node %+v", frr)
+	}
+	//eth1 of frr-1 is used by uplink
+	link := spec.LinkList["frr-11-frr-1"]
+	if *link.Connectors[0].PortId != "eth1" || *link.Connectors[1].PortId != "eth2" {
+		t.Fatalf("unexpected port ids of %+v", link)
+	}
+	if link = spec.LinkList["frr-12-frr-1"]; *link.Connectors[1].PortId != "eth3" {
+		t.Fatalf("unexpected port ids of %+v", link)
+	}
+	//idempotent
+	if err := Apply(spec); err != nil {
+		t.Fatal(err)
+	}
+	if len(spec.LinkList) != 5 || *spec.LinkList["frr-12-frr-1"].Connectors[1].PortId != "eth3" {
+		t.Fatalf("apply is not idempotent")
+	}
+}